@@ -0,0 +1,294 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/**
+* A typed client of the API served by `et serve`, one method per operation
+* of its OpenAPI document. Token is sent as a bearer token when set, which
+* the server requires once API tokens have been created.
+ */
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+/**
+* An error answered by the API, e.g. 404 for an unknown expense ID.
+ */
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+const (
+	DUPLICATES_HEADER = "X-Possible-Duplicates"
+)
+
+/**
+* Creates a client of the API at the given base URL, e.g. "http://127.0.0.1:8080".
+ */
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+func (e *Error) Error() string {
+	return strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode) + ": " + e.Message
+}
+
+/**
+* Reports whether err is an API error with the given status code.
+ */
+func IsStatus(err error, statusCode int) bool {
+	var apiErr *Error
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+func (c *Client) ListExpenses(ctx context.Context, filter ExpenseFilter) (ExpensePage, error) {
+	query := url.Values{}
+	setInt(query, "month", filter.Month)
+	setInt(query, "year", filter.Year)
+	setString(query, "category", filter.Category)
+	setString(query, "from", filter.From)
+	setString(query, "to", filter.To)
+	setString(query, "q", filter.Query)
+	if filter.WithDeleted {
+		query.Set("with_deleted", "true")
+	}
+	setInt(query, "limit", filter.Limit)
+	setInt(query, "offset", filter.Offset)
+
+	var page ExpensePage
+	_, err := c.do(ctx, http.MethodGet, "/api/expenses", query, nil, &page)
+
+	return page, err
+}
+
+func (c *Client) CreateExpense(ctx context.Context, input ExpenseInput) (CreatedExpense, error) {
+	var created CreatedExpense
+	header, err := c.do(ctx, http.MethodPost, "/api/expenses", nil, input, &created.Expense)
+	if err != nil {
+		return CreatedExpense{}, err
+	}
+
+	if ids := header.Get(DUPLICATES_HEADER); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(id))
+			if err != nil {
+				return CreatedExpense{}, errors.New("invalid " + DUPLICATES_HEADER + " header: " + ids)
+			}
+			created.PossibleDuplicates = append(created.PossibleDuplicates, n)
+		}
+	}
+
+	return created, nil
+}
+
+func (c *Client) GetExpense(ctx context.Context, id int) (Expense, error) {
+	var exp Expense
+	_, err := c.do(ctx, http.MethodGet, "/api/expenses/"+strconv.Itoa(id), nil, nil, &exp)
+
+	return exp, err
+}
+
+func (c *Client) UpdateExpense(ctx context.Context, id int, input ExpenseInput) (Expense, error) {
+	var exp Expense
+	_, err := c.do(ctx, http.MethodPatch, "/api/expenses/"+strconv.Itoa(id), nil, input, &exp)
+
+	return exp, err
+}
+
+func (c *Client) DeleteExpense(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/expenses/"+strconv.Itoa(id), nil, nil, nil)
+
+	return err
+}
+
+func (c *Client) ListBudgets(ctx context.Context) ([]Budget, error) {
+	budgets := []Budget{}
+	_, err := c.do(ctx, http.MethodGet, "/api/budgets", nil, nil, &budgets)
+
+	return budgets, err
+}
+
+func (c *Client) SetBudget(ctx context.Context, input BudgetInput) (Budget, error) {
+	var b Budget
+	_, err := c.do(ctx, http.MethodPut, "/api/budgets", nil, input, &b)
+
+	return b, err
+}
+
+func (c *Client) RemoveBudget(ctx context.Context, month int, category string) error {
+	query := url.Values{}
+	setInt(query, "month", month)
+	setString(query, "category", category)
+
+	_, err := c.do(ctx, http.MethodDelete, "/api/budgets", query, nil, nil)
+
+	return err
+}
+
+func (c *Client) ListCategories(ctx context.Context) ([]Category, error) {
+	categories := []Category{}
+	_, err := c.do(ctx, http.MethodGet, "/api/categories", nil, nil, &categories)
+
+	return categories, err
+}
+
+/**
+* Registers a category and its missing parents.
+*
+* @return The category in its canonical spelling.
+ */
+func (c *Client) AddCategory(ctx context.Context, name string) (Category, error) {
+	var cat Category
+	_, err := c.do(ctx, http.MethodPost, "/api/categories", nil, Category{Name: name}, &cat)
+
+	return cat, err
+}
+
+func (c *Client) RemoveCategory(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/api/categories/"+url.PathEscape(name), nil, nil, nil)
+
+	return err
+}
+
+func (c *Client) Summary(ctx context.Context, filter ReportFilter) (Summary, error) {
+	var summary Summary
+	_, err := c.do(ctx, http.MethodGet, "/api/summary", reportQuery(filter), nil, &summary)
+
+	return summary, err
+}
+
+/**
+* Returns spending and income per month. Only the year and category of the
+* filter apply.
+ */
+func (c *Client) MonthlyReport(ctx context.Context, filter ReportFilter) ([]MonthTotal, error) {
+	filter.Month = 0
+	totals := []MonthTotal{}
+	_, err := c.do(ctx, http.MethodGet, "/api/reports/monthly", reportQuery(filter), nil, &totals)
+
+	return totals, err
+}
+
+func (c *Client) BudgetReport(ctx context.Context, filter ReportFilter) ([]BudgetLine, error) {
+	lines := []BudgetLine{}
+	_, err := c.do(ctx, http.MethodGet, "/api/reports/budget", reportQuery(filter), nil, &lines)
+
+	return lines, err
+}
+
+/**
+* Returns the end-of-month forecast of the current month, optionally for
+* one category.
+ */
+func (c *Client) Forecast(ctx context.Context, category string) ([]ForecastLine, error) {
+	query := url.Values{}
+	setString(query, "category", category)
+
+	lines := []ForecastLine{}
+	_, err := c.do(ctx, http.MethodGet, "/api/reports/forecast", query, nil, &lines)
+
+	return lines, err
+}
+
+/**
+* Sends a request and decodes the JSON answer into result, unless it is nil.
+*
+* @return The response headers, or an *Error if the API answered with an error status.
+ */
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result any) (http.Header, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, payload)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var answer struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil || answer.Error == "" {
+			answer.Error = http.StatusText(resp.StatusCode)
+		}
+
+		return resp.Header, &Error{StatusCode: resp.StatusCode, Message: answer.Error}
+	}
+
+	if result == nil {
+		return resp.Header, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return resp.Header, errors.New("invalid answer from " + method + " " + path + ": " + err.Error())
+	}
+
+	return resp.Header, nil
+}
+
+func reportQuery(filter ReportFilter) url.Values {
+	query := url.Values{}
+	setInt(query, "month", filter.Month)
+	setInt(query, "year", filter.Year)
+	setString(query, "category", filter.Category)
+
+	return query
+}
+
+func setInt(query url.Values, name string, value int) {
+	if value != 0 {
+		query.Set(name, strconv.Itoa(value))
+	}
+}
+
+func setString(query url.Values, name, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
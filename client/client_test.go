@@ -0,0 +1,425 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+	"github.com/dmitriy-zverev/expense-tracker/internal/server"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	ts := httptest.NewServer(server.New())
+	t.Cleanup(ts.Close)
+
+	return New(ts.URL + "/")
+}
+
+func TestExpenseLifecycle(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	created, err := c.CreateExpense(ctx, ExpenseInput{
+		Amount:      Float(12.5),
+		Description: String("Lunch"),
+		Category:    String("food"),
+		Date:        String("2025-09-20"),
+		Tags:        []string{"work"},
+	})
+	if err != nil {
+		t.Fatalf("CreateExpense() error = %v", err)
+	}
+	if created.ID != 0 || created.Category != "food" || created.Month != 9 || len(created.PossibleDuplicates) != 0 {
+		t.Errorf("CreateExpense() = %+v", created)
+	}
+
+	again, err := c.CreateExpense(ctx, ExpenseInput{Amount: Float(12.5), Description: String("LUNCH"), Date: String("2025-09-21")})
+	if err != nil {
+		t.Fatalf("CreateExpense() error = %v", err)
+	}
+	if !slices.Equal(again.PossibleDuplicates, []int{0}) {
+		t.Errorf("PossibleDuplicates = %v, want [0]", again.PossibleDuplicates)
+	}
+
+	updated, err := c.UpdateExpense(ctx, 0, ExpenseInput{Notes: String("with the team")})
+	if err != nil {
+		t.Fatalf("UpdateExpense() error = %v", err)
+	}
+	if updated.Notes != "with the team" || updated.Amount != 12.5 {
+		t.Errorf("UpdateExpense() = %+v", updated)
+	}
+
+	if err := c.DeleteExpense(ctx, 1); err != nil {
+		t.Fatalf("DeleteExpense() error = %v", err)
+	}
+
+	page, err := c.ListExpenses(ctx, ExpenseFilter{Category: "Food"})
+	if err != nil {
+		t.Fatalf("ListExpenses() error = %v", err)
+	}
+	if page.Total != 1 || page.Items[0].Description != "Lunch" {
+		t.Errorf("ListExpenses() = %+v", page)
+	}
+
+	page, err = c.ListExpenses(ctx, ExpenseFilter{WithDeleted: true, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListExpenses() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 1 || !page.Items[0].IsDeleted {
+		t.Errorf("ListExpenses() = %+v", page)
+	}
+
+	exp, err := c.GetExpense(ctx, 1)
+	if err != nil || !exp.IsDeleted {
+		t.Errorf("GetExpense() = %+v, %v", exp, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := c.GetExpense(ctx, 7)
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Errorf("GetExpense() error = %v, want 404", err)
+	}
+	if !strings.Contains(err.Error(), "cannot find expense") {
+		t.Errorf("error message = %q", err.Error())
+	}
+
+	if _, err := c.CreateExpense(ctx, ExpenseInput{Amount: Float(-1)}); !IsStatus(err, http.StatusBadRequest) {
+		t.Errorf("CreateExpense() error = %v, want 400", err)
+	}
+
+	if err := c.RemoveCategory(ctx, "Nothing"); !IsStatus(err, http.StatusNotFound) {
+		t.Errorf("RemoveCategory() error = %v, want 404", err)
+	}
+}
+
+func TestBudgetsCategoriesAndReports(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	cat, err := c.AddCategory(ctx, "Food:Fast food")
+	if err != nil || cat.Name != "Food:Fast food" {
+		t.Fatalf("AddCategory() = %+v, %v", cat, err)
+	}
+
+	categories, err := c.ListCategories(ctx)
+	if err != nil || len(categories) != 2 {
+		t.Errorf("ListCategories() = %v, %v", categories, err)
+	}
+
+	if err := c.RemoveCategory(ctx, "Food:Fast food"); err != nil {
+		t.Errorf("RemoveCategory() error = %v", err)
+	}
+
+	b, err := c.SetBudget(ctx, BudgetInput{Month: 9, Category: "Food", Limit: 200})
+	if err != nil || b.Limit != 200 {
+		t.Fatalf("SetBudget() = %+v, %v", b, err)
+	}
+
+	budgets, err := c.ListBudgets(ctx)
+	if err != nil || len(budgets) != 1 {
+		t.Errorf("ListBudgets() = %v, %v", budgets, err)
+	}
+
+	if _, err := c.CreateExpense(ctx, ExpenseInput{Amount: Float(50), Description: String("Groceries"), Category: String("Food"), Date: String("2025-09-02")}); err != nil {
+		t.Fatalf("CreateExpense() error = %v", err)
+	}
+
+	summary, err := c.Summary(ctx, ReportFilter{Year: 2025})
+	if err != nil || summary.TotalExpenses != 50 || len(summary.Categories) != 1 {
+		t.Errorf("Summary() = %+v, %v", summary, err)
+	}
+
+	months, err := c.MonthlyReport(ctx, ReportFilter{Year: 2025, Month: 3})
+	if err != nil || len(months) != 1 || months[0].Month != 9 {
+		t.Errorf("MonthlyReport() = %+v, %v", months, err)
+	}
+
+	if _, err := c.BudgetReport(ctx, ReportFilter{Month: 9, Year: b.Year}); err != nil {
+		t.Errorf("BudgetReport() error = %v", err)
+	}
+
+	if _, err := c.Forecast(ctx, "Food"); err != nil {
+		t.Errorf("Forecast() error = %v", err)
+	}
+
+	if err := c.RemoveBudget(ctx, 9, "Food"); err != nil {
+		t.Errorf("RemoveBudget() error = %v", err)
+	}
+}
+
+/**
+* Checks that the JSON fields of the client types are exactly the properties
+* of the schemas they mirror, so a field added to the API is added here too.
+ */
+func TestToken(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	_, secret, err := auth.CreateToken("alice", auth.SCOPE_WRITE)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := c.ListExpenses(ctx, ExpenseFilter{}); !IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("ListExpenses() without a token error = %v, want 401", err)
+	}
+
+	c.Token = secret
+	created, err := c.CreateExpense(ctx, ExpenseInput{Amount: Float(3), Description: String("Tea")})
+	if err != nil {
+		t.Fatalf("CreateExpense() error = %v", err)
+	}
+
+	if created.Expense.CreatedBy != "alice" {
+		t.Errorf("CreatedBy = %q, want alice", created.Expense.CreatedBy)
+	}
+}
+
+func TestTypesMatchOpenAPI(t *testing.T) {
+	c := newTestClient(t)
+
+	resp, err := c.HTTPClient.Get(c.BaseURL + server.OPENAPI_PATH)
+	if err != nil {
+		t.Fatalf("Failed to fetch the OpenAPI document: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var document struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		t.Fatalf("Failed to decode the OpenAPI document: %v", err)
+	}
+
+	types := map[string]any{
+		"Expense":       Expense{},
+		"ExpenseInput":  ExpenseInput{},
+		"ExpensePage":   ExpensePage{},
+		"Budget":        Budget{},
+		"BudgetInput":   BudgetInput{},
+		"Category":      Category{},
+		"CategoryInput": Category{},
+		"CategoryTotal": CategoryTotal{},
+		"MonthTotal":    MonthTotal{},
+		"BudgetLine":    BudgetLine{},
+		"ForecastLine":  ForecastLine{},
+		"Summary":       Summary{},
+	}
+
+	for name, value := range types {
+		schema, ok := document.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s is not in the OpenAPI document", name)
+			continue
+		}
+
+		documented := []string{}
+		for property := range schema.Properties {
+			documented = append(documented, property)
+		}
+
+		fields := []string{}
+		typ := reflect.TypeOf(value)
+		for i := range typ.NumField() {
+			tag, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			fields = append(fields, tag)
+		}
+
+		sort.Strings(documented)
+		sort.Strings(fields)
+		if !slices.Equal(documented, fields) {
+			t.Errorf("%s fields = %v, schema properties = %v", name, fields, documented)
+		}
+	}
+}
+
+func TestOperationsMatchOpenAPI(t *testing.T) {
+	c := newTestClient(t)
+
+	resp, err := c.HTTPClient.Get(c.BaseURL + server.OPENAPI_PATH)
+	if err != nil {
+		t.Fatalf("Failed to fetch the OpenAPI document: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var document struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		t.Fatalf("Failed to decode the OpenAPI document: %v", err)
+	}
+
+	// The client method of each operation, called with any arguments
+	operations := map[string]struct {
+		method string
+		call   func(ctx context.Context, c *Client) error
+	}{
+		"listExpenses": {"ListExpenses", func(ctx context.Context, c *Client) error {
+			_, err := c.ListExpenses(ctx, ExpenseFilter{})
+			return err
+		}},
+		"createExpense": {"CreateExpense", func(ctx context.Context, c *Client) error {
+			_, err := c.CreateExpense(ctx, ExpenseInput{})
+			return err
+		}},
+		"getExpense": {"GetExpense", func(ctx context.Context, c *Client) error {
+			_, err := c.GetExpense(ctx, 3)
+			return err
+		}},
+		"updateExpense": {"UpdateExpense", func(ctx context.Context, c *Client) error {
+			_, err := c.UpdateExpense(ctx, 3, ExpenseInput{})
+			return err
+		}},
+		"deleteExpense": {"DeleteExpense", func(ctx context.Context, c *Client) error {
+			return c.DeleteExpense(ctx, 3)
+		}},
+		"listBudgets": {"ListBudgets", func(ctx context.Context, c *Client) error {
+			_, err := c.ListBudgets(ctx)
+			return err
+		}},
+		"setBudget": {"SetBudget", func(ctx context.Context, c *Client) error {
+			_, err := c.SetBudget(ctx, BudgetInput{})
+			return err
+		}},
+		"removeBudget": {"RemoveBudget", func(ctx context.Context, c *Client) error {
+			return c.RemoveBudget(ctx, 9, "Food")
+		}},
+		"listCategories": {"ListCategories", func(ctx context.Context, c *Client) error {
+			_, err := c.ListCategories(ctx)
+			return err
+		}},
+		"addCategory": {"AddCategory", func(ctx context.Context, c *Client) error {
+			_, err := c.AddCategory(ctx, "Food")
+			return err
+		}},
+		"removeCategory": {"RemoveCategory", func(ctx context.Context, c *Client) error {
+			return c.RemoveCategory(ctx, "Food:Eating out")
+		}},
+		"getSummary": {"Summary", func(ctx context.Context, c *Client) error {
+			_, err := c.Summary(ctx, ReportFilter{})
+			return err
+		}},
+		"getMonthlyReport": {"MonthlyReport", func(ctx context.Context, c *Client) error {
+			_, err := c.MonthlyReport(ctx, ReportFilter{})
+			return err
+		}},
+		"getBudgetReport": {"BudgetReport", func(ctx context.Context, c *Client) error {
+			_, err := c.BudgetReport(ctx, ReportFilter{})
+			return err
+		}},
+		"getForecast": {"Forecast", func(ctx context.Context, c *Client) error {
+			_, err := c.Forecast(ctx, "")
+			return err
+		}},
+	}
+
+	var sent *http.Request
+	recorder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r
+		http.Error(w, `{"error": "recorded"}`, http.StatusTeapot)
+	}))
+	defer recorder.Close()
+	recording := New(recorder.URL)
+
+	documented := map[string]bool{}
+	for path, methods := range document.Paths {
+		if !strings.HasPrefix(path, "/api/") {
+			continue
+		}
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, "{") {
+				segments[i] = "[^/]+"
+			} else {
+				segments[i] = regexp.QuoteMeta(segment)
+			}
+		}
+		pattern := regexp.MustCompile("^" + strings.Join(segments, "/") + "$")
+
+		for method, node := range methods {
+			// Besides the operations, a path may hold shared parameters
+			operation, ok := node.(map[string]any)
+			operationID, _ := operation["operationId"].(string)
+			if !ok || operationID == "" {
+				continue
+			}
+			documented[operationID] = true
+
+			mapped, ok := operations[operationID]
+			if !ok {
+				t.Errorf("%s %s (%s) has no client method", strings.ToUpper(method), path, operationID)
+				continue
+			}
+
+			sent = nil
+			if err := mapped.call(context.Background(), recording); !IsStatus(err, http.StatusTeapot) {
+				t.Errorf("%s() error = %v, want the recorded answer", mapped.method, err)
+				continue
+			}
+			if sent.Method != strings.ToUpper(method) || !pattern.MatchString(sent.URL.EscapedPath()) {
+				t.Errorf("%s() sends %s %s, want %s %s", mapped.method, sent.Method, sent.URL.EscapedPath(), strings.ToUpper(method), path)
+			}
+		}
+	}
+
+	mappedMethods := map[string]bool{}
+	for operationID, mapped := range operations {
+		mappedMethods[mapped.method] = true
+		if !documented[operationID] {
+			t.Errorf("operation %s of %s() is not in the OpenAPI document", operationID, mapped.method)
+		}
+	}
+
+	typ := reflect.TypeOf(c)
+	for i := range typ.NumMethod() {
+		if name := typ.Method(i).Name; !mappedMethods[name] {
+			t.Errorf("%s() is not mapped to an OpenAPI operation", name)
+		}
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
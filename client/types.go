@@ -0,0 +1,154 @@
+package client
+
+import "time"
+
+// The types below mirror the schemas of the OpenAPI document served at /openapi.json
+
+type Expense struct {
+	ID           int       `json:"id"`
+	Amount       float64   `json:"amount"`
+	Date         time.Time `json:"date"`
+	Month        int       `json:"month"`
+	IsDeleted    bool      `json:"is_deleted"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	Tags         []string  `json:"tags,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	IsIncome     bool      `json:"is_income,omitempty"`
+	ExternalID   string    `json:"external_id,omitempty"`
+	ValueDate    time.Time `json:"value_date,omitzero"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+}
+
+/**
+* The fields of an expense to add or change. Fields left nil are not sent,
+* so on update they keep their value; tags are replaced when not empty.
+* Date is in YYYY-MM-DD format.
+ */
+type ExpenseInput struct {
+	Amount      *float64 `json:"amount,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Category    *string  `json:"category,omitempty"`
+	Date        *string  `json:"date,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+	IsIncome    *bool    `json:"is_income,omitempty"`
+}
+
+type ExpensePage struct {
+	Items  []Expense `json:"items"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+/**
+* A newly added expense, with the IDs of the expenses it may duplicate.
+ */
+type CreatedExpense struct {
+	Expense
+	PossibleDuplicates []int
+}
+
+/**
+* Filters of ListExpenses. Zero values are left out: Month and Year 0 mean
+* any, Limit 0 means the server's default page size.
+ */
+type ExpenseFilter struct {
+	Month       int
+	Year        int
+	Category    string
+	From        string
+	To          string
+	Query       string
+	WithDeleted bool
+	Limit       int
+	Offset      int
+}
+
+type Budget struct {
+	Month    int     `json:"month"`
+	Year     int     `json:"year"`
+	Category string  `json:"category"`
+	Limit    float64 `json:"limit"`
+}
+
+type BudgetInput struct {
+	Month    int     `json:"month"`
+	Category string  `json:"category,omitempty"`
+	Limit    float64 `json:"limit"`
+}
+
+type Category struct {
+	Name string `json:"name"`
+}
+
+/**
+* Filters of the summary and reports. Zero values are left out.
+ */
+type ReportFilter struct {
+	Month    int
+	Year     int
+	Category string
+}
+
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Depth    int     `json:"depth"`
+	Own      float64 `json:"own"`
+	Total    float64 `json:"total"`
+}
+
+type MonthTotal struct {
+	Year   int     `json:"year"`
+	Month  int     `json:"month"`
+	Spent  float64 `json:"spent"`
+	Income float64 `json:"income"`
+	Count  int     `json:"count"`
+}
+
+type BudgetLine struct {
+	Year           int     `json:"year"`
+	Month          int     `json:"month"`
+	Category       string  `json:"category"`
+	Limit          float64 `json:"limit"`
+	Spent          float64 `json:"spent"`
+	Remaining      float64 `json:"remaining"`
+	PercentUsed    float64 `json:"percent_used"`
+	DaysLeft       int     `json:"days_left"`
+	DailyAllowance float64 `json:"daily_allowance"`
+}
+
+type ForecastLine struct {
+	Category   string  `json:"category"`
+	Spent      float64 `json:"spent"`
+	Recurring  float64 `json:"recurring"`
+	Forecast   float64 `json:"forecast"`
+	Limit      float64 `json:"limit"`
+	HasBudget  bool    `json:"has_budget"`
+	OverBudget bool    `json:"over_budget"`
+}
+
+type Summary struct {
+	Year          int             `json:"year"`
+	Month         int             `json:"month"`
+	TotalExpenses float64         `json:"total_expenses"`
+	TotalIncome   float64         `json:"total_income"`
+	Categories    []CategoryTotal `json:"categories"`
+	Budgets       []BudgetLine    `json:"budgets"`
+	Forecast      []ForecastLine  `json:"forecast,omitempty"`
+}
+
+func Float(v float64) *float64 {
+	return &v
+}
+
+func String(v string) *string {
+	return &v
+}
+
+func Bool(v bool) *bool {
+	return &v
+}
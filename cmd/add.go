@@ -2,18 +2,42 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/classifier"
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
 	"github.com/dmitriy-zverev/expense-tracker/internal/utils"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
 )
 
 /**
 * Adds a new expense after validating it.
+* Without --category the category and tags come from the first matching rule.
+* The category is mapped onto its canonical spelling and registered if new.
+* Expenses that look like one recorded before are added, but flagged.
+* Without any flags, the fields are asked for one by one instead.
 *
 * @param cmd The command containing the expense details.
 * @return An error if the expense creation, validation, or addition fails; otherwise, nil.
  */
 func add(cmd Command) error {
+	isGuided := isAddEmpty(cmd)
+	if isGuided {
+		answers, err := askExpense(cmd)
+		if err != nil {
+			return err
+		}
+		cmd = answers
+	}
+
 	exp, err := expense.CreateExpenseObj(
 		float64(cmd.Amount),
 		cmd.Description,
@@ -27,9 +51,323 @@ func add(cmd Command) error {
 		return errors.New("not valid expense")
 	}
 
+	date, err := commandDate(cmd)
+	if err != nil {
+		return err
+	}
+	exp.Date = date
+	exp.Month = int(date.Month())
+	exp.Tags = cmd.Tags
+
+	// The guided answers already offered the category and tags of the matching rule
+	if exp.Category == "" && !isGuided {
+		allRules, err := rules.GetRules()
+		if err != nil {
+			return err
+		}
+
+		exp, _ = rules.Apply(allRules, exp)
+	}
+
+	if exp.Category != "" {
+		canonical, err := category.Register(exp.Category)
+		if err != nil {
+			return err
+		}
+		exp.Category = canonical
+	}
+
+	existing, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := webhook.TakeSnapshot(exp.Date)
+	if err != nil {
+		return err
+	}
+
 	if err := expense.AddExpense(exp); err != nil {
 		return err
 	}
 
+	notifyChange(snapshot, webhook.EVENT_EXPENSE_CREATED, exp)
+
+	if isGuided {
+		fmt.Printf("Expense #%d has been added\n", exp.ID)
+	}
+
+	printDuplicateWarnings(duplicates.FindCandidates(existing, exp, duplicates.DefaultOptions()))
+
+	if exp.Category == "" {
+		if err := printSuggestions(exp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/**
+* Prints the categories that similar past expenses were filed under,
+* so the user can set one with `update` or `categorize --review`.
+ */
+func printSuggestions(exp expense.Expense) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	suggestions := classifier.Train(expenses).Suggest(exp.Description, SUGGESTIONS_LIMIT)
+	if len(suggestions) < 1 {
+		return nil
+	}
+
+	fmt.Printf("Expense #%d has no category. Suggested:", exp.ID)
+	for _, s := range suggestions {
+		fmt.Printf(" %s (%.0f%%)", s.Category, s.Confidence*100)
+	}
+	fmt.Printf("\n")
+
 	return nil
 }
+
+func isAddEmpty(cmd Command) bool {
+	return cmd.Amount == -1 &&
+		cmd.Description == "" &&
+		cmd.Category == "" &&
+		cmd.Date == "" &&
+		len(cmd.Tags) < 1
+}
+
+/**
+* Asks for the amount, description, category, date and tags of an expense.
+* Every answer is checked as soon as it is given and asked for again until
+* it is valid. Enter takes the default shown in brackets: today for the
+* date, and the category and tags of the matching rule.
+*
+* @param cmd The add command without flags.
+* @return The command with the answers filled in, or an error if input ends first.
+ */
+func askExpense(cmd Command) (Command, error) {
+	amount, err := askField("Amount", "", parseGuidedAmount)
+	if err != nil {
+		return Command{}, err
+	}
+
+	description, err := askField("Description", "", func(answer string) (string, error) {
+		if answer == "" {
+			return "", errors.New("description cannot be empty")
+		}
+		return answer, nil
+	})
+	if err != nil {
+		return Command{}, err
+	}
+
+	allRules, err := rules.GetRules()
+	if err != nil {
+		return Command{}, err
+	}
+
+	// The rule is matched as of today, before the date is asked for
+	draft := expense.Expense{Amount: amount, Description: description, Date: time.Now().UTC()}
+	draft, _ = rules.Apply(allRules, draft)
+
+	categoryName, err := askGuidedCategory(draft)
+	if err != nil {
+		return Command{}, err
+	}
+
+	date, err := askField("Date", time.Now().Format(DATE_INPUT_FORMAT), func(answer string) (string, error) {
+		if _, err := time.Parse(DATE_INPUT_FORMAT, answer); err != nil {
+			return "", errors.New("date must be in YYYY-MM-DD format")
+		}
+		return answer, nil
+	})
+	if err != nil {
+		return Command{}, err
+	}
+
+	tags, err := askField("Tags, separated by commas", strings.Join(draft.Tags, ", "), func(answer string) ([]string, error) {
+		tags := []string{}
+		if answer == GUIDED_NONE_ANSWER {
+			return tags, nil
+		}
+		for _, tag := range strings.Split(answer, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return tags, nil
+	})
+	if err != nil {
+		return Command{}, err
+	}
+
+	cmd.Amount = amount
+	cmd.Description = description
+	cmd.Category = categoryName
+	cmd.Date = date
+	cmd.Tags = tags
+
+	return cmd, nil
+}
+
+func parseGuidedAmount(answer string) (float64, error) {
+	if answer == "" {
+		return 0, errors.New("amount cannot be empty")
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(answer, ",", ".", 1), 64)
+	if err != nil {
+		return 0, errors.New("amount must be a number, e.g. 12.50")
+	}
+
+	if !utils.IsExpenseAmountValid(expense.Expense{Amount: amount}) {
+		return 0, errors.New("amount cannot be negative")
+	}
+
+	return amount, nil
+}
+
+/**
+* Asks for the category, offering the one of the matching rule and those of
+* similar past expenses by number. A name that is not registered yet is
+* only taken once it is typed again, after the registered categories that
+* look like it have been offered instead.
+ */
+func askGuidedCategory(draft expense.Expense) (string, error) {
+	registry, err := category.GetCategories()
+	if err != nil {
+		return "", err
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return "", err
+	}
+
+	choices := []string{}
+	if draft.Category != "" {
+		choices = append(choices, category.Resolve(registry, draft.Category))
+	}
+	for _, s := range classifier.Train(expenses).Suggest(draft.Description, SUGGESTIONS_LIMIT) {
+		if !slices.ContainsFunc(choices, func(c string) bool { return category.Equal(c, s.Category) }) {
+			choices = append(choices, s.Category)
+		}
+	}
+
+	defaultCategory := ""
+	if len(choices) > 0 {
+		defaultCategory = choices[0]
+		printChoices("Suggested categories:", choices)
+	}
+
+	unregistered := ""
+	for {
+		answer, err := askField("Category ("+GUIDED_NONE_ANSWER+" for none)", defaultCategory, func(answer string) (string, error) {
+			return answer, nil
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if answer == "" || answer == GUIDED_NONE_ANSWER {
+			return "", nil
+		}
+
+		if n, err := strconv.Atoi(answer); err == nil {
+			if n < 1 || n > len(choices) {
+				fmt.Printf("  no category %d, pick one of 1-%d or type a name\n", n, len(choices))
+				continue
+			}
+			return choices[n-1], nil
+		}
+
+		if idx := slices.IndexFunc(choices, func(c string) bool { return category.Equal(c, answer) }); idx >= 0 {
+			return choices[idx], nil
+		}
+
+		name := category.Resolve(registry, answer)
+		if slices.ContainsFunc(registry, func(c category.Category) bool { return c.Name == name }) || category.Equal(name, unregistered) {
+			return name, nil
+		}
+
+		similar := similarCategories(registry, answer)
+		if len(similar) < 1 {
+			return name, nil
+		}
+
+		fmt.Printf("  %s is not a category yet; type it again to add it, or pick a similar one\n", name)
+		choices, unregistered = similar, name
+		printChoices("Similar categories:", choices)
+	}
+}
+
+/**
+* The registered categories containing the typed text, case-insensitively.
+ */
+func similarCategories(registry []category.Category, text string) []string {
+	similar := []string{}
+	key := category.Key(text)
+
+	for _, c := range registry {
+		if key != "" && strings.Contains(category.Key(c.Name), key) {
+			similar = append(similar, c.Name)
+		}
+	}
+
+	if len(similar) > GUIDED_SIMILAR_LIMIT {
+		similar = similar[:GUIDED_SIMILAR_LIMIT]
+	}
+
+	return similar
+}
+
+func printChoices(title string, choices []string) {
+	fmt.Printf("%s", title)
+	for i, choice := range choices {
+		fmt.Printf(" %d) %s", i+1, choice)
+	}
+	fmt.Printf("\n")
+}
+
+/**
+* Asks one question until parse accepts the answer. An empty answer is
+* replaced with the default before it is parsed.
+*
+* @return The parsed answer, or an error if input ends or cannot be read.
+ */
+func askField[T any](label, defaultValue string, parse func(answer string) (T, error)) (T, error) {
+	var zero T
+
+	for {
+		if defaultValue != "" {
+			fmt.Printf("%s [%s]: ", label, defaultValue)
+		} else {
+			fmt.Printf("%s: ", label)
+		}
+
+		line, err := stdin.ReadString('\n')
+		if err != nil && (line == "" || err != io.EOF) {
+			if err == io.EOF {
+				return zero, errors.New("input ended, no expense has been added")
+			}
+			return zero, err
+		}
+
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = defaultValue
+		}
+
+		value, err := parse(answer)
+		if err != nil {
+			fmt.Printf("  %v\n", err)
+			continue
+		}
+
+		return value, nil
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+)
+
+/**
+* Prints the audit log, oldest entry first. --name keeps the changes of one
+* user and --days the changes of the last days.
+ */
+func auditCmd(cmd Command) error {
+	entries, err := audit.GetEntries()
+	if err != nil {
+		return err
+	}
+
+	since := time.Time{}
+	if cmd.Days != -1 {
+		since = time.Now().AddDate(0, 0, -cmd.Days)
+	}
+
+	entries = audit.Filter(entries, cmd.Name, since)
+	if len(entries) < 1 {
+		fmt.Printf("No changes recorded\n")
+		return nil
+	}
+
+	for _, entry := range entries {
+		user := entry.User
+		if user == "" {
+			user = "(local)"
+		}
+
+		fmt.Printf(
+			"%s\t%-15s\t%-16s\t%s",
+			entry.Time.Local().Format("2006-01-02 15:04:05"),
+			user,
+			entry.Action,
+			entry.Target,
+		)
+
+		if entry.Details != "" {
+			fmt.Printf("\t%s", entry.Details)
+		}
+
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
 )
 
 const (
@@ -34,10 +37,27 @@ func budgetCmd(cmd Command) error {
 }
 
 func setBudget(cmd Command) error {
-	if err := budget.SetBudget(cmd.Month, cmd.Category, cmd.Limit); err != nil {
+	categoryName := cmd.Category
+	if categoryName != "" {
+		canonical, err := category.Register(categoryName)
+		if err != nil {
+			return err
+		}
+		categoryName = canonical
+	}
+
+	// Budgets are set for the current year, so a lower limit may cross a threshold now
+	snapshot, err := webhook.TakeSnapshot(time.Date(time.Now().Year(), time.Month(cmd.Month), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
 		return err
 	}
 
+	if err := budget.SetBudget(cmd.Month, categoryName, cmd.Limit); err != nil {
+		return err
+	}
+
+	notifyChange(snapshot, "", nil)
+
 	return nil
 }
 
@@ -69,7 +89,12 @@ func listBudget() error {
 }
 
 func removeBudget(cmd Command) error {
-	if err := budget.RemoveBudget(cmd.Month, cmd.Category); err != nil {
+	categoryName, err := category.Canonical(cmd.Category)
+	if err != nil {
+		return err
+	}
+
+	if err := budget.RemoveBudget(cmd.Month, categoryName); err != nil {
 		return err
 	}
 
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/classifier"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+/**
+* Proposes categories for every non-deleted expense without one, using a
+* classifier trained on the already categorised expenses. With --review the
+* user is asked to accept a suggestion, type a category or skip each expense.
+*
+* @param cmd The command containing the --review flag.
+* @return An error if expenses cannot be read or updated; otherwise, nil.
+ */
+func categorize(cmd Command) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	model := classifier.Train(expenses)
+	reader := stdin
+	reviewed := 0
+
+	for _, exp := range expenses {
+		if exp.IsDeleted || exp.IsIncome || category.Normalize(exp.Category) != "" {
+			continue
+		}
+
+		suggestions := model.Suggest(exp.Description, SUGGESTIONS_LIMIT)
+		printUncategorized(exp, suggestions)
+
+		if !cmd.Review {
+			continue
+		}
+
+		choice, quit, err := askCategory(reader, suggestions)
+		if err != nil {
+			return err
+		}
+
+		if quit {
+			break
+		}
+
+		if choice == "" {
+			continue
+		}
+
+		canonical, err := category.Register(choice)
+		if err != nil {
+			return err
+		}
+
+		if err := expense.UpdateExpense(exp.ID, exp.Amount, exp.Description, canonical); err != nil {
+			return err
+		}
+
+		reviewed++
+	}
+
+	if cmd.Review {
+		fmt.Printf("\nCategorised %d expense(s)\n", reviewed)
+	}
+
+	return nil
+}
+
+func printUncategorized(exp expense.Expense, suggestions []classifier.Suggestion) {
+	year, month, day := exp.Date.Date()
+
+	fmt.Printf(
+		"\n# %d\t%d-%d-%d\t%s\t%.2f\n",
+		exp.ID,
+		year,
+		month,
+		day,
+		exp.Description,
+		exp.Amount,
+	)
+
+	if len(suggestions) < 1 {
+		fmt.Printf("  no suggestions\n")
+	}
+
+	for i, s := range suggestions {
+		fmt.Printf("  %d) %s (%.0f%%)\n", i+1, s.Category, s.Confidence*100)
+	}
+}
+
+/**
+* Reads the user's decision for one expense.
+*
+* @return The chosen category (empty to skip), whether the user wants to stop, or an error if input cannot be read.
+ */
+func askCategory(reader *bufio.Reader, suggestions []classifier.Suggestion) (string, bool, error) {
+	fmt.Printf("Pick a number, type a category, press Enter to skip or q to quit: ")
+
+	line, err := reader.ReadString('\n')
+	if err == io.EOF {
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	answer := strings.TrimSpace(line)
+	if answer == "q" {
+		return "", true, nil
+	}
+
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(suggestions) {
+			fmt.Printf("  no suggestion %d, skipped\n", n)
+			return "", false, nil
+		}
+		return suggestions[n-1].Category, false, nil
+	}
+
+	return answer, false, nil
+}
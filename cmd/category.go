@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+)
+
+func categoryCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case CATEGORY_ADD_CMD:
+		if err := addCategory(cmd); err != nil {
+			return err
+		}
+	case CATEGORY_LIST_CMD:
+		if err := listCategories(); err != nil {
+			return err
+		}
+	case CATEGORY_REMOVE_CMD:
+		if err := removeCategory(cmd); err != nil {
+			return err
+		}
+	case CATEGORY_RENAME_CMD:
+		change, err := category.Rename(cmd.From, cmd.To, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+		printCategoryChange("Renamed", change, cmd.DryRun)
+	case CATEGORY_MERGE_CMD:
+		change, err := category.Merge(cmd.From, cmd.To, cmd.DryRun)
+		if err != nil {
+			return err
+		}
+		printCategoryChange("Merged", change, cmd.DryRun)
+	default:
+		return errors.New("command for category is not provided")
+	}
+
+	return nil
+}
+
+func addCategory(cmd Command) error {
+	name, err := category.AddCategory(cmd.Category)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Category '%s' has been added\n", name)
+
+	return nil
+}
+
+func listCategories() error {
+	categories, err := category.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range categories {
+		segments := strings.Split(c.Name, category.SEPARATOR)
+
+		fmt.Printf(
+			"%s%s\n",
+			strings.Repeat("  ", len(segments)-1),
+			segments[len(segments)-1],
+		)
+	}
+
+	return nil
+}
+
+func removeCategory(cmd Command) error {
+	if err := category.RemoveCategory(cmd.Category); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func printCategoryChange(action string, change category.Change, dryRun bool) {
+	if dryRun {
+		fmt.Printf("Dry run, nothing has been changed\n\n")
+	}
+
+	fmt.Printf(
+		"%s '%s' into '%s': %d expense(s), %d budget(s)\n",
+		action,
+		change.From,
+		change.To,
+		len(change.Expenses),
+		len(change.Budgets),
+	)
+
+	for _, exp := range change.Expenses {
+		fmt.Printf("  expense #%d: %s -> %s\n", exp.ID, exp.From, exp.To)
+	}
+
+	for _, b := range change.Budgets {
+		fmt.Printf("  budget %d-%02d: %s -> %s (%.2f)", b.Year, b.Month, b.From, b.To, b.Limit)
+		if b.IsMerged {
+			fmt.Printf(" added to existing budget")
+		}
+		fmt.Printf("\n")
+	}
+}
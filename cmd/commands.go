@@ -16,7 +16,20 @@ var commands map[string]cliCommand
 * - "list": Lists all expenses
 * - "delete": Deletes an expense by its ID
 * - "update": Updates an expense by its id
-* - "summary": Summarizes all expenses—if set within provided month
+* - "summary": Summarizes expenses and reports budget vs actual for a month
+* - "forecast": Projects end-of-month spending for the current month
+* - "category": Manages the category registry and renames or merges categories
+* - "rules": Manages auto-categorisation rules
+* - "categorize": Suggests categories for uncategorised expenses
+* - "duplicates": Finds, merges or dismisses possible duplicate expenses
+* - "settings": Shows and changes the settings
+* - "serve": Serves a JSON REST API, a web dashboard and metrics on localhost
+* - "token": Manages the API tokens of the server
+* - "audit": Shows the changes made through the API
+* - "webhook": Manages the webhooks notified of expense and budget events
+* - "email": Sets up and sends monthly statements and budget alerts by email
+* - "tui": Opens a full-screen terminal interface to browse and edit expenses
+* - "shell": Runs commands one after another in an interactive shell
  */
 func initCommands() {
 	commands = map[string]cliCommand{
@@ -42,18 +55,88 @@ func initCommands() {
 		},
 		"summary": {
 			Name:        "summary",
-			Description: "Summarizes all expenses—if set within provided month",
+			Description: "Summarizes expenses and reports budget vs actual for a month",
 			Callback:    summary,
 		},
+		"forecast": {
+			Name:        "forecast",
+			Description: "Projects end-of-month spending and flags budgets forecast to be exceeded",
+			Callback:    forecast,
+		},
 		"export": {
 			Name:        "export",
-			Description: "Exports expenses into a .csv file—if set with custom file name",
+			Description: "Exports expenses as CSV, QIF, ledger, beancount or xlsx, or a json/ndjson backup of the ledger—without API tokens, webhooks or email settings—into a file or stdout—if set with custom path",
 			Callback:    export,
 		},
+		"import": {
+			Name:        "import",
+			Description: "Imports bank statements—csv with saved column mapping profiles, ofx, qfx, qif, camt, mt940, ledger, beancount and json backups. In csv, negative amounts are incomes unless the profile sets --negative-expense",
+			Callback:    importCmd,
+		},
 		"budget": {
 			Name:        "budget",
 			Description: "Sets budget for given month and category with provided limit",
 			Callback:    budgetCmd,
 		},
+		"category": {
+			Name:        "category",
+			Description: "Manages the category registry: add, list or remove categories, or rename or merge one with --from and --to across expenses and budgets—if set with --dry-run, only shows what would change",
+			Callback:    categoryCmd,
+		},
+		"rules": {
+			Name:        "rules",
+			Description: "Manages auto-categorisation rules: add, list, test, remove or apply",
+			Callback:    rulesCmd,
+		},
+		"categorize": {
+			Name:        "categorize",
+			Description: "Suggests categories for uncategorised expenses—if set with --review, walks through them",
+			Callback:    categorize,
+		},
+		"duplicates": {
+			Name:        "duplicates",
+			Description: "Lists possible duplicate expenses—if set with --review, merges or dismisses them",
+			Callback:    duplicatesCmd,
+		},
+		"settings": {
+			Name:        "settings",
+			Description: "Shows or changes the settings, e.g. the funding account of journal exports",
+			Callback:    settingsCmd,
+		},
+		"serve": {
+			Name:        "serve",
+			Description: "Serves a JSON REST API, a web dashboard and Prometheus metrics on localhost—if set with --addr, on that address",
+			Callback:    serve,
+		},
+		"token": {
+			Name:        "token",
+			Description: "Manages API tokens: create (--name, --scope read|write), list or revoke (--id)",
+			Callback:    tokenCmd,
+		},
+		"audit": {
+			Name:        "audit",
+			Description: "Shows the changes made through the API and to tokens—if set with --name or --days, only those",
+			Callback:    auditCmd,
+		},
+		"webhook": {
+			Name:        "webhook",
+			Description: "Manages webhooks: add (--url, --events), list, remove (--id), queue or deliver",
+			Callback:    webhookCmd,
+		},
+		"email": {
+			Name:        "email",
+			Description: "Emails monthly statements and budget alerts: setup (--smtp, --from, --to, --user, --events), show, test, statement (--month, --year) or remove",
+			Callback:    emailCmd,
+		},
+		"tui": {
+			Name:        "tui",
+			Description: "Opens a full-screen terminal interface to browse, filter and edit expenses, with budget gauges",
+			Callback:    tuiCmd,
+		},
+		"shell": {
+			Name:        "shell",
+			Description: "Runs commands without the et prefix in an interactive shell, with history and tab completion of categories",
+			Callback:    shellCmd,
+		},
 	}
 }
@@ -1,14 +1,54 @@
 package cmd
 
+import "time"
+
 const (
-	DESCRIPTION_PARAM  = "--description"
-	AMOUNT_PARAM       = "--amount"
-	ID_PARAM           = "--id"
-	MONTH_PARAM        = "--month"
-	CATEGORY_PARAM     = "--category"
-	WITH_DELETED_PARAM = "--with-deleted"
-	OUTPUT_PARAM       = "--output"
-	LIMIT_PARAM        = "--limit"
+	DESCRIPTION_PARAM        = "--description"
+	AMOUNT_PARAM             = "--amount"
+	ID_PARAM                 = "--id"
+	MONTH_PARAM              = "--month"
+	CATEGORY_PARAM           = "--category"
+	WITH_DELETED_PARAM       = "--with-deleted"
+	OUTPUT_PARAM             = "--output"
+	LIMIT_PARAM              = "--limit"
+	YEAR_PARAM               = "--year"
+	FROM_PARAM               = "--from"
+	TO_PARAM                 = "--to"
+	DRY_RUN_PARAM            = "--dry-run"
+	CONTAINS_PARAM           = "--contains"
+	REGEX_PARAM              = "--regex"
+	MIN_AMOUNT_PARAM         = "--min-amount"
+	MAX_AMOUNT_PARAM         = "--max-amount"
+	WEEKDAY_PARAM            = "--weekday"
+	TAGS_PARAM               = "--tags"
+	DATE_PARAM               = "--date"
+	REVIEW_PARAM             = "--review"
+	COLUMNS_PARAM            = "--columns"
+	DELIMITER_PARAM          = "--delimiter"
+	DATE_FORMAT_PARAM        = "--date-format"
+	FILE_PARAM               = "--file"
+	PROFILE_PARAM            = "--profile"
+	PREVIEW_PARAM            = "--preview"
+	NAME_PARAM               = "--name"
+	DATE_COLUMN_PARAM        = "--date-column"
+	AMOUNT_COLUMN_PARAM      = "--amount-column"
+	DESCRIPTION_COLUMN_PARAM = "--description-column"
+	CATEGORY_COLUMN_PARAM    = "--category-column"
+	DECIMAL_PARAM            = "--decimal"
+	NEGATIVE_EXPENSE_PARAM   = "--negative-expense"
+	NO_HEADER_PARAM          = "--no-header"
+	FORMAT_PARAM             = "--format"
+	ACCOUNT_PARAM            = "--account"
+	REPLACE_PARAM            = "--replace"
+	DAYS_PARAM               = "--days"
+	INTO_PARAM               = "--into"
+	WITH_PARAM               = "--with"
+	ADDR_PARAM               = "--addr"
+	SCOPE_PARAM              = "--scope"
+	URL_PARAM                = "--url"
+	EVENTS_PARAM             = "--events"
+	SMTP_PARAM               = "--smtp"
+	USER_PARAM               = "--user"
 )
 
 const (
@@ -18,6 +58,103 @@ const (
 )
 
 const (
-	PRINT_MAX_DESCRIPTION_LENGTH = 20
-	DEFAULT_EXPORT_FILE_PATH     = "./csv/expenses.csv"
+	CATEGORY_ADD_CMD    = "add"
+	CATEGORY_LIST_CMD   = "list"
+	CATEGORY_REMOVE_CMD = "remove"
+	CATEGORY_RENAME_CMD = "rename"
+	CATEGORY_MERGE_CMD  = "merge"
+)
+
+const (
+	TOKEN_CREATE_CMD = "create"
+	TOKEN_LIST_CMD   = "list"
+	TOKEN_REVOKE_CMD = "revoke"
+)
+
+const (
+	WEBHOOK_ADD_CMD     = "add"
+	WEBHOOK_LIST_CMD    = "list"
+	WEBHOOK_REMOVE_CMD  = "remove"
+	WEBHOOK_QUEUE_CMD   = "queue"
+	WEBHOOK_DELIVER_CMD = "deliver"
+)
+
+const (
+	EMAIL_SETUP_CMD     = "setup"
+	EMAIL_SHOW_CMD      = "show"
+	EMAIL_TEST_CMD      = "test"
+	EMAIL_STATEMENT_CMD = "statement"
+	EMAIL_REMOVE_CMD    = "remove"
+)
+
+const (
+	RULES_ADD_CMD    = "add"
+	RULES_LIST_CMD   = "list"
+	RULES_TEST_CMD   = "test"
+	RULES_REMOVE_CMD = "remove"
+	RULES_APPLY_CMD  = "apply"
+)
+
+const (
+	IMPORT_CSV_CMD       = "csv"
+	IMPORT_OFX_CMD       = "ofx"
+	IMPORT_QFX_CMD       = "qfx"
+	IMPORT_QIF_CMD       = "qif"
+	IMPORT_CAMT_CMD      = "camt"
+	IMPORT_MT940_CMD     = "mt940"
+	IMPORT_LEDGER_CMD    = "ledger"
+	IMPORT_BEANCOUNT_CMD = "beancount"
+	IMPORT_JSON_CMD      = "json"
+	IMPORT_PROFILE_CMD   = "profile"
+)
+
+const (
+	EXPORT_FORMAT_CSV       = "csv"
+	EXPORT_FORMAT_QIF       = "qif"
+	EXPORT_FORMAT_LEDGER    = "ledger"
+	EXPORT_FORMAT_HLEDGER   = "hledger"
+	EXPORT_FORMAT_BEANCOUNT = "beancount"
+	EXPORT_FORMAT_JSON      = "json"
+	EXPORT_FORMAT_NDJSON    = "ndjson"
+	EXPORT_FORMAT_XLSX      = "xlsx"
+)
+
+const (
+	DUPLICATES_LIST_CMD    = "list"
+	DUPLICATES_MERGE_CMD   = "merge"
+	DUPLICATES_DISMISS_CMD = "dismiss"
+)
+
+const (
+	SETTINGS_LIST_CMD = "list"
+	SETTINGS_SET_CMD  = "set"
+)
+
+const (
+	PROFILE_SET_CMD    = "set"
+	PROFILE_LIST_CMD   = "list"
+	PROFILE_REMOVE_CMD = "remove"
+)
+
+const (
+	PRINT_MAX_DESCRIPTION_LENGTH       = 20
+	DEFAULT_EXPORT_FILE_PATH           = "./csv/expenses.csv"
+	DEFAULT_QIF_EXPORT_FILE_PATH       = "./qif/expenses.qif"
+	DEFAULT_LEDGER_EXPORT_FILE_PATH    = "./ledger/expenses.journal"
+	DEFAULT_BEANCOUNT_EXPORT_FILE_PATH = "./ledger/expenses.beancount"
+	DEFAULT_XLSX_EXPORT_FILE_PATH      = "./xlsx/expenses.xlsx"
+	DEFAULT_JSON_EXPORT_FILE_PATH      = "./backup/expense-tracker.json"
+	DEFAULT_NDJSON_EXPORT_FILE_PATH    = "./backup/expense-tracker.ndjson"
+	DATE_INPUT_FORMAT                  = "2006-01-02"
+	SUGGESTIONS_LIMIT                  = 3
+	SERVER_SHUTDOWN_TIMEOUT            = 5 * time.Second
+	STDOUT_OUTPUT                      = "-"
+	UNCATEGORIZED_LABEL                = "(uncategorized)"
+)
+
+const (
+	// Typed at a question of the guided add to leave the category or tags empty
+	GUIDED_NONE_ANSWER = "-"
+	// The most registered categories offered instead of a new one
+	GUIDED_SIMILAR_LIMIT = 5
 )
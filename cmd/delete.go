@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
 )
 
 /**
@@ -17,9 +18,20 @@ func delete(cmd Command) error {
 		return errors.New("id not provided")
 	}
 
+	exp, err := expense.GetExpense(cmd.ID)
+	if err != nil {
+		return err
+	}
+
 	if err := expense.DeleteExpense(cmd.ID); err != nil {
 		return err
 	}
 
+	// Deleting only lowers spending, so no budget can cross a threshold
+	if !exp.IsDeleted {
+		exp.IsDeleted = true
+		notifyChange(webhook.Snapshot{}, webhook.EVENT_EXPENSE_DELETED, exp)
+	}
+
 	return nil
 }
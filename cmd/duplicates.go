@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func duplicatesCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case "", DUPLICATES_LIST_CMD:
+		if err := listDuplicates(cmd); err != nil {
+			return err
+		}
+	case DUPLICATES_MERGE_CMD:
+		if cmd.ID == -1 || cmd.OtherID == -1 {
+			return errors.New("provide the duplicate with --id and the expense to keep with --into")
+		}
+		merged, err := duplicates.Merge(cmd.OtherID, cmd.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Merged expense #%d into #%d\n", cmd.ID, merged.ID)
+	case DUPLICATES_DISMISS_CMD:
+		if cmd.ID == -1 || cmd.OtherID == -1 {
+			return errors.New("provide both expenses with --id and --with")
+		}
+		if err := duplicates.Dismiss(cmd.ID, cmd.OtherID); err != nil {
+			return err
+		}
+		fmt.Printf("Expenses #%d and #%d will no longer be reported as duplicates\n", cmd.ID, cmd.OtherID)
+	default:
+		return errors.New("unknown command for duplicates: " + cmd.SubCmd)
+	}
+
+	return nil
+}
+
+/**
+* Lists the pairs of expenses that look like the same payment. With --review
+* the user is asked to merge, dismiss or skip each pair; merging keeps the
+* expense that was recorded first.
+*
+* @param cmd The command containing --days and the --review flag.
+* @return An error if expenses cannot be read or updated; otherwise, nil.
+ */
+func listDuplicates(cmd Command) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	dismissals, err := duplicates.GetDismissals()
+	if err != nil {
+		return err
+	}
+
+	options := duplicates.DefaultOptions()
+	if cmd.Days != -1 {
+		options.WindowDays = cmd.Days
+	}
+
+	pairs := duplicates.FindPairs(expenses, dismissals, options)
+	if len(pairs) < 1 {
+		fmt.Println("No possible duplicates found")
+		return nil
+	}
+
+	reader := stdin
+	merged := map[int]bool{}
+	reviewed := 0
+
+	for _, pair := range pairs {
+		if merged[pair.Original.ID] || merged[pair.Duplicate.ID] {
+			continue
+		}
+
+		printDuplicatePair(pair)
+
+		if !cmd.Review {
+			continue
+		}
+
+		answer, err := askDuplicate(reader, pair)
+		if err != nil {
+			return err
+		}
+
+		switch answer {
+		case "q":
+			fmt.Printf("\nReviewed %d pair(s)\n", reviewed)
+			return nil
+		case "m":
+			if _, err := duplicates.Merge(pair.Original.ID, pair.Duplicate.ID); err != nil {
+				return err
+			}
+			merged[pair.Duplicate.ID] = true
+			reviewed++
+		case "k":
+			if err := duplicates.Dismiss(pair.Original.ID, pair.Duplicate.ID); err != nil {
+				return err
+			}
+			reviewed++
+		}
+	}
+
+	if cmd.Review {
+		fmt.Printf("\nReviewed %d pair(s)\n", reviewed)
+	} else {
+		fmt.Printf("\n%d possible duplicate(s). Review them with `duplicates --review`, or use `duplicates merge --id <duplicate> --into <id>` and `duplicates dismiss --id <id> --with <id>`\n", len(pairs))
+	}
+
+	return nil
+}
+
+func printDuplicatePair(pair duplicates.Pair) {
+	fmt.Printf("\n#%d and #%d look alike (%.0f%% similar):\n", pair.Original.ID, pair.Duplicate.ID, pair.Similarity*100)
+
+	for _, exp := range []expense.Expense{pair.Original, pair.Duplicate} {
+		description := truncate(exp.Description, PRINT_MAX_DESCRIPTION_LENGTH)
+
+		fmt.Printf(
+			"  # %d\t%s\t%s%s%.2f\t%s",
+			exp.ID,
+			exp.Date.Format(DATE_INPUT_FORMAT),
+			description,
+			padding(description),
+			exp.Amount,
+			exp.Category,
+		)
+
+		if exp.ExternalID != "" {
+			fmt.Printf("\t(imported)")
+		}
+		fmt.Printf("\n")
+	}
+}
+
+/**
+* Reads the user's decision for one pair.
+*
+* @return "m" to merge, "k" to keep both, "q" to quit or "" to skip, or an error if input cannot be read.
+ */
+func askDuplicate(reader *bufio.Reader, pair duplicates.Pair) (string, error) {
+	fmt.Printf(
+		"m to merge #%d into #%d, k to keep both, Enter to skip or q to quit: ",
+		pair.Duplicate.ID,
+		pair.Original.ID,
+	)
+
+	line, err := reader.ReadString('\n')
+	if err == io.EOF {
+		return "q", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	switch answer {
+	case "m", "k", "q":
+		return answer, nil
+	}
+
+	return "", nil
+}
+
+/**
+* Warns about expenses that look like the same payment as a new one.
+ */
+func printDuplicateWarnings(pairs []duplicates.Pair) {
+	for _, pair := range pairs {
+		fmt.Printf(
+			"Possible duplicate: #%d %s '%s' %.2f looks like #%d %s '%s'\n",
+			pair.Duplicate.ID,
+			pair.Duplicate.Date.Format(DATE_INPUT_FORMAT),
+			pair.Duplicate.Description,
+			pair.Duplicate.Amount,
+			pair.Original.ID,
+			pair.Original.Date.Format(DATE_INPUT_FORMAT),
+			pair.Original.Description,
+		)
+	}
+
+	if len(pairs) > 0 {
+		fmt.Println("Review possible duplicates with `duplicates --review`")
+	}
+}
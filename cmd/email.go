@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+func emailCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case EMAIL_SETUP_CMD:
+		if err := setupEmail(cmd); err != nil {
+			return err
+		}
+	case EMAIL_SHOW_CMD:
+		if err := showEmail(); err != nil {
+			return err
+		}
+	case EMAIL_TEST_CMD:
+		if err := sendEmail(mail.Message{
+			Subject: "Test email from expense-tracker",
+			Text:    "Emails from expense-tracker reach you.\n",
+			HTML:    "<p>Emails from expense-tracker reach you.</p>",
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("Test email has been sent\n")
+	case EMAIL_STATEMENT_CMD:
+		if err := sendStatement(cmd); err != nil {
+			return err
+		}
+	case EMAIL_REMOVE_CMD:
+		if err := mail.RemoveConfig(); err != nil {
+			return err
+		}
+		fmt.Printf("Email has been turned off\n")
+	default:
+		return errors.New("command for email is not provided")
+	}
+
+	return nil
+}
+
+/**
+* Saves the SMTP server and the recipients. Settings that are not given
+* are kept from the previous setup. The first scheduled statement is the
+* one of the current month.
+ */
+func setupEmail(cmd Command) error {
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if cmd.SMTP != "" {
+		host, port, err := smtpAddress(cmd.SMTP)
+		if err != nil {
+			return err
+		}
+		config.Host, config.Port = host, port
+	}
+
+	if cmd.From != "" {
+		config.From = cmd.From
+	}
+
+	if cmd.To != "" {
+		config.To = []string{}
+		for _, recipient := range strings.Split(cmd.To, ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				config.To = append(config.To, recipient)
+			}
+		}
+	}
+
+	if cmd.User != "" {
+		config.Username = cmd.User
+	}
+
+	if cmd.Events != nil {
+		config.Events = cmd.Events
+	}
+
+	if config.LastStatement == "" {
+		config.LastStatement = time.Now().AddDate(0, -1, 0).Format(mail.PERIOD_FORMAT)
+	}
+
+	if err := mail.SaveConfig(config); err != nil {
+		return err
+	}
+
+	fmt.Printf("Email set up for %s\n", strings.Join(config.To, ", "))
+	if config.Username != "" {
+		fmt.Printf("Export the SMTP password as %s wherever statements and alerts are sent from\n", mail.PASSWORD_ENV)
+	}
+
+	return nil
+}
+
+func showEmail() error {
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if !mail.IsConfigured(config) {
+		fmt.Printf("Email is not set up, see 'email setup'\n")
+		return nil
+	}
+
+	events := "statements and alerts"
+	if len(config.Events) > 0 {
+		events = strings.Join(config.Events, ", ")
+	}
+
+	fmt.Printf("SMTP server: %s\n", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+	if config.Username != "" {
+		fmt.Printf("User: %s (password from %s)\n", config.Username, mail.PASSWORD_ENV)
+	}
+	fmt.Printf("From: %s\n", config.From)
+	fmt.Printf("To: %s\n", strings.Join(config.To, ", "))
+	fmt.Printf("Sends: %s\n", events)
+	fmt.Printf("Last scheduled statement: %s\n", config.LastStatement)
+
+	return nil
+}
+
+/**
+* Emails the statement of a month now, by default the previous one. When
+* that is the statement the schedule has yet to send, `serve` will not send
+* it again, so a cron job can take the place of the schedule.
+ */
+func sendStatement(cmd Command) error {
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	previous := now.AddDate(0, 0, -now.Day())
+	year, month := previous.Year(), int(previous.Month())
+	if cmd.Year != -1 {
+		year = cmd.Year
+	}
+	if cmd.Month != -1 {
+		month = cmd.Month
+	}
+
+	if month < 1 || month > 12 {
+		return errors.New("month must be between 1 and 12")
+	}
+
+	message, err := mail.StatementMessage(year, month, now.UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := sendEmail(message); err != nil {
+		return err
+	}
+
+	fmt.Printf("Statement for %v %d has been sent\n", time.Month(month).String(), year)
+
+	if dueYear, dueMonth, ok := mail.DueStatement(config, now); ok && dueYear == year && dueMonth == month {
+		return mail.MarkStatementSent(year, month)
+	}
+
+	return nil
+}
+
+/**
+* Emails an alert for the budgets a change pushed over a threshold, if
+* alerts are wanted.
+ */
+func sendBudgetAlerts(crossings []report.Crossing) error {
+	if len(crossings) < 1 {
+		return nil
+	}
+
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if !mail.Wants(config, mail.EVENT_ALERT) {
+		return nil
+	}
+
+	message, err := mail.AlertMessage(crossings)
+	if err != nil {
+		return err
+	}
+
+	return sendEmail(message)
+}
+
+func sendEmail(message mail.Message) error {
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if !mail.IsConfigured(config) {
+		return errors.New("email is not set up, see 'email setup'")
+	}
+
+	password, err := mail.Password(config)
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(config, password, message)
+}
+
+// "smtp.example.com:465" or just "smtp.example.com" for DEFAULT_PORT
+func smtpAddress(addr string) (string, int, error) {
+	host, rawPort, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, mail.DEFAULT_PORT, nil
+	}
+
+	port, err := strconv.Atoi(rawPort)
+	if err != nil {
+		return "", 0, errors.New("argument for --smtp must be host or host:port, e.g. smtp.example.com:587")
+	}
+
+	return host, port, nil
+}
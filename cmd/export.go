@@ -1,53 +1,278 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/dmitriy-zverev/expense-tracker/internal/backup"
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/csvio"
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/journal"
+	"github.com/dmitriy-zverev/expense-tracker/internal/qif"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+	"github.com/dmitriy-zverev/expense-tracker/internal/settings"
+	"github.com/dmitriy-zverev/expense-tracker/internal/xlsx"
 )
 
+/**
+* Exports the expenses selected by --month, --year, --category and
+* --with-deleted as CSV or, with --format, as QIF, a ledger/hledger journal,
+* a beancount file or an xlsx workbook to --output, a file path or "-" for stdout. Journals are
+* balanced against --account or the funding account from the settings.
+* The json and ndjson formats are full backups and take no filters.
+*
+* @param cmd The command containing the filters, the format, the account and CSV options.
+* @return An error if the options are invalid or the export cannot be written; otherwise, nil.
+ */
 func export(cmd Command) error {
-	expenses, err := expense.GetExpenses()
+	format := cmd.Format
+	if format == "" {
+		format = EXPORT_FORMAT_CSV
+	}
+
+	defaultPath := DEFAULT_EXPORT_FILE_PATH
+	switch format {
+	case EXPORT_FORMAT_CSV:
+	case EXPORT_FORMAT_QIF:
+		defaultPath = DEFAULT_QIF_EXPORT_FILE_PATH
+	case EXPORT_FORMAT_LEDGER, EXPORT_FORMAT_HLEDGER:
+		defaultPath = DEFAULT_LEDGER_EXPORT_FILE_PATH
+	case EXPORT_FORMAT_BEANCOUNT:
+		defaultPath = DEFAULT_BEANCOUNT_EXPORT_FILE_PATH
+	case EXPORT_FORMAT_XLSX:
+		defaultPath = DEFAULT_XLSX_EXPORT_FILE_PATH
+	case EXPORT_FORMAT_JSON:
+		return exportBackup(cmd, format, DEFAULT_JSON_EXPORT_FILE_PATH)
+	case EXPORT_FORMAT_NDJSON:
+		return exportBackup(cmd, format, DEFAULT_NDJSON_EXPORT_FILE_PATH)
+	default:
+		return errors.New("unknown export format " + format + ", available: csv, qif, ledger, hledger, beancount, xlsx, json, ndjson")
+	}
+
+	if format != EXPORT_FORMAT_CSV && (cmd.Columns != "" || cmd.Delimiter != "" || cmd.DateFormat != "") {
+		return errors.New("--columns, --delimiter and --date-format only apply to csv")
+	}
+
+	fundingAccount, err := exportFundingAccount(cmd, format)
 	if err != nil {
 		return err
 	}
 
-	exportFilePath := DEFAULT_EXPORT_FILE_PATH
-	if cmd.Output != "" {
-		exportFilePath = "./csv/" + cmd.Output
+	options, err := exportOptions(cmd)
+	if err != nil {
+		return err
 	}
 
-	if _, err := os.Stat("./csv"); os.IsNotExist(err) {
-		if err := os.Mkdir("./csv", 0755); err != nil {
-			return err
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	selected := []expense.Expense{}
+	for _, exp := range expenses {
+		if isExpenseSelected(cmd, exp) {
+			selected = append(selected, exp)
 		}
 	}
 
-	exportFile, err := os.OpenFile(exportFilePath, os.O_CREATE|os.O_RDWR, 0755)
+	output, exportFilePath, err := openExportOutput(cmd.Output, defaultPath)
 	if err != nil {
 		return err
 	}
-	defer exportFile.Close()
 
-	csvString := "ID,Date,Description,Amount,Category,Month\n"
-	for _, exp := range expenses {
-		csvString += fmt.Sprintf(
-			"%d,%v,%s,%.2f,%s,%d\n",
-			exp.ID,
-			exp.Date.String(),
-			exp.Description,
-			exp.Amount,
-			exp.Category,
-			exp.Month,
-		)
+	switch format {
+	case EXPORT_FORMAT_QIF:
+		err = qif.WriteExpenses(output, selected)
+	case EXPORT_FORMAT_LEDGER, EXPORT_FORMAT_HLEDGER:
+		err = journal.WriteLedger(output, selected, fundingAccount)
+	case EXPORT_FORMAT_BEANCOUNT:
+		err = journal.WriteBeancount(output, selected, fundingAccount)
+	case EXPORT_FORMAT_XLSX:
+		err = writeWorkbook(output, cmd, expenses, selected)
+	default:
+		err = csvio.WriteExpenses(output, selected, options)
+	}
+	if err != nil {
+		output.Close()
+		return err
+	}
+
+	// Closing flushes the file, so an error here means the export is incomplete
+	if err := output.Close(); err != nil {
+		return err
+	}
+
+	if exportFilePath != STDOUT_OUTPUT {
+		fmt.Printf("%d expense(s) have been successfully exported at '%s'\n", len(selected), exportFilePath)
+	}
+
+	return nil
+}
+
+/**
+* Writes the selected expenses as an xlsx workbook with the per-month and
+* per-category pivots of the selection and the budget report that `summary`
+* prints for the same --month, --year and --category.
+*
+* @param expenses All known expenses, as the budget report needs them.
+* @param selected The expenses selected by the filters.
+ */
+func writeWorkbook(w io.Writer, cmd Command, expenses, selected []expense.Expense) error {
+	registry, err := category.GetCategories()
+	if err != nil {
+		return err
 	}
 
-	if _, err := exportFile.WriteString(csvString); err != nil {
+	budgets, err := budget.GetBudgets()
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Data has been successfully exported at '%s'\n", exportFilePath)
+	now := time.Now().UTC()
+	year, month := summaryPeriod(cmd, now)
+
+	return xlsx.WriteExpenses(w, selected, xlsx.Summary{
+		Months:     report.MonthlyTotals(selected),
+		Categories: report.CategoryTotals(selected, registry),
+		Budget:     report.BudgetVsActual(expenses, budgets, year, month, cmd.Category, now),
+		Year:       year,
+		Month:      month,
+	})
+}
+
+/**
+* Exports everything the tracker stores—expenses including deleted ones,
+* budgets, categories, rules, import profiles and settings—as a versioned
+* JSON document or as NDJSON, one record per line.
+ */
+func exportBackup(cmd Command, format, defaultPath string) error {
+	if cmd.Month != -1 || cmd.Year != -1 || cmd.Category != "" || cmd.WithDeleted || cmd.Account != "" ||
+		cmd.Columns != "" || cmd.Delimiter != "" || cmd.DateFormat != "" {
+		return errors.New(format + " exports are full backups, filters and format options do not apply")
+	}
+
+	b, err := backup.Collect()
+	if err != nil {
+		return err
+	}
+
+	output, exportFilePath, err := openExportOutput(cmd.Output, defaultPath)
+	if err != nil {
+		return err
+	}
+
+	if format == EXPORT_FORMAT_NDJSON {
+		err = backup.WriteNDJSON(output, b)
+	} else {
+		err = backup.WriteJSON(output, b)
+	}
+	if err != nil {
+		output.Close()
+		return err
+	}
+
+	if err := output.Close(); err != nil {
+		return err
+	}
+
+	if exportFilePath != STDOUT_OUTPUT {
+		fmt.Printf("Backup of %d expense(s) has been successfully exported at '%s'\n", len(b.Expenses), exportFilePath)
+	}
+
+	return nil
+}
+
+/**
+* Returns the account journal exports are balanced against: --account if
+* given, otherwise the funding account from the settings.
+ */
+func exportFundingAccount(cmd Command, format string) (string, error) {
+	switch format {
+	case EXPORT_FORMAT_LEDGER, EXPORT_FORMAT_HLEDGER, EXPORT_FORMAT_BEANCOUNT:
+	default:
+		if cmd.Account != "" {
+			return "", errors.New("--account only applies to ledger, hledger and beancount")
+		}
+		return "", nil
+	}
+
+	if strings.TrimSpace(cmd.Account) != "" {
+		return strings.TrimSpace(cmd.Account), nil
+	}
+
+	s, err := settings.GetSettings()
+	if err != nil {
+		return "", err
+	}
+
+	return s.FundingAccount, nil
+}
+
+func exportOptions(cmd Command) (csvio.ExportOptions, error) {
+	options := csvio.DefaultExportOptions()
+
+	if cmd.Columns != "" {
+		columns, err := csvio.ParseColumns(cmd.Columns)
+		if err != nil {
+			return csvio.ExportOptions{}, err
+		}
+		options.Columns = columns
+	}
+
+	if cmd.Delimiter != "" {
+		delimiter, err := csvio.ParseDelimiter(cmd.Delimiter)
+		if err != nil {
+			return csvio.ExportOptions{}, err
+		}
+		options.Delimiter = delimiter
+	}
+
+	if cmd.DateFormat != "" {
+		options.DateFormat = csvio.ParseDateFormat(cmd.DateFormat)
+	}
+
+	return options, nil
+}
+
+/**
+* Opens the export destination. An empty output means the default path and
+* "-" means stdout; missing parent directories are created.
+*
+* @return The writer, the path it writes to, or an error if it cannot be opened.
+ */
+func openExportOutput(output, defaultPath string) (io.WriteCloser, string, error) {
+	if output == STDOUT_OUTPUT {
+		return nopWriteCloser{os.Stdout}, STDOUT_OUTPUT, nil
+	}
+
+	exportFilePath := defaultPath
+	if output != "" {
+		exportFilePath = output
+	}
+
+	if err := os.MkdirAll(filepath.Dir(exportFilePath), 0755); err != nil {
+		return nil, "", err
+	}
+
+	exportFile, err := os.OpenFile(exportFilePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return exportFile, exportFilePath, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
 
+func (nopWriteCloser) Close() error {
 	return nil
 }
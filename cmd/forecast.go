@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* Prints the end-of-month spending forecast for the current month.
+*
+* @param cmd The command containing an optional category filter.
+* @return An error if expenses or budgets cannot be read; otherwise, nil.
+ */
+func forecast(cmd Command) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	lines := report.Forecast(expenses, budgets, now.Year(), int(now.Month()), cmd.Category, now)
+
+	if len(lines) < 1 {
+		fmt.Printf("Nothing to forecast for %v %d\n", now.Month().String(), now.Year())
+		return nil
+	}
+
+	printForecast(lines, now)
+
+	return nil
+}
+
+func printForecast(lines []report.ForecastLine, now time.Time) {
+	fmt.Printf("Forecast for the end of %v %d:\n", now.Month().String(), now.Year())
+
+	fmt.Printf(
+		"  %-*s%12s%12s%12s%12s\n",
+		CATEGORY_LIMIT_CHARS+1,
+		"Category",
+		"Spent",
+		"Recurring",
+		"Forecast",
+		"Limit",
+	)
+
+	for _, line := range lines {
+		name := line.Category
+		if name == "" {
+			name = UNCATEGORIZED_LABEL
+		}
+
+		limit := "-"
+		if line.HasBudget {
+			limit = fmt.Sprintf("%.2f", line.Limit)
+		}
+
+		fmt.Printf(
+			"  %-*s%12.2f%12.2f%12.2f%12s",
+			CATEGORY_LIMIT_CHARS+1,
+			truncate(name, CATEGORY_LIMIT_CHARS),
+			line.Spent,
+			line.Recurring,
+			line.Forecast,
+			limit,
+		)
+
+		if line.OverBudget {
+			fmt.Printf("  (over budget by %.2f)", line.Forecast-line.Limit)
+		}
+
+		fmt.Printf("\n")
+	}
+
+	fmt.Println()
+}
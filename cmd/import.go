@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/backup"
+	"github.com/dmitriy-zverev/expense-tracker/internal/camt"
+	"github.com/dmitriy-zverev/expense-tracker/internal/csvio"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+	"github.com/dmitriy-zverev/expense-tracker/internal/journal"
+	"github.com/dmitriy-zverev/expense-tracker/internal/mt940"
+	"github.com/dmitriy-zverev/expense-tracker/internal/ofx"
+	"github.com/dmitriy-zverev/expense-tracker/internal/qif"
+)
+
+func importCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case IMPORT_CSV_CMD:
+		if err := importCSV(cmd); err != nil {
+			return err
+		}
+	case IMPORT_OFX_CMD, IMPORT_QFX_CMD:
+		if err := importStatement(cmd, ofx.ReadTransactions); err != nil {
+			return err
+		}
+	case IMPORT_CAMT_CMD:
+		if err := importStatement(cmd, camt.ReadTransactions); err != nil {
+			return err
+		}
+	case IMPORT_MT940_CMD:
+		if err := importStatement(cmd, mt940.ReadTransactions); err != nil {
+			return err
+		}
+	case IMPORT_LEDGER_CMD:
+		if err := importStatement(cmd, journal.ReadLedger); err != nil {
+			return err
+		}
+	case IMPORT_BEANCOUNT_CMD:
+		if err := importStatement(cmd, journal.ReadBeancount); err != nil {
+			return err
+		}
+	case IMPORT_JSON_CMD:
+		if err := importBackup(cmd); err != nil {
+			return err
+		}
+	case IMPORT_QIF_CMD:
+		if err := importQIF(cmd); err != nil {
+			return err
+		}
+	case IMPORT_PROFILE_CMD:
+		if err := profileCmd(cmd); err != nil {
+			return err
+		}
+	default:
+		return errors.New("format for import is not provided")
+	}
+
+	return nil
+}
+
+/**
+* Imports a CSV statement with a saved mapping profile, or with the layout of
+* our own CSV export when no profile is given. If any row cannot be read,
+* all row errors are reported and nothing is written. Negative amounts are
+* incomes, as in our own export, unless the profile sets --negative-expense;
+* the convention in use is printed, as most banks sign the other way round.
+*
+* @param cmd The command containing --file, --profile and --preview.
+* @return An error if the statement cannot be read or imported; otherwise, nil.
+ */
+func importCSV(cmd Command) error {
+	if cmd.File == "" {
+		return errors.New("file not provided")
+	}
+
+	profile := csvio.DefaultProfile()
+	if cmd.Profile != "" {
+		p, err := csvio.GetProfile(cmd.Profile)
+		if err != nil {
+			return err
+		}
+		profile = p
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	transactions, rowErrors := csvio.ReadTransactions(file, profile)
+
+	if profile.NegativeIsExpense {
+		fmt.Printf("Negative amounts are read as expenses and positive ones as incomes\n")
+	} else {
+		fmt.Printf("Negative amounts are read as incomes and positive ones as expenses; use a profile with --negative-expense for the other way round\n")
+	}
+
+	return importTransactions(transactions, rowErrors, cmd.Preview)
+}
+
+/**
+* Imports a statement in a format that needs no options, such as OFX,
+* camt.053, MT940 or a ledger journal. Transactions whose bank reference was already imported
+* from the same account are skipped.
+*
+* @param cmd The command containing --file and --preview.
+* @param read The reader of the statement format.
+* @return An error if the statement cannot be read or imported; otherwise, nil.
+ */
+func importStatement(cmd Command, read func(io.Reader) ([]importer.Transaction, []importer.RowError)) error {
+	if cmd.File == "" {
+		return errors.New("file not provided")
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	transactions, rowErrors := read(file)
+
+	return importTransactions(transactions, rowErrors, cmd.Preview)
+}
+
+/**
+* Imports a QIF file from older finance software. Split transactions become
+* one expense per split.
+*
+* @param cmd The command containing --file, --date-format and --preview.
+* @return An error if the file cannot be read or imported; otherwise, nil.
+ */
+func importQIF(cmd Command) error {
+	if cmd.File == "" {
+		return errors.New("file not provided")
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dateFormat := ""
+	if cmd.DateFormat != "" {
+		dateFormat = csvio.ParseDateFormat(cmd.DateFormat)
+	}
+
+	transactions, rowErrors := qif.ReadTransactions(file, dateFormat)
+
+	return importTransactions(transactions, rowErrors, cmd.Preview)
+}
+
+/**
+* Runs parsed statement transactions through the import pipeline and prints
+* the outcome. Shared by every statement format.
+ */
+func importTransactions(transactions []importer.Transaction, rowErrors []importer.RowError, preview bool) error {
+	if len(rowErrors) > 0 {
+		for _, rowErr := range rowErrors {
+			fmt.Printf("  %v\n", rowErr)
+		}
+		return fmt.Errorf("import aborted, %d row(s) could not be read and nothing has been written", len(rowErrors))
+	}
+
+	result, err := importer.Prepare(transactions)
+	if err != nil {
+		return err
+	}
+
+	incomes := 0
+	for _, exp := range result.Expenses {
+		if exp.IsIncome {
+			incomes++
+		}
+	}
+
+	if preview {
+		printImportPreview(result)
+		fmt.Printf("\n%d expense(s) and %d income(s) would be imported\n", len(result.Expenses)-incomes, incomes)
+		fmt.Printf("Preview only, nothing has been written\n")
+		return nil
+	}
+
+	if err := importer.Commit(result); err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"Imported %d transaction(s): %d expense(s) and %d income(s), skipped %d row(s)\n",
+		len(result.Expenses),
+		len(result.Expenses)-incomes,
+		incomes,
+		len(result.Skipped),
+	)
+	printDuplicateWarnings(result.Duplicates)
+
+	return nil
+}
+
+func printImportPreview(result importer.Result) {
+	fmt.Printf(
+		"# ID\tDate\t\tDescription%sAmount\tCategory\n",
+		padding("Description"),
+	)
+
+	for _, exp := range result.Expenses {
+		description := truncate(exp.Description, PRINT_MAX_DESCRIPTION_LENGTH)
+
+		fmt.Printf(
+			"# %d\t%s\t%s%s%.2f\t%s",
+			exp.ID,
+			exp.Date.Format(DATE_INPUT_FORMAT),
+			description,
+			padding(description),
+			exp.Amount,
+			exp.Category,
+		)
+
+		if exp.IsIncome {
+			fmt.Printf("\t(income)")
+		}
+		fmt.Printf("\n")
+	}
+
+	for _, skipped := range result.Skipped {
+		fmt.Printf("  skipped line %d '%s': %s\n", skipped.Line, skipped.Description, skipped.Reason)
+	}
+
+	for _, pair := range result.Duplicates {
+		fmt.Printf("  #%d may duplicate #%d '%s' of %s\n", pair.Duplicate.ID, pair.Original.ID, pair.Original.Description, pair.Original.Date.Format(DATE_INPUT_FORMAT))
+	}
+}
+
+func profileCmd(cmd Command) error {
+	switch cmd.Action {
+	case PROFILE_SET_CMD:
+		profile := csvio.DefaultProfile()
+		profile.Name = cmd.Name
+		profile.DateColumn = cmd.ColumnMap[DATE_COLUMN_PARAM]
+		profile.AmountColumn = cmd.ColumnMap[AMOUNT_COLUMN_PARAM]
+		profile.DescriptionColumn = cmd.ColumnMap[DESCRIPTION_COLUMN_PARAM]
+		profile.CategoryColumn = cmd.ColumnMap[CATEGORY_COLUMN_PARAM]
+		profile.NegativeIsExpense = cmd.NegativeIsExpense
+		profile.HasHeader = !cmd.NoHeader
+
+		if cmd.DateFormat != "" {
+			profile.DateFormat = cmd.DateFormat
+		}
+		if cmd.Delimiter != "" {
+			profile.Delimiter = cmd.Delimiter
+		}
+		if cmd.Decimal != "" {
+			profile.DecimalSeparator = cmd.Decimal
+		}
+
+		if err := csvio.SetProfile(profile); err != nil {
+			return err
+		}
+
+		fmt.Printf("Import profile '%s' has been saved\n", profile.Name)
+	case PROFILE_LIST_CMD:
+		profiles, err := csvio.GetProfiles()
+		if err != nil {
+			return err
+		}
+
+		for _, p := range profiles {
+			fmt.Printf(
+				"%s\tdate=%s amount=%s description=%s category=%s format=%s delimiter=%q decimal=%q negative-expense=%t header=%t\n",
+				p.Name,
+				p.DateColumn,
+				p.AmountColumn,
+				p.DescriptionColumn,
+				p.CategoryColumn,
+				p.DateFormat,
+				p.Delimiter,
+				p.DecimalSeparator,
+				p.NegativeIsExpense,
+				p.HasHeader,
+			)
+		}
+	case PROFILE_REMOVE_CMD:
+		if err := csvio.RemoveProfile(cmd.Name); err != nil {
+			return err
+		}
+	default:
+		return errors.New("command for import profile is not provided")
+	}
+
+	return nil
+}
+
+func padding(s string) string {
+	return fmt.Sprintf("%*s", PRINT_MAX_DESCRIPTION_LENGTH-utf8.RuneCountInString(s)+1, "")
+}
+
+/**
+* Restores a json or ndjson backup. Everything the backup holds is replaced
+* at once, so --replace is required when there are expenses already. The API
+* tokens, webhooks and email settings of this machine are kept.
+*
+* @param cmd The command containing --file, --preview and --replace.
+* @return An error if the backup is invalid or cannot be restored; otherwise, nil.
+ */
+func importBackup(cmd Command) error {
+	if cmd.File == "" {
+		return errors.New("file not provided")
+	}
+
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	b, err := backup.Read(file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"Backup of %s, schema version %d: %d expense(s), %d budget(s), %d categories, %d rule(s), %d import profile(s)\n",
+		b.ExportedAt.Format(DATE_INPUT_FORMAT),
+		b.Version,
+		len(b.Expenses),
+		len(b.Budgets),
+		len(b.Categories),
+		len(b.Rules),
+		len(b.Profiles),
+	)
+
+	if cmd.Preview {
+		fmt.Println("Preview only, nothing has been written")
+		return nil
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	if len(expenses) > 0 && !cmd.Replace {
+		return fmt.Errorf("there are %d expense(s) already, use --replace to overwrite all data with the backup", len(expenses))
+	}
+
+	if err := backup.Restore(b); err != nil {
+		return err
+	}
+
+	fmt.Println("Backup has been restored")
+	fmt.Println("API tokens, webhooks and email settings of this machine have been kept")
+
+	return nil
+}
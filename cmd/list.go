@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
 )
 
@@ -25,15 +26,7 @@ func list(cmd Command) error {
 	)
 
 	for _, exp := range expenses {
-		if !cmd.WithDeleted && exp.IsDeleted {
-			continue
-		}
-
-		if cmd.Month != -1 && exp.Month != cmd.Month {
-			continue
-		}
-
-		if cmd.Category != "" && exp.Category != cmd.Category {
+		if !isExpenseSelected(cmd, exp) {
 			continue
 		}
 
@@ -53,10 +46,26 @@ func list(cmd Command) error {
 			exp.Category,
 		)
 
+		if len(exp.Tags) > 0 {
+			fmt.Printf("\t[%s]", strings.Join(exp.Tags, ", "))
+		}
+
+		if exp.IsIncome {
+			fmt.Printf("\t(income)")
+		}
+
 		if exp.IsDeleted {
 			fmt.Printf("\t(deleted)")
 		}
 
+		if exp.CreatedBy != "" {
+			fmt.Printf("\tby %s", exp.CreatedBy)
+		}
+
+		if exp.UpdatedBy != "" {
+			fmt.Printf("\tedited by %s", exp.UpdatedBy)
+		}
+
 		fmt.Printf("\n")
 	}
 
@@ -64,3 +73,27 @@ func list(cmd Command) error {
 
 	return nil
 }
+
+/**
+* Reports whether an expense passes the --with-deleted, --month, --year
+* and --category filters of the command.
+ */
+func isExpenseSelected(cmd Command, exp expense.Expense) bool {
+	if !cmd.WithDeleted && exp.IsDeleted {
+		return false
+	}
+
+	if cmd.Month != -1 && exp.Month != cmd.Month {
+		return false
+	}
+
+	if cmd.Year != -1 && exp.Date.Year() != cmd.Year {
+		return false
+	}
+
+	if cmd.Category != "" && !category.IsWithin(exp.Category, cmd.Category) {
+		return false
+	}
+
+	return true
+}
@@ -1,26 +1,68 @@
 package cmd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"os"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Command struct {
-	Amount      float64
-	Limit       float64
-	ID          int
-	Month       int
-	WithDeleted bool
-	Description string
-	Cmd         string
-	Category    string
-	Output      string
-	BudgetCmd   string
+	Amount            float64
+	Limit             float64
+	MinAmount         float64
+	MaxAmount         float64
+	ID                int
+	OtherID           int
+	Days              int
+	Month             int
+	Year              int
+	WithDeleted       bool
+	DryRun            bool
+	Review            bool
+	Preview           bool
+	NegativeIsExpense bool
+	NoHeader          bool
+	Replace           bool
+	Description       string
+	Cmd               string
+	Category          string
+	Output            string
+	From              string
+	To                string
+	BudgetCmd         string
+	Contains          string
+	Regex             string
+	Weekday           string
+	Date              string
+	Columns           string
+	Delimiter         string
+	DateFormat        string
+	Format            string
+	Account           string
+	Addr              string
+	Scope             string
+	URL               string
+	SMTP              string
+	User              string
+	Tags              []string
+	Events            []string
+	SubCmd            string
+	Action            string
+	File              string
+	Profile           string
+	Name              string
+	Decimal           string
+	ColumnMap         map[string]string
 }
 
+// Standard input, shared so the shell and the questions of commands do not read ahead of each other
+var stdin = bufio.NewReader(os.Stdin)
+
 func (cmd *Command) Run() error {
 	initCommands()
 
@@ -36,6 +78,34 @@ func (cmd *Command) Run() error {
 	return nil
 }
 
+/**
+* Returns the date given with --date, or the current time if it is not set.
+ */
+func commandDate(cmd Command) (time.Time, error) {
+	if cmd.Date == "" {
+		return time.Now().UTC(), nil
+	}
+
+	date, err := time.Parse(DATE_INPUT_FORMAT, cmd.Date)
+	if err != nil {
+		return time.Time{}, errors.New("argument for --date must be in YYYY-MM-DD format")
+	}
+
+	return date, nil
+}
+
+/**
+* Cuts s to at most n runes, so that multi-byte characters are never split.
+ */
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:max(0, n)])
+}
+
 func help() {
 	fmt.Printf("Usage: et <command> [-argument 1] [description 1] ...\n")
 	fmt.Printf("	Example: et add --description \"Lunch\" --amount 20\n")
@@ -50,15 +120,29 @@ func ParseCommand(args []string) (Command, error) {
 	cmd := Command{
 		Cmd:         args[1],
 		ID:          -1,
+		OtherID:     -1,
+		Days:        -1,
 		Month:       -1,
+		Year:        -1,
 		Amount:      -1.0,
 		Limit:       -1.0,
+		MinAmount:   -1.0,
+		MaxAmount:   -1.0,
 		WithDeleted: false,
+		ColumnMap:   map[string]string{},
+	}
+
+	if len(args) > 2 && !strings.HasPrefix(args[2], "--") {
+		cmd.SubCmd = args[2]
+	}
+
+	if len(args) > 3 && cmd.SubCmd != "" && !strings.HasPrefix(args[3], "--") {
+		cmd.Action = args[3]
 	}
 
 	if slices.Contains(args, DESCRIPTION_PARAM) {
 		idx := slices.Index(args, DESCRIPTION_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --description")
 		}
 		cmd.Description = args[idx+1]
@@ -66,7 +150,7 @@ func ParseCommand(args []string) (Command, error) {
 
 	if slices.Contains(args, AMOUNT_PARAM) {
 		idx := slices.Index(args, AMOUNT_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --amount")
 		}
 
@@ -80,7 +164,7 @@ func ParseCommand(args []string) (Command, error) {
 
 	if slices.Contains(args, ID_PARAM) {
 		idx := slices.Index(args, ID_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --id")
 		}
 
@@ -92,9 +176,41 @@ func ParseCommand(args []string) (Command, error) {
 		cmd.ID = id
 	}
 
+	for _, param := range []string{INTO_PARAM, WITH_PARAM} {
+		if !slices.Contains(args, param) {
+			continue
+		}
+
+		idx := slices.Index(args, param)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for " + param)
+		}
+
+		id, err := strconv.Atoi(args[idx+1])
+		if err != nil {
+			return Command{}, errors.New("argument for " + param + " is not a number")
+		}
+
+		cmd.OtherID = id
+	}
+
+	if slices.Contains(args, DAYS_PARAM) {
+		idx := slices.Index(args, DAYS_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --days")
+		}
+
+		days, err := strconv.Atoi(args[idx+1])
+		if err != nil || days < 0 {
+			return Command{}, errors.New("argument for --days is not a positive number")
+		}
+
+		cmd.Days = days
+	}
+
 	if slices.Contains(args, MONTH_PARAM) {
 		idx := slices.Index(args, MONTH_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --month")
 		}
 
@@ -106,13 +222,27 @@ func ParseCommand(args []string) (Command, error) {
 		cmd.Month = month
 	}
 
+	if slices.Contains(args, YEAR_PARAM) {
+		idx := slices.Index(args, YEAR_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --year")
+		}
+
+		year, err := strconv.Atoi(args[idx+1])
+		if err != nil {
+			return Command{}, errors.New("argument for --year is not a number")
+		}
+
+		cmd.Year = year
+	}
+
 	if slices.Contains(args, CATEGORY_PARAM) {
 		idx := slices.Index(args, CATEGORY_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --category")
 		}
 
-		cmd.Category = os.Args[idx+1]
+		cmd.Category = args[idx+1]
 	}
 
 	if slices.Contains(args, WITH_DELETED_PARAM) {
@@ -121,16 +251,292 @@ func ParseCommand(args []string) (Command, error) {
 
 	if slices.Contains(args, OUTPUT_PARAM) {
 		idx := slices.Index(args, OUTPUT_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --output")
 		}
 
-		cmd.Output = os.Args[idx+1]
+		cmd.Output = args[idx+1]
+	}
+
+	if slices.Contains(args, COLUMNS_PARAM) {
+		idx := slices.Index(args, COLUMNS_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --columns")
+		}
+
+		cmd.Columns = args[idx+1]
+	}
+
+	if slices.Contains(args, DELIMITER_PARAM) {
+		idx := slices.Index(args, DELIMITER_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --delimiter")
+		}
+
+		cmd.Delimiter = args[idx+1]
+	}
+
+	if slices.Contains(args, DATE_FORMAT_PARAM) {
+		idx := slices.Index(args, DATE_FORMAT_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --date-format")
+		}
+
+		cmd.DateFormat = args[idx+1]
+	}
+
+	if slices.Contains(args, FORMAT_PARAM) {
+		idx := slices.Index(args, FORMAT_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --format")
+		}
+
+		cmd.Format = strings.ToLower(args[idx+1])
+	}
+
+	if slices.Contains(args, ACCOUNT_PARAM) {
+		idx := slices.Index(args, ACCOUNT_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --account")
+		}
+
+		cmd.Account = args[idx+1]
+	}
+
+	if slices.Contains(args, ADDR_PARAM) {
+		idx := slices.Index(args, ADDR_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --addr")
+		}
+
+		cmd.Addr = args[idx+1]
+	}
+
+	if slices.Contains(args, SCOPE_PARAM) {
+		idx := slices.Index(args, SCOPE_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --scope")
+		}
+
+		cmd.Scope = strings.ToLower(args[idx+1])
+	}
+
+	if slices.Contains(args, FROM_PARAM) {
+		idx := slices.Index(args, FROM_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --from")
+		}
+
+		cmd.From = args[idx+1]
+	}
+
+	if slices.Contains(args, TO_PARAM) {
+		idx := slices.Index(args, TO_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --to")
+		}
+
+		cmd.To = args[idx+1]
+	}
+
+	if slices.Contains(args, DRY_RUN_PARAM) {
+		cmd.DryRun = true
+	}
+
+	if slices.Contains(args, REVIEW_PARAM) {
+		cmd.Review = true
+	}
+
+	if slices.Contains(args, CONTAINS_PARAM) {
+		idx := slices.Index(args, CONTAINS_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --contains")
+		}
+
+		cmd.Contains = args[idx+1]
+	}
+
+	if slices.Contains(args, REGEX_PARAM) {
+		idx := slices.Index(args, REGEX_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --regex")
+		}
+
+		cmd.Regex = args[idx+1]
+	}
+
+	if slices.Contains(args, MIN_AMOUNT_PARAM) {
+		idx := slices.Index(args, MIN_AMOUNT_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --min-amount")
+		}
+
+		minAmount, err := strconv.ParseFloat(args[idx+1], 64)
+		if err != nil {
+			return Command{}, errors.New("argument for --min-amount is not a number")
+		}
+
+		cmd.MinAmount = minAmount
+	}
+
+	if slices.Contains(args, MAX_AMOUNT_PARAM) {
+		idx := slices.Index(args, MAX_AMOUNT_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --max-amount")
+		}
+
+		maxAmount, err := strconv.ParseFloat(args[idx+1], 64)
+		if err != nil {
+			return Command{}, errors.New("argument for --max-amount is not a number")
+		}
+
+		cmd.MaxAmount = maxAmount
+	}
+
+	if slices.Contains(args, WEEKDAY_PARAM) {
+		idx := slices.Index(args, WEEKDAY_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --weekday")
+		}
+
+		cmd.Weekday = args[idx+1]
+	}
+
+	if slices.Contains(args, TAGS_PARAM) {
+		idx := slices.Index(args, TAGS_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --tags")
+		}
+
+		for _, tag := range strings.Split(args[idx+1], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				cmd.Tags = append(cmd.Tags, tag)
+			}
+		}
+	}
+
+	if slices.Contains(args, URL_PARAM) {
+		idx := slices.Index(args, URL_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --url")
+		}
+
+		cmd.URL = args[idx+1]
+	}
+
+	if slices.Contains(args, EVENTS_PARAM) {
+		idx := slices.Index(args, EVENTS_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --events")
+		}
+
+		for _, event := range strings.Split(args[idx+1], ",") {
+			if event = strings.TrimSpace(event); event != "" {
+				cmd.Events = append(cmd.Events, event)
+			}
+		}
+	}
+
+	if slices.Contains(args, SMTP_PARAM) {
+		idx := slices.Index(args, SMTP_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --smtp")
+		}
+
+		cmd.SMTP = args[idx+1]
+	}
+
+	if slices.Contains(args, USER_PARAM) {
+		idx := slices.Index(args, USER_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --user")
+		}
+
+		cmd.User = args[idx+1]
+	}
+
+	if slices.Contains(args, DATE_PARAM) {
+		idx := slices.Index(args, DATE_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --date")
+		}
+
+		cmd.Date = args[idx+1]
+	}
+
+	if slices.Contains(args, FILE_PARAM) {
+		idx := slices.Index(args, FILE_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --file")
+		}
+
+		cmd.File = args[idx+1]
+	}
+
+	if slices.Contains(args, PROFILE_PARAM) {
+		idx := slices.Index(args, PROFILE_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --profile")
+		}
+
+		cmd.Profile = args[idx+1]
+	}
+
+	if slices.Contains(args, NAME_PARAM) {
+		idx := slices.Index(args, NAME_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --name")
+		}
+
+		cmd.Name = args[idx+1]
+	}
+
+	if slices.Contains(args, DECIMAL_PARAM) {
+		idx := slices.Index(args, DECIMAL_PARAM)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for --decimal")
+		}
+
+		cmd.Decimal = args[idx+1]
+	}
+
+	for _, param := range []string{
+		DATE_COLUMN_PARAM,
+		AMOUNT_COLUMN_PARAM,
+		DESCRIPTION_COLUMN_PARAM,
+		CATEGORY_COLUMN_PARAM,
+	} {
+		if !slices.Contains(args, param) {
+			continue
+		}
+
+		idx := slices.Index(args, param)
+		if idx+1 >= len(args) {
+			return Command{}, errors.New("cannot find argument for " + param)
+		}
+
+		cmd.ColumnMap[param] = args[idx+1]
+	}
+
+	if slices.Contains(args, PREVIEW_PARAM) {
+		cmd.Preview = true
+	}
+
+	if slices.Contains(args, NEGATIVE_EXPENSE_PARAM) {
+		cmd.NegativeIsExpense = true
+	}
+
+	if slices.Contains(args, NO_HEADER_PARAM) {
+		cmd.NoHeader = true
+	}
+
+	if slices.Contains(args, REPLACE_PARAM) {
+		cmd.Replace = true
 	}
 
 	if slices.Contains(args, LIMIT_PARAM) {
 		idx := slices.Index(args, LIMIT_PARAM)
-		if idx+1 > len(args) {
+		if idx+1 >= len(args) {
 			return Command{}, errors.New("cannot find argument for --limit")
 		}
 
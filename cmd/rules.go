@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+)
+
+func rulesCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case RULES_ADD_CMD:
+		if err := addRule(cmd); err != nil {
+			return err
+		}
+	case RULES_LIST_CMD:
+		if err := listRules(); err != nil {
+			return err
+		}
+	case RULES_TEST_CMD:
+		if err := testRules(cmd); err != nil {
+			return err
+		}
+	case RULES_REMOVE_CMD:
+		if cmd.ID == -1 {
+			return errors.New("id not provided")
+		}
+		if err := rules.RemoveRule(cmd.ID); err != nil {
+			return err
+		}
+	case RULES_APPLY_CMD:
+		if err := applyRules(cmd); err != nil {
+			return err
+		}
+	default:
+		return errors.New("command for rules is not provided")
+	}
+
+	return nil
+}
+
+func addRule(cmd Command) error {
+	rule := rules.NewRule()
+	rule.Contains = cmd.Contains
+	rule.Regex = cmd.Regex
+	rule.MinAmount = cmd.MinAmount
+	rule.MaxAmount = cmd.MaxAmount
+	rule.Weekday = cmd.Weekday
+	rule.Tags = append(rule.Tags, cmd.Tags...)
+	rule.Category = cmd.Category
+
+	// Checked before the category is registered, so an invalid rule leaves no category behind
+	if err := rules.ValidateRule(rule); err != nil {
+		return err
+	}
+
+	if cmd.Category != "" {
+		canonical, err := category.Register(cmd.Category)
+		if err != nil {
+			return err
+		}
+		rule.Category = canonical
+	}
+
+	rule, err := rules.AddRule(rule)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rule #%d has been added: %s\n", rule.ID, describeRule(rule))
+
+	return nil
+}
+
+func listRules() error {
+	allRules, err := rules.GetRules()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range allRules {
+		fmt.Printf("#%d\t%s\n", rule.ID, describeRule(rule))
+	}
+
+	return nil
+}
+
+/**
+* Shows which rule would categorise an expense with the given details
+* without adding anything. --amount is required once any rule checks the
+* amount, as the rule could not be told to match or not without it.
+ */
+func testRules(cmd Command) error {
+	allRules, err := rules.GetRules()
+	if err != nil {
+		return err
+	}
+
+	hasAmountRule := slices.ContainsFunc(allRules, func(rule rules.Rule) bool {
+		return rule.MinAmount != -1 || rule.MaxAmount != -1
+	})
+	if cmd.Amount == -1 && hasAmountRule {
+		return errors.New("amount not set, use --amount as some rules check it")
+	}
+
+	date, err := commandDate(cmd)
+	if err != nil {
+		return err
+	}
+
+	exp := expense.Expense{
+		Description: cmd.Description,
+		Amount:      cmd.Amount,
+		Date:        date,
+	}
+
+	rule, ok := rules.FindMatch(allRules, exp)
+	if !ok {
+		fmt.Println("No rule matches")
+		return nil
+	}
+
+	fmt.Printf("Rule #%d matches: %s\n", rule.ID, describeRule(rule))
+
+	return nil
+}
+
+func applyRules(cmd Command) error {
+	changed, err := rules.ApplyToUncategorized(cmd.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		fmt.Printf("Dry run, nothing has been changed\n\n")
+	}
+
+	fmt.Printf("Categorised %d expense(s)\n", len(changed))
+	for _, exp := range changed {
+		fmt.Printf("  expense #%d '%s' -> %s", exp.ID, exp.Description, exp.Category)
+		if len(exp.Tags) > 0 {
+			fmt.Printf(" [%s]", strings.Join(exp.Tags, ", "))
+		}
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+func describeRule(rule rules.Rule) string {
+	conditions := []string{}
+
+	if rule.Contains != "" {
+		conditions = append(conditions, fmt.Sprintf("description contains '%s'", rule.Contains))
+	}
+	if rule.Regex != "" {
+		conditions = append(conditions, fmt.Sprintf("description matches /%s/", rule.Regex))
+	}
+	if rule.MinAmount != -1 {
+		conditions = append(conditions, fmt.Sprintf("amount >= %.2f", rule.MinAmount))
+	}
+	if rule.MaxAmount != -1 {
+		conditions = append(conditions, fmt.Sprintf("amount <= %.2f", rule.MaxAmount))
+	}
+	if rule.Weekday != "" {
+		conditions = append(conditions, "on "+rule.Weekday)
+	}
+
+	actions := []string{}
+	if rule.Category != "" {
+		actions = append(actions, "category "+rule.Category)
+	}
+	if len(rule.Tags) > 0 {
+		actions = append(actions, "tags "+strings.Join(rule.Tags, ", "))
+	}
+
+	return "if " + strings.Join(conditions, " and ") + " then " + strings.Join(actions, ", ")
+}
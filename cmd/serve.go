@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+	"github.com/dmitriy-zverev/expense-tracker/internal/server"
+)
+
+/**
+* Serves the JSON REST API, the web dashboard and the Prometheus metrics
+* until interrupted, then waits for running requests to finish. Queued
+* webhook events are delivered, and monthly statements and budget alerts
+* emailed, in the background meanwhile. Until an
+* API token has been created the API is open, so an address other than a
+* loopback one is then served with a warning.
+*
+* @param cmd The command containing an optional --addr.
+* @return An error if the address cannot be listened on; otherwise, nil.
+ */
+func serve(cmd Command) error {
+	addr := cmd.Addr
+	if addr == "" {
+		addr = server.DEFAULT_ADDR
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.New("argument for --addr must be host:port, e.g. " + server.DEFAULT_ADDR)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := auth.GetTokens()
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	if auth.IsEnabled(tokens) && !auth.HasActive(tokens) {
+		fmt.Printf("Warning: every API token is revoked, so the API refuses every request until one is created with 'et token create'\n")
+	} else if auth.IsEnabled(tokens) {
+		fmt.Printf("API tokens are required; manage them with 'et token'\n")
+	} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		fmt.Printf("Warning: no API token has been created, so the API is open to anyone who can reach %s\n", addr)
+	}
+
+	apiServer := server.New()
+	apiServer.SetAddr(addr)
+	httpServer := &http.Server{
+		Handler:           apiServer,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	go apiServer.DeliverWebhooks(ctx)
+	go apiServer.SendEmails(ctx, func(err error) {
+		fmt.Printf("Warning: cannot send email: %v\n", err)
+	})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	fmt.Printf("Serving the dashboard on http://%s/, the API under /api/ and metrics at /metrics (Ctrl+C to stop)\n", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), SERVER_SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nServer stopped\n")
+
+	return nil
+}
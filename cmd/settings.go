@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/settings"
+)
+
+func settingsCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case SETTINGS_LIST_CMD:
+		if err := listSettings(); err != nil {
+			return err
+		}
+	case SETTINGS_SET_CMD:
+		if err := setSettings(cmd); err != nil {
+			return err
+		}
+	default:
+		return errors.New("command for settings is not provided")
+	}
+
+	return nil
+}
+
+func listSettings() error {
+	s, err := settings.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Funding account: %s\n", s.FundingAccount)
+
+	return nil
+}
+
+func setSettings(cmd Command) error {
+	if cmd.Account == "" {
+		return errors.New("nothing to set, provide --account")
+	}
+
+	s, err := settings.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	s.FundingAccount = cmd.Account
+
+	if err := settings.SaveSettings(s); err != nil {
+		return err
+	}
+
+	fmt.Printf("Funding account set to %s\n", s.FundingAccount)
+
+	return nil
+}
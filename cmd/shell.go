@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/shell"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+	"github.com/dmitriy-zverev/expense-tracker/internal/tui"
+)
+
+const (
+	SHELL_PROMPT   = "et> "
+	SHELL_HELP_CMD = "help"
+)
+
+var SHELL_EXIT_CMDS = []string{"exit", "quit"}
+
+// Commands that take over the terminal or run until stopped
+var SHELL_UNAVAILABLE_CMDS = []string{"shell", "serve", "tui"}
+
+/**
+* Runs an interactive shell: commands are typed without the "et" prefix and
+* run one after another against the ledger kept in memory. The changes of
+* each command are written through the storage layer once it has finished.
+* On a terminal, lines can be edited, the history of earlier shells is
+* recalled with the arrow keys, and Tab completes commands and categories.
+* Without a terminal, lines are read from stdin, e.g. a file of commands.
+*
+* @param cmd The shell command; it takes no arguments.
+* @return An error if the ledger cannot be read or the last changes cannot be written; otherwise, nil.
+ */
+func shellCmd(cmd Command) error {
+	storage.BeginSession()
+	defer storage.EndSession()
+
+	if _, err := expense.GetExpenses(); err != nil {
+		return err
+	}
+
+	readLine := readPipedLine
+	var editor *shell.Editor
+	if tui.IsTerminal() {
+		history, err := shell.GetHistory()
+		if err != nil {
+			return err
+		}
+
+		editor = shell.NewEditor(stdin, os.Stdout, history, completeShellWord)
+		readLine = editor.ReadLine
+		fmt.Printf("Type a command without \"et\", help to list them, exit or Ctrl+D to leave\n")
+	}
+
+	for {
+		line, err := readLine(SHELL_PROMPT)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		args, err := shell.Split(line)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		if len(args) < 1 {
+			continue
+		}
+
+		if slices.Contains(SHELL_EXIT_CMDS, args[0]) {
+			break
+		}
+
+		runShellLine(args)
+
+		if editor != nil {
+			if err := shell.SaveHistory(editor.History); err != nil {
+				fmt.Printf("Warning: cannot save the history: %v\n", err)
+			}
+		}
+
+		if err := storage.FlushSession(); err != nil {
+			fmt.Printf("Error: the changes have not been written yet: %v\n", err)
+		}
+	}
+
+	return storage.EndSession()
+}
+
+func runShellLine(args []string) {
+	if args[0] == SHELL_HELP_CMD {
+		printShellHelp()
+		return
+	}
+
+	if slices.Contains(SHELL_UNAVAILABLE_CMDS, args[0]) {
+		fmt.Printf("Error: %s cannot run in the shell, run \"et %s\" instead\n", args[0], args[0])
+		return
+	}
+
+	command, err := ParseCommand(append([]string{"et"}, args...))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if err := command.Run(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+func printShellHelp() {
+	initCommands()
+
+	for _, name := range shellCommandNames() {
+		if command, ok := commands[name]; ok {
+			fmt.Printf("  %-12s %s\n", name, command.Description)
+		}
+	}
+	fmt.Printf("  %-12s %s\n", SHELL_HELP_CMD, "Lists the commands")
+	fmt.Printf("  %-12s %s\n", strings.Join(SHELL_EXIT_CMDS, ", "), "Leaves the shell")
+}
+
+/**
+* The commands that can run in the shell and its own ones, sorted.
+ */
+func shellCommandNames() []string {
+	initCommands()
+
+	names := []string{SHELL_HELP_CMD}
+	names = append(names, SHELL_EXIT_CMDS...)
+	for name := range commands {
+		if !slices.Contains(SHELL_UNAVAILABLE_CMDS, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func completeShellWord(before []rune) shell.Completion {
+	names := []string{}
+	if registry, err := category.GetCategories(); err == nil {
+		for _, c := range registry {
+			names = append(names, c.Name)
+		}
+	}
+
+	return shell.Complete(before, shellCommandNames(), names)
+}
+
+func readPipedLine(prompt string) (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
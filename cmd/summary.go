@@ -2,107 +2,173 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
 )
 
+/**
+* Prints total spending and a budget-vs-actual report.
+* Without --month and --year the total covers all time, while the budget
+* report covers a single (year, month) period, defaulting to the current one.
+* With --year alone, both cover that year: a budget report is printed for
+* each of its months that has budgets.
+*
+* @param cmd The command containing optional month, year and category filters.
+* @return An error if expenses or budgets cannot be read; otherwise, nil.
+ */
 func summary(cmd Command) error {
 	expenses, err := expense.GetExpenses()
 	if err != nil {
 		return err
 	}
 
+	now := time.Now().UTC()
+	year, month := summaryPeriod(cmd, now)
+
+	// The totals cover all time unless a period is given
+	totalsYear := -1
+	if cmd.Month != -1 || cmd.Year != -1 {
+		totalsYear = year
+	}
+	totals := report.Totals(expenses, totalsYear, cmd.Month, cmd.Category)
+
 	fmt.Printf("Total expenses")
+	if cmd.Category != "" {
+		fmt.Printf(" for '%s'", cmd.Category)
+	}
 
-	if cmd.Month != -1 {
-		fmt.Printf(" in %v: ", time.Month(cmd.Month).String())
-	} else {
-		fmt.Printf(": $")
+	switch {
+	case cmd.Month != -1:
+		fmt.Printf(" in %v %d", time.Month(cmd.Month).String(), year)
+	case cmd.Year != -1:
+		fmt.Printf(" in %d", year)
 	}
 
-	totalExpenses := 0.0
-	for _, exp := range expenses {
-		if cmd.Month != -1 && cmd.Month != exp.Month {
-			continue
-		}
+	fmt.Printf(": %.2f $\n", totals.Expenses)
 
-		if cmd.Category != "" && cmd.Category != exp.Category {
-			continue
-		}
+	if totals.Income > 0 {
+		fmt.Printf("Total income: %.2f $\n", totals.Income)
+	}
+
+	fmt.Println()
 
-		totalExpenses += exp.Amount
+	registry, err := category.GetCategories()
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("%.2f $\n\n", totalExpenses)
+	printCategoryTotals(report.CategoryTotals(totals.Spending, registry))
 
-	if err := printBudget(cmd, totalExpenses); err != nil {
+	budgets, err := budget.GetBudgets()
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	if cmd.Year != -1 && cmd.Month == -1 {
+		for yearMonth := 1; yearMonth <= 12; yearMonth++ {
+			printBudgetReport(report.BudgetVsActual(expenses, budgets, year, yearMonth, cmd.Category, now))
+		}
+
+		return nil
+	}
+
+	printBudgetReport(report.BudgetVsActual(expenses, budgets, year, month, cmd.Category, now))
 
-func printBudget(cmd Command, expenses float64) error {
-	if cmd.Month != -1 && cmd.Category != "" {
-		budgetLimit, err := budget.GetBudgetLimit(cmd.Month, cmd.Category)
-		if err != nil {
-			return err
+	if year == now.Year() && month == int(now.Month()) {
+		lines := report.Forecast(expenses, budgets, year, month, cmd.Category, now)
+		if len(lines) > 0 {
+			printForecast(lines, now)
 		}
+	}
 
-		fmt.Printf(
-			"Budget for '%s' in %v: %.2f $\n",
-			cmd.Category,
-			time.Month(cmd.Month).String(),
-			budgetLimit,
-		)
+	return nil
+}
 
-		fmt.Printf("Current budget stat: %.2f $\n", budgetLimit-expenses)
+func summaryPeriod(cmd Command, now time.Time) (int, int) {
+	year := now.Year()
+	if cmd.Year != -1 {
+		year = cmd.Year
 	}
 
-	if cmd.Month == -1 && cmd.Category != "" {
-		budgetLimit, err := budget.GetBudgetLimit(int(time.Now().Month()), cmd.Category)
-		if err != nil {
-			return err
-		}
+	month := int(now.Month())
+	if cmd.Month != -1 {
+		month = cmd.Month
+	}
 
-		fmt.Printf(
-			"Budget for '%s' in %v: %.2f $\n",
-			cmd.Category,
-			time.Month(time.Now().Month()).String(),
-			budgetLimit,
-		)
+	return year, month
+}
 
-		fmt.Printf("Current budget stat: %.2f $\n", budgetLimit-expenses)
+func printCategoryTotals(totals []report.CategoryTotal) {
+	if len(totals) < 2 {
+		return
 	}
 
-	if cmd.Month == -1 && cmd.Category == "" {
-		budgets, err := budget.GetBudgetLimitsForMonth(int(time.Now().Month()))
-		if err != nil {
-			return err
-		}
+	fmt.Printf("By category:\n")
 
-		if len(budgets) < 1 {
-			return nil
+	for _, total := range totals {
+		name := total.Category
+		if name == "" {
+			name = UNCATEGORIZED_LABEL
 		}
 
-		for _, b := range budgets {
-			fmt.Printf(
-				"	Budget for '%s' in %v: %.2f $\n",
-				b.Category,
-				time.Month(time.Now().Month()).String(),
-				b.Limit,
-			)
+		indent := strings.Repeat("  ", total.Depth)
+
+		fmt.Printf(
+			"  %s%-*s%12.2f\n",
+			indent,
+			CATEGORY_LIMIT_CHARS+1-len(indent),
+			truncate(name, CATEGORY_LIMIT_CHARS-len(indent)),
+			total.Total,
+		)
+	}
+
+	fmt.Println()
+}
 
-			categoryExpense, err := expense.GetExpenseForCategory(b.Category)
-			if err != nil {
-				return err
-			}
+func printBudgetReport(lines []report.BudgetLine) {
+	if len(lines) < 1 {
+		return
+	}
 
-			fmt.Printf("	Current budgeting: %.2f $\n\n", b.Limit-categoryExpense)
+	fmt.Printf(
+		"Budget report for %v %d:\n",
+		time.Month(lines[0].Month).String(),
+		lines[0].Year,
+	)
+
+	fmt.Printf(
+		"  %-*s%12s%12s%12s%9s%12s\n",
+		CATEGORY_LIMIT_CHARS+1,
+		"Category",
+		"Limit",
+		"Spent",
+		"Remaining",
+		"Used",
+		"Per day",
+	)
+
+	for _, line := range lines {
+		dailyAllowance := "-"
+		if line.DailyAllowance > 0 {
+			dailyAllowance = fmt.Sprintf("%.2f", line.DailyAllowance)
 		}
+
+		fmt.Printf(
+			"  %-*s%12.2f%12.2f%12.2f%8.1f%%%12s\n",
+			CATEGORY_LIMIT_CHARS+1,
+			truncate(line.Category, CATEGORY_LIMIT_CHARS),
+			line.Limit,
+			line.Spent,
+			line.Remaining,
+			line.PercentUsed,
+			dailyAllowance,
+		)
 	}
 
-	return nil
+	fmt.Printf("\n  Days left in period: %d\n\n", lines[0].DaysLeft)
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+)
+
+func tokenCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case TOKEN_CREATE_CMD:
+		if err := createToken(cmd); err != nil {
+			return err
+		}
+	case TOKEN_LIST_CMD:
+		if err := listTokens(); err != nil {
+			return err
+		}
+	case TOKEN_REVOKE_CMD:
+		if err := revokeToken(cmd); err != nil {
+			return err
+		}
+	default:
+		return errors.New("command for token is not provided")
+	}
+
+	return nil
+}
+
+/**
+* Creates an API token for the user given with --name. Tokens are read-only
+* unless created with --scope write. The secret is printed once; only its
+* hash is stored.
+ */
+func createToken(cmd Command) error {
+	scope := cmd.Scope
+	if scope == "" {
+		scope = auth.SCOPE_READ
+	}
+
+	token, secret, err := auth.CreateToken(cmd.Name, scope)
+	if err != nil {
+		return err
+	}
+
+	if err := audit.Record(audit.Entry{
+		Action:  audit.ACTION_TOKEN_CREATE,
+		Target:  tokenTarget(token.ID),
+		Details: token.User + " " + token.Scope,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Token %d (%s) has been created for '%s'\n", token.ID, token.Scope, token.User)
+	fmt.Printf("\n\t%s\n\n", secret)
+	fmt.Printf("Store it now: it cannot be shown again. Send it as 'Authorization: Bearer <token>'.\n")
+
+	return nil
+}
+
+func listTokens() error {
+	tokens, err := auth.GetTokens()
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) < 1 {
+		fmt.Printf("No tokens: the API is open to anyone who can reach it\n")
+		return nil
+	}
+
+	fmt.Printf("# ID\t%-15s\tScope\tPrefix\t\tCreated\n", "User")
+	for _, token := range tokens {
+		fmt.Printf(
+			"# %d\t%-15s\t%s\t%s\t%s",
+			token.ID,
+			token.User,
+			token.Scope,
+			token.Prefix,
+			token.CreatedAt.Local().Format(DATE_INPUT_FORMAT),
+		)
+
+		if !auth.IsActive(token) {
+			fmt.Printf("\t(revoked %s)", token.RevokedAt.Local().Format(DATE_INPUT_FORMAT))
+		}
+
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+func revokeToken(cmd Command) error {
+	if cmd.ID == -1 {
+		return errors.New("token id not set, use --id")
+	}
+
+	token, err := auth.RevokeToken(cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := audit.Record(audit.Entry{
+		Action:  audit.ACTION_TOKEN_REVOKE,
+		Target:  tokenTarget(token.ID),
+		Details: token.User,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Token %d of '%s' has been revoked\n", token.ID, token.User)
+
+	tokens, err := auth.GetTokens()
+	if err != nil {
+		return err
+	}
+
+	if !auth.HasActive(tokens) {
+		fmt.Printf("Warning: no active token is left, so the API refuses every request until one is created with 'et token create'\n")
+	}
+
+	return nil
+}
+
+func tokenTarget(id int) string {
+	return "token " + strconv.Itoa(id)
+}
@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"github.com/dmitriy-zverev/expense-tracker/internal/tui"
+)
+
+/**
+* Opens the full-screen terminal interface. Changes made in it notify
+* webhooks and email like the other commands; what fails is shown in its
+* status line rather than printed over the screen.
+ */
+func tuiCmd(cmd Command) error {
+	return tui.Run(notify)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
 	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
 )
 
 func update(cmd Command) error {
@@ -24,11 +26,29 @@ func update(cmd Command) error {
 
 	if newCategory == "" && exp.Category != "" {
 		newCategory = exp.Category
+	} else if newCategory != "" {
+		canonical, err := category.Register(newCategory)
+		if err != nil {
+			return err
+		}
+		newCategory = canonical
+	}
+
+	snapshot, err := webhook.TakeSnapshot(exp.Date)
+	if err != nil {
+		return err
 	}
 
 	if err := expense.UpdateExpense(cmd.ID, newAmount, newDesc, newCategory); err != nil {
 		return err
 	}
 
+	updated, err := expense.GetExpense(cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	notifyChange(snapshot, webhook.EVENT_EXPENSE_UPDATED, updated)
+
 	return nil
 }
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+func webhookCmd(cmd Command) error {
+	switch cmd.SubCmd {
+	case WEBHOOK_ADD_CMD:
+		if err := addWebhook(cmd); err != nil {
+			return err
+		}
+	case WEBHOOK_LIST_CMD:
+		if err := listWebhooks(); err != nil {
+			return err
+		}
+	case WEBHOOK_REMOVE_CMD:
+		if cmd.ID == -1 {
+			return errors.New("webhook id not set, use --id")
+		}
+		if err := webhook.RemoveHook(cmd.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Webhook %d has been removed\n", cmd.ID)
+	case WEBHOOK_QUEUE_CMD:
+		if err := listWebhookQueue(); err != nil {
+			return err
+		}
+	case WEBHOOK_DELIVER_CMD:
+		result, err := deliverWebhooks(true)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d delivered, %d to be retried, %d failed\n", result.Delivered, result.Retrying, result.Failed)
+	default:
+		return errors.New("command for webhook is not provided")
+	}
+
+	return nil
+}
+
+func addWebhook(cmd Command) error {
+	hook, err := webhook.AddHook(cmd.URL, cmd.Events)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Webhook %d has been added for %s\n", hook.ID, webhookEvents(hook))
+	fmt.Printf("Deliveries are signed with this secret in the %s header:\n", webhook.SIGNATURE_HEADER)
+	fmt.Printf("\n\t%s\n\n", hook.Secret)
+
+	return nil
+}
+
+func listWebhooks() error {
+	hooks, err := webhook.GetHooks()
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) < 1 {
+		fmt.Printf("No webhooks\n")
+		return nil
+	}
+
+	for _, hook := range hooks {
+		fmt.Printf("# %d\t%s\t%s\tsecret %s\n", hook.ID, hook.URL, webhookEvents(hook), hook.Secret)
+	}
+
+	return nil
+}
+
+func listWebhookQueue() error {
+	queue, err := webhook.GetQueue()
+	if err != nil {
+		return err
+	}
+
+	if len(queue) < 1 {
+		fmt.Printf("No deliveries waiting\n")
+		return nil
+	}
+
+	for _, delivery := range queue {
+		state := "next attempt " + delivery.NextAttemptAt.Local().Format("2006-01-02 15:04:05")
+		if delivery.Failed {
+			state = "failed, retry with 'webhook deliver'"
+		}
+
+		fmt.Printf(
+			"webhook %d\t%-16s\t%d attempt(s)\t%s",
+			delivery.HookID,
+			delivery.Event,
+			delivery.Attempts,
+			state,
+		)
+
+		if delivery.LastError != "" {
+			fmt.Printf("\t(%s)", delivery.LastError)
+		}
+
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+/**
+* Queues a webhook event, and an event for every budget the change pushed
+* over a threshold, then delivers what is due and emails an alert for
+* those budgets. An empty event only checks the budgets. Failures are
+* printed as warnings but not returned: the change has been made, and
+* failed deliveries are retried later.
+ */
+func notifyChange(snapshot webhook.Snapshot, event string, data any) {
+	for _, err := range notify(snapshot, event, data) {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+/**
+* Does what notifyChange does and returns the failures instead of
+* printing them.
+ */
+func notify(snapshot webhook.Snapshot, event string, data any) []error {
+	var crossings []report.Crossing
+	var err error
+	if event == "" {
+		crossings, err = snapshot.NotifyCrossings()
+	} else {
+		crossings, err = snapshot.Notify(event, data)
+	}
+
+	if err != nil {
+		return []error{fmt.Errorf("cannot queue webhook event: %w", err)}
+	}
+
+	errs := []error{}
+	if err := sendBudgetAlerts(crossings); err != nil {
+		errs = append(errs, fmt.Errorf("cannot email budget alert: %w", err))
+	}
+
+	result, err := deliverWebhooks(false)
+	if err != nil {
+		return append(errs, fmt.Errorf("cannot deliver webhooks: %w", err))
+	}
+
+	return append(errs, result.Errors...)
+}
+
+/**
+* Delivers the queued webhook events that are due, or all of them.
+ */
+func deliverWebhooks(all bool) (webhook.Result, error) {
+	client := &http.Client{Timeout: webhook.DELIVERY_TIMEOUT}
+
+	return webhook.Deliver(context.Background(), client, time.Now(), all)
+}
+
+func webhookEvents(hook webhook.Hook) string {
+	if len(hook.Events) < 1 {
+		return "all events"
+	}
+
+	return strings.Join(hook.Events, ", ")
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* One change to the stored data. User is the name of the API token the
+* change was made with, and empty for changes made locally.
+ */
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user,omitempty"`
+	Action  string    `json:"action"`
+	Target  string    `json:"target"`
+	Details string    `json:"details,omitempty"`
+}
+
+const (
+	DEFAULT_AUDIT_FILE_PATH = "./data/audit.log"
+)
+
+const (
+	ACTION_EXPENSE_CREATE  = "expense.create"
+	ACTION_EXPENSE_UPDATE  = "expense.update"
+	ACTION_EXPENSE_DELETE  = "expense.delete"
+	ACTION_BUDGET_SET      = "budget.set"
+	ACTION_BUDGET_REMOVE   = "budget.remove"
+	ACTION_CATEGORY_ADD    = "category.add"
+	ACTION_CATEGORY_REMOVE = "category.remove"
+	ACTION_TOKEN_CREATE    = "token.create"
+	ACTION_TOKEN_REVOKE    = "token.revoke"
+)
+
+/**
+* Appends an entry to the audit log, one JSON object per line, so entries
+* are never rewritten. The time is set if the entry has none.
+ */
+func Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return storage.AppendFileData(DEFAULT_AUDIT_FILE_PATH, append(data, '\n'))
+}
+
+/**
+* Reads the audit log, oldest entry first.
+ */
+func GetEntries() ([]Entry, error) {
+	data, err := storage.GetFileData(DEFAULT_AUDIT_FILE_PATH)
+	if err != nil {
+		return []Entry{}, err
+	}
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) < 1 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return []Entry{}, errors.New("audit log line " + strconv.Itoa(n) + ": " + err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return []Entry{}, err
+	}
+
+	return entries, nil
+}
+
+/**
+* Keeps the entries of a user made at or after since. An empty user and a
+* zero since match everything.
+ */
+func Filter(entries []Entry, user string, since time.Time) []Entry {
+	result := []Entry{}
+
+	for _, entry := range entries {
+		if user != "" && entry.User != user {
+			continue
+		}
+
+		if !since.IsZero() && entry.Time.Before(since) {
+			continue
+		}
+
+		result = append(result, entry)
+	}
+
+	return result
+}
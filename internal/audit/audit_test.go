@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRecordAndGetEntries(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	entries, err := GetEntries()
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("GetEntries() of a new log = %d entries, want 0", len(entries))
+	}
+
+	recorded := []Entry{
+		{User: "alice", Action: ACTION_EXPENSE_CREATE, Target: "expense 0", Details: "12.50 Lunch"},
+		{Action: ACTION_TOKEN_CREATE, Target: "token 0"},
+	}
+	for _, entry := range recorded {
+		if err := Record(entry); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	entries, err = GetEntries()
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+
+	if len(entries) != len(recorded) {
+		t.Fatalf("GetEntries() = %d entries, want %d", len(entries), len(recorded))
+	}
+
+	for i, entry := range entries {
+		if entry.Time.IsZero() {
+			t.Errorf("entry %d has no time", i)
+		}
+
+		entry.Time = time.Time{}
+		if entry != recorded[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, recorded[i])
+		}
+	}
+}
+
+func TestGetEntriesRejectsCorruptLog(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if err := os.WriteFile(DEFAULT_AUDIT_FILE_PATH, []byte("{\"action\":\"budget.set\"}\nnot json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	if _, err := GetEntries(); err == nil {
+		t.Errorf("GetEntries() error = nil, want error")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2025, 9, 21, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: now.AddDate(0, 0, -10), User: "alice", Action: ACTION_EXPENSE_CREATE},
+		{Time: now.AddDate(0, 0, -1), User: "bob", Action: ACTION_EXPENSE_UPDATE},
+		{Time: now, User: "alice", Action: ACTION_EXPENSE_DELETE},
+		{Time: now, Action: ACTION_TOKEN_REVOKE},
+	}
+
+	tests := []struct {
+		name  string
+		user  string
+		since time.Time
+		want  int
+	}{
+		{"Everything", "", time.Time{}, 4},
+		{"One user", "alice", time.Time{}, 2},
+		{"Recent", "", now.AddDate(0, 0, -2), 3},
+		{"Recent of one user", "alice", now.AddDate(0, 0, -2), 1},
+		{"Unknown user", "carol", time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Filter(entries, tt.user, tt.since); len(got) != tt.want {
+				t.Errorf("Filter() = %d entries, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* An API token. Only the SHA-256 hash of the secret is stored; the secret
+* itself is shown once, when the token is created.
+ */
+type Token struct {
+	ID        int       `json:"id"`
+	User      string    `json:"user"`
+	Scope     string    `json:"scope"`
+	Prefix    string    `json:"prefix"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+}
+
+const (
+	DEFAULT_TOKENS_FILE_PATH = "./data/tokens.json"
+	SCOPE_READ               = "read"
+	SCOPE_WRITE              = "write"
+	SECRET_PREFIX            = "et_"
+	SECRET_BYTES             = 24
+	// The characters of a secret kept in the clear, so tokens can be told apart
+	VISIBLE_PREFIX_LENGTH = 8
+)
+
+func GetTokens() ([]Token, error) {
+	data, err := storage.GetFileData(DEFAULT_TOKENS_FILE_PATH)
+	if err != nil {
+		return []Token{}, err
+	}
+
+	if len(data) < 1 {
+		return []Token{}, nil
+	}
+
+	tokens := []Token{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return []Token{}, err
+	}
+
+	return tokens, nil
+}
+
+func SaveTokens(tokens []Token) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_TOKENS_FILE_PATH, data)
+}
+
+/**
+* Creates a token for a user.
+*
+* @param user The name changes made with the token are attributed to.
+* @param scope SCOPE_READ for read-only access or SCOPE_WRITE for read-write access.
+* @return The stored token and its secret, which cannot be recovered later.
+ */
+func CreateToken(user, scope string) (Token, string, error) {
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return Token{}, "", errors.New("token user not set")
+	}
+
+	if scope != SCOPE_READ && scope != SCOPE_WRITE {
+		return Token{}, "", errors.New("token scope must be " + SCOPE_READ + " or " + SCOPE_WRITE)
+	}
+
+	tokens, err := GetTokens()
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	random := make([]byte, SECRET_BYTES)
+	if _, err := rand.Read(random); err != nil {
+		return Token{}, "", err
+	}
+	secret := SECRET_PREFIX + hex.EncodeToString(random)
+
+	token := Token{
+		ID:        len(tokens),
+		User:      user,
+		Scope:     scope,
+		Prefix:    secret[:len(SECRET_PREFIX)+VISIBLE_PREFIX_LENGTH],
+		Hash:      hash(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := SaveTokens(append(tokens, token)); err != nil {
+		return Token{}, "", err
+	}
+
+	return token, secret, nil
+}
+
+/**
+* Revokes a token. It is kept, marked as revoked, so its ID is not reused.
+ */
+func RevokeToken(id int) (Token, error) {
+	tokens, err := GetTokens()
+	if err != nil {
+		return Token{}, err
+	}
+
+	if id < 0 || id >= len(tokens) {
+		return Token{}, errors.New("cannot find token with provided id")
+	}
+
+	if !tokens[id].RevokedAt.IsZero() {
+		return Token{}, errors.New("token with provided id is already revoked")
+	}
+
+	tokens[id].RevokedAt = time.Now().UTC()
+
+	if err := SaveTokens(tokens); err != nil {
+		return Token{}, err
+	}
+
+	return tokens[id], nil
+}
+
+func IsActive(token Token) bool {
+	return token.RevokedAt.IsZero()
+}
+
+/**
+* Reports whether the API requires a token. It does once any token has been
+* created, even after every one is revoked, so revoking the last token locks
+* the API rather than opening it. Only a store that never had a token is open.
+ */
+func IsEnabled(tokens []Token) bool {
+	return len(tokens) > 0
+}
+
+/**
+* Reports whether any token can still be used.
+ */
+func HasActive(tokens []Token) bool {
+	return slices.ContainsFunc(tokens, IsActive)
+}
+
+/**
+* Finds the active token a secret belongs to.
+ */
+func Authenticate(tokens []Token, secret string) (Token, bool) {
+	secretHash := hash(secret)
+
+	for _, token := range tokens {
+		if !IsActive(token) {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(secretHash)) == 1 {
+			return token, true
+		}
+	}
+
+	return Token{}, false
+}
+
+/**
+* Reports whether a token may make a request with the given HTTP method:
+* read-only tokens may only read.
+ */
+func Allows(token Token, method string) bool {
+	if token.Scope == SCOPE_WRITE {
+		return true
+	}
+
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCreateToken(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	token, secret, err := CreateToken(" alice ", SCOPE_WRITE)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	if token.ID != 0 || token.User != "alice" || token.Scope != SCOPE_WRITE {
+		t.Errorf("CreateToken() = %+v, want ID 0, user alice, scope write", token)
+	}
+
+	if !strings.HasPrefix(secret, SECRET_PREFIX) || !strings.HasPrefix(secret, token.Prefix) {
+		t.Errorf("CreateToken() secret = %q, want it to start with %q", secret, token.Prefix)
+	}
+
+	data, err := os.ReadFile(DEFAULT_TOKENS_FILE_PATH)
+	if err != nil {
+		t.Fatalf("Failed to read tokens file: %v", err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Errorf("tokens file contains the secret in the clear")
+	}
+
+	_, other, err := CreateToken("bob", SCOPE_READ)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	if other == secret {
+		t.Errorf("CreateToken() returned the same secret twice")
+	}
+
+	tests := []struct {
+		name  string
+		user  string
+		scope string
+	}{
+		{"No user", "  ", SCOPE_READ},
+		{"Unknown scope", "carol", "admin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := CreateToken(tt.user, tt.scope); err == nil {
+				t.Errorf("CreateToken(%q, %q) error = nil, want error", tt.user, tt.scope)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	alice, aliceSecret, err := CreateToken("alice", SCOPE_WRITE)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+	_, bobSecret, err := CreateToken("bob", SCOPE_READ)
+	if err != nil {
+		t.Fatalf("CreateToken() error = %v", err)
+	}
+
+	tokens, err := GetTokens()
+	if err != nil {
+		t.Fatalf("GetTokens() error = %v", err)
+	}
+
+	if !IsEnabled(tokens) {
+		t.Errorf("IsEnabled() = false, want true")
+	}
+
+	if token, ok := Authenticate(tokens, aliceSecret); !ok || token.User != "alice" {
+		t.Errorf("Authenticate(alice) = %+v, %v, want alice", token, ok)
+	}
+
+	if _, ok := Authenticate(tokens, aliceSecret+"x"); ok {
+		t.Errorf("Authenticate() accepted a wrong secret")
+	}
+
+	if _, err := RevokeToken(alice.ID); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	if _, err := RevokeToken(alice.ID); err == nil {
+		t.Errorf("RevokeToken() of a revoked token error = nil, want error")
+	}
+
+	if _, err := RevokeToken(5); err == nil {
+		t.Errorf("RevokeToken() of an unknown token error = nil, want error")
+	}
+
+	tokens, err = GetTokens()
+	if err != nil {
+		t.Fatalf("GetTokens() error = %v", err)
+	}
+
+	if _, ok := Authenticate(tokens, aliceSecret); ok {
+		t.Errorf("Authenticate() accepted a revoked token")
+	}
+
+	if _, ok := Authenticate(tokens, bobSecret); !ok {
+		t.Errorf("Authenticate(bob) = false, want true")
+	}
+
+	if _, err := RevokeToken(1); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	tokens, err = GetTokens()
+	if err != nil {
+		t.Fatalf("GetTokens() error = %v", err)
+	}
+
+	if !IsEnabled(tokens) {
+		t.Errorf("IsEnabled() with every token revoked = false, want true")
+	}
+
+	if HasActive(tokens) {
+		t.Errorf("HasActive() with every token revoked = true, want false")
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		scope  string
+		method string
+		want   bool
+	}{
+		{SCOPE_READ, http.MethodGet, true},
+		{SCOPE_READ, http.MethodHead, true},
+		{SCOPE_READ, http.MethodPost, false},
+		{SCOPE_READ, http.MethodDelete, false},
+		{SCOPE_WRITE, http.MethodGet, true},
+		{SCOPE_WRITE, http.MethodPatch, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope+" "+tt.method, func(t *testing.T) {
+			if got := Allows(Token{Scope: tt.scope}, tt.method); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
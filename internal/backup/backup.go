@@ -0,0 +1,414 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/csvio"
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+	"github.com/dmitriy-zverev/expense-tracker/internal/settings"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* Everything the tracker stores, as one versioned document. Fields are only
+* ever added to a schema version; renaming or removing one needs a new version.
+*
+* The files that belong to the machine rather than to the ledger are left
+* out, and Restore keeps them as they are: API tokens and the audit log,
+* webhooks and their delivery queue, the email settings, which hold the SMTP
+* password, and the shell history.
+ */
+type Backup struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Expenses   []expense.Expense      `json:"expenses"`
+	Budgets    []budget.Budget        `json:"budgets"`
+	Categories []category.Category    `json:"categories"`
+	Rules      []rules.Rule           `json:"rules"`
+	Profiles   []csvio.Profile        `json:"import_profiles"`
+	Settings   settings.Settings      `json:"settings"`
+	Dismissed  []duplicates.Dismissal `json:"dismissed_duplicates"`
+}
+
+const (
+	SCHEMA_VERSION = 1
+)
+
+// Record types of an NDJSON backup: a header line, then one line per item
+const (
+	RECORD_HEADER    = "header"
+	RECORD_EXPENSE   = "expense"
+	RECORD_BUDGET    = "budget"
+	RECORD_CATEGORY  = "category"
+	RECORD_RULE      = "rule"
+	RECORD_PROFILE   = "import_profile"
+	RECORD_SETTINGS  = "settings"
+	RECORD_DISMISSED = "dismissed_duplicate"
+)
+
+/**
+* One line of an NDJSON backup, e.g. {"type":"expense","data":{...}}.
+ */
+type record struct {
+	Type       string          `json:"type"`
+	Version    int             `json:"version,omitempty"`
+	ExportedAt time.Time       `json:"exported_at,omitzero"`
+	Data       json.RawMessage `json:"data,omitempty"`
+}
+
+/**
+* Reads everything the tracker stores into a backup of the current schema version.
+ */
+func Collect() (Backup, error) {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	categories, err := category.GetCategories()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	storedRules, err := rules.GetRules()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	profiles, err := csvio.GetProfiles()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	s, err := settings.GetSettings()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	dismissed, err := duplicates.GetDismissals()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	b := Backup{
+		Version:    SCHEMA_VERSION,
+		ExportedAt: time.Now().UTC(),
+		Expenses:   expenses,
+		Budgets:    budgets,
+		Categories: categories,
+		Rules:      storedRules,
+		Profiles:   profiles,
+		Settings:   s,
+		Dismissed:  dismissed,
+	}
+	fillEmpty(&b)
+
+	return b, nil
+}
+
+/**
+* Writes the backup as a single indented JSON document.
+ */
+func WriteJSON(w io.Writer, b Backup) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(b)
+}
+
+/**
+* Writes the backup as newline-delimited JSON: a header with the schema
+* version, then one {"type": ..., "data": ...} line per expense, budget,
+* category, rule, import profile and dismissed duplicate, and one for the
+* settings. Every line can be processed on its own, e.g. with
+* `jq 'select(.type == "expense") | .data'`.
+ */
+func WriteNDJSON(w io.Writer, b Backup) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+
+	if err := encoder.Encode(record{Type: RECORD_HEADER, Version: b.Version, ExportedAt: b.ExportedAt}); err != nil {
+		return err
+	}
+
+	write := func(recordType string, value any) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(record{Type: recordType, Data: data})
+	}
+
+	for _, exp := range b.Expenses {
+		if err := write(RECORD_EXPENSE, exp); err != nil {
+			return err
+		}
+	}
+	for _, bud := range b.Budgets {
+		if err := write(RECORD_BUDGET, bud); err != nil {
+			return err
+		}
+	}
+	for _, cat := range b.Categories {
+		if err := write(RECORD_CATEGORY, cat); err != nil {
+			return err
+		}
+	}
+	for _, rule := range b.Rules {
+		if err := write(RECORD_RULE, rule); err != nil {
+			return err
+		}
+	}
+	for _, profile := range b.Profiles {
+		if err := write(RECORD_PROFILE, profile); err != nil {
+			return err
+		}
+	}
+	for _, dismissal := range b.Dismissed {
+		if err := write(RECORD_DISMISSED, dismissal); err != nil {
+			return err
+		}
+	}
+	if err := write(RECORD_SETTINGS, b.Settings); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+/**
+* Reads a backup written by WriteJSON or WriteNDJSON; the format is told
+* apart by the first JSON value. The backup is validated before it is returned.
+*
+* @return The backup, or an error naming the record that is invalid or of an unsupported schema version.
+ */
+func Read(r io.Reader) (Backup, error) {
+	decoder := json.NewDecoder(r)
+
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		if err == io.EOF {
+			return Backup{}, errors.New("backup is empty")
+		}
+		return Backup{}, errors.New("invalid backup: " + err.Error())
+	}
+
+	var header record
+	if err := json.Unmarshal(first, &header); err != nil {
+		return Backup{}, errors.New("not an expense tracker backup")
+	}
+
+	var b Backup
+	var err error
+	if header.Type == RECORD_HEADER {
+		b, err = readNDJSON(decoder, header)
+	} else {
+		b, err = readJSON(decoder, first)
+	}
+	if err != nil {
+		return Backup{}, err
+	}
+
+	fillEmpty(&b)
+	if err := Validate(b); err != nil {
+		return Backup{}, err
+	}
+
+	return b, nil
+}
+
+func readJSON(decoder *json.Decoder, document json.RawMessage) (Backup, error) {
+	var b Backup
+	if err := json.Unmarshal(document, &b); err != nil {
+		return Backup{}, errors.New("invalid backup: " + err.Error())
+	}
+
+	if err := checkVersion(b.Version); err != nil {
+		return Backup{}, err
+	}
+
+	if decoder.More() {
+		return Backup{}, errors.New("invalid backup: unexpected data after the document")
+	}
+
+	return b, nil
+}
+
+func readNDJSON(decoder *json.Decoder, header record) (Backup, error) {
+	if err := checkVersion(header.Version); err != nil {
+		return Backup{}, err
+	}
+
+	b := Backup{Version: header.Version, ExportedAt: header.ExportedAt}
+	hasSettings := false
+
+	for n := 2; ; n++ {
+		var rec record
+		if err := decoder.Decode(&rec); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return Backup{}, errors.New("record " + strconv.Itoa(n) + ": " + err.Error())
+		}
+
+		var err error
+		switch rec.Type {
+		case RECORD_EXPENSE:
+			b.Expenses, err = appendRecord(b.Expenses, rec.Data)
+		case RECORD_BUDGET:
+			b.Budgets, err = appendRecord(b.Budgets, rec.Data)
+		case RECORD_CATEGORY:
+			b.Categories, err = appendRecord(b.Categories, rec.Data)
+		case RECORD_RULE:
+			b.Rules, err = appendRecord(b.Rules, rec.Data)
+		case RECORD_PROFILE:
+			b.Profiles, err = appendRecord(b.Profiles, rec.Data)
+		case RECORD_DISMISSED:
+			b.Dismissed, err = appendRecord(b.Dismissed, rec.Data)
+		case RECORD_SETTINGS:
+			if hasSettings {
+				err = errors.New("settings appear more than once")
+			} else {
+				err = json.Unmarshal(rec.Data, &b.Settings)
+			}
+			hasSettings = true
+		case RECORD_HEADER:
+			err = errors.New("unexpected second header")
+		default:
+			err = errors.New("unknown record type '" + rec.Type + "'")
+		}
+		if err != nil {
+			return Backup{}, errors.New("record " + strconv.Itoa(n) + ": " + err.Error())
+		}
+	}
+
+	return b, nil
+}
+
+func appendRecord[T any](items []T, data json.RawMessage) ([]T, error) {
+	var item T
+	if len(data) < 1 {
+		return items, errors.New("record has no data")
+	}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return items, err
+	}
+
+	return append(items, item), nil
+}
+
+func checkVersion(version int) error {
+	if version < 1 {
+		return errors.New("not an expense tracker backup: schema version missing")
+	}
+
+	if version > SCHEMA_VERSION {
+		return fmt.Errorf("backup schema version %d is newer than the supported version %d", version, SCHEMA_VERSION)
+	}
+
+	return nil
+}
+
+/**
+* Checks that a backup can be restored as it is. Expense IDs must match their
+* position, as expenses are looked up by ID.
+ */
+func Validate(b Backup) error {
+	for i, exp := range b.Expenses {
+		if exp.ID != i {
+			return fmt.Errorf("expense %d: id must be %d, the position of the expense in the backup", exp.ID, i)
+		}
+	}
+
+	for _, rule := range b.Rules {
+		if err := rules.ValidateRule(rule); err != nil {
+			return fmt.Errorf("rule %d: %v", rule.ID, err)
+		}
+	}
+
+	for _, profile := range b.Profiles {
+		if err := csvio.ValidateProfile(profile); err != nil {
+			return fmt.Errorf("import profile '%s': %v", profile.Name, err)
+		}
+	}
+
+	if err := settings.ValidateSettings(b.Settings); err != nil {
+		return errors.New("settings: " + err.Error())
+	}
+
+	return nil
+}
+
+/**
+* Replaces everything a backup holds with the backup; the files it leaves out
+* are kept. Either every file is replaced or, if one cannot be written, none is.
+ */
+func Restore(b Backup) error {
+	if err := Validate(b); err != nil {
+		return err
+	}
+
+	files := map[string]any{
+		expense.EXPENSES_FILE_PATH:              b.Expenses,
+		budget.DEFAULT_BUDGET_FILE_PATH:         b.Budgets,
+		category.DEFAULT_CATEGORY_FILE_PATH:     b.Categories,
+		rules.DEFAULT_RULES_FILE_PATH:           b.Rules,
+		csvio.DEFAULT_PROFILES_FILE_PATH:        b.Profiles,
+		settings.DEFAULT_SETTINGS_FILE_PATH:     b.Settings,
+		duplicates.DEFAULT_DUPLICATES_FILE_PATH: b.Dismissed,
+	}
+
+	data := map[string][]byte{}
+	for fileName, value := range files {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		data[fileName] = encoded
+	}
+
+	return storage.WriteFilesData(data)
+}
+
+/**
+* Replaces missing lists with empty ones and unset settings with their
+* defaults, so that restored files hold "[]" rather than "null".
+ */
+func fillEmpty(b *Backup) {
+	if b.Expenses == nil {
+		b.Expenses = []expense.Expense{}
+	}
+	if b.Budgets == nil {
+		b.Budgets = []budget.Budget{}
+	}
+	if b.Categories == nil {
+		b.Categories = []category.Category{}
+	}
+	if b.Rules == nil {
+		b.Rules = []rules.Rule{}
+	}
+	if b.Profiles == nil {
+		b.Profiles = []csvio.Profile{}
+	}
+	if b.Dismissed == nil {
+		b.Dismissed = []duplicates.Dismissal{}
+	}
+	if b.Settings.FundingAccount == "" {
+		b.Settings.FundingAccount = settings.DEFAULT_FUNDING_ACCOUNT
+	}
+}
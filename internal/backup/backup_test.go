@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/csvio"
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+	"github.com/dmitriy-zverev/expense-tracker/internal/settings"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+func testBackup() Backup {
+	rule := rules.NewRule()
+	rule.ID = 3
+	rule.Contains = "uber"
+	rule.Category = "Transport"
+
+	return Backup{
+		Version:    SCHEMA_VERSION,
+		ExportedAt: time.Date(2025, 9, 30, 12, 0, 0, 0, time.UTC),
+		Expenses: []expense.Expense{
+			{
+				ID:           0,
+				Amount:       12.5,
+				Date:         time.Date(2025, 9, 12, 8, 30, 15, 123456789, time.UTC),
+				ValueDate:    time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC),
+				Month:        9,
+				Description:  "Uber, \"airport\"",
+				Category:     "Transport",
+				Tags:         []string{"work"},
+				Notes:        "line one\nline two",
+				ExternalID:   "ofx:1:A",
+				Counterparty: "Uber BV",
+			},
+			{ID: 1, Amount: 3, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Month: 9, Description: "Gum", IsDeleted: true},
+			{ID: 2, Amount: 2000, Date: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC), Month: 9, Description: "Salary", IsIncome: true},
+		},
+		Budgets:    []budget.Budget{{Month: 9, Year: 2025, Category: "Transport", Limit: 100}},
+		Categories: []category.Category{{Name: "Transport"}},
+		Rules:      []rules.Rule{rule},
+		Profiles:   []csvio.Profile{csvio.DefaultProfile()},
+		Settings:   settings.Settings{FundingAccount: "Liabilities:Visa"},
+		Dismissed:  []duplicates.Dismissal{{First: 0, Second: 2}},
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testBackup()); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"is_deleted": true`) {
+		t.Errorf("WriteJSON() should keep deleted expenses:\n%s", buf.String())
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, testBackup()) {
+		t.Errorf("Read() = %+v, want %+v", got, testBackup())
+	}
+}
+
+func TestNDJSONRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, testBackup()); err != nil {
+		t.Fatalf("WriteNDJSON() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 10 {
+		t.Fatalf("WriteNDJSON() wrote %d lines, want 10:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], `{"type":"header","version":1,`) || !strings.HasPrefix(lines[1], `{"type":"expense","data":{`) {
+		t.Errorf("WriteNDJSON() = %s", buf.String())
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, testBackup()) {
+		t.Errorf("Read() = %+v, want %+v", got, testBackup())
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Empty", "", "backup is empty"},
+		{"Not an object", "[1, 2]", "not an expense tracker backup"},
+		{"No version", `{"expenses": []}`, "schema version missing"},
+		{"Newer version", `{"version": 99}`, "newer than the supported version"},
+		{"Trailing data", `{"version": 1} {"version": 1}`, "unexpected data"},
+		{"Expense id out of place", `{"version": 1, "expenses": [{"id": 4}]}`, "expense 4"},
+		{"Invalid rule", `{"version": 1, "rules": [{"id": 2, "min_amount": -1, "max_amount": -1}]}`, "rule 2"},
+		{"Unknown record", "{\"type\":\"header\",\"version\":1}\n{\"type\":\"receipt\",\"data\":{}}\n", "record 2: unknown record type"},
+		{"Broken record", "{\"type\":\"header\",\"version\":1}\n{\"type\":\"expense\",\"data\":{\"amount\":\"x\"}}\n", "record 2"},
+		{"Record without data", "{\"type\":\"header\",\"version\":1}\n{\"type\":\"budget\"}\n", "record 2: record has no data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFillsDefaults(t *testing.T) {
+	got, err := Read(strings.NewReader(`{"version": 1}`))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if got.Expenses == nil || got.Rules == nil || got.Settings.FundingAccount != settings.DEFAULT_FUNDING_ACCOUNT {
+		t.Errorf("Read() = %+v", got)
+	}
+}
+
+func TestCollectAndRestore(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if err := Restore(testBackup()); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	got, err := Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	want := testBackup()
+	want.ExportedAt = got.ExportedAt
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() = %+v, want %+v", got, want)
+	}
+
+	invalid := testBackup()
+	invalid.Expenses[1].ID = 7
+	if err := Restore(invalid); err == nil {
+		t.Errorf("Restore() should fail for an invalid backup")
+	}
+
+	stored, _ := expense.GetExpenses()
+	if len(stored) != 3 || stored[1].ID != 1 {
+		t.Errorf("Restore() should not write anything for an invalid backup, got %+v", stored)
+	}
+}
+
+func TestRestoreKeepsMachineFiles(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	kept := map[string]string{
+		auth.DEFAULT_TOKENS_FILE_PATH:      `[{"id":1,"name":"alice"}]`,
+		webhook.DEFAULT_WEBHOOKS_FILE_PATH: `[{"id":1,"url":"http://127.0.0.1:9000/hook"}]`,
+		webhook.DEFAULT_QUEUE_FILE_PATH:    `[{"id":1,"event":"expense.created"}]`,
+		mail.DEFAULT_EMAIL_FILE_PATH:       `{"host":"smtp.example.com","password":"secret"}`,
+	}
+	for path, data := range kept {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	if err := Restore(testBackup()); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	for path, data := range kept {
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != data {
+			t.Errorf("%s = %q, %v after Restore(), want it kept as %q", path, got, err, data)
+		}
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
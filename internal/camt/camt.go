@@ -0,0 +1,273 @@
+package camt
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	EXTERNAL_ID_PREFIX = "camt:"
+	DEBIT              = "DBIT"
+	BOOKED             = "BOOK"
+	NOT_PROVIDED       = "NOTPROVIDED"
+)
+
+/**
+* The parts of a camt.053 entry (Ntry) we read. Element names are matched
+* without their namespace, so every camt.053 version works.
+ */
+type entry struct {
+	Reference    string      `xml:"NtryRef"`
+	Amount       string      `xml:"Amt"`
+	CreditDebit  string      `xml:"CdtDbtInd"`
+	Status       status      `xml:"Sts"`
+	BookingDate  dateOrTime  `xml:"BookgDt"`
+	ValueDate    dateOrTime  `xml:"ValDt"`
+	ServicerRef  string      `xml:"AcctSvcrRef"`
+	Remittance   []string    `xml:"AddtlNtryInf"`
+	Transactions []txDetails `xml:"NtryDtls>TxDtls"`
+}
+
+/**
+* Before camt.053.001.08 the status is plain text, later versions wrap it in Cd.
+ */
+type status struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type dateOrTime struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type txDetails struct {
+	ServicerRef  string   `xml:"Refs>AcctSvcrRef"`
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	Amount       string   `xml:"Amt"`
+	TxAmount     string   `xml:"AmtDtls>TxAmt>Amt"`
+	CreditDebit  string   `xml:"CdtDbtInd"`
+	Creditor     []string `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  []string `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Debtor       []string `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    []string `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+	Additional   string   `xml:"AddtlTxInf"`
+}
+
+type account struct {
+	IBAN  string `xml:"Id>IBAN"`
+	Other string `xml:"Id>Othr>Id"`
+}
+
+/**
+* Reads the booked entries of a camt.053 bank-to-customer statement. Pending
+* entries are left out. An entry that batches several transactions becomes
+* one transaction per TxDtls. The counterparty is the creditor of a debit and
+* the debtor of a credit; the bank's reference, together with the account,
+* becomes the external ID used to skip transactions that were already imported.
+*
+* @param r The camt.053 XML statement.
+* @return The parsed transactions and the errors of the entries that could not be read.
+ */
+func ReadTransactions(r io.Reader) ([]importer.Transaction, []importer.RowError) {
+	decoder := xml.NewDecoder(r)
+
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+	accountID := ""
+	isStatement := false
+
+	// Every field is read as text, so decoding only fails on malformed XML,
+	// after which the rest of the document cannot be trusted
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line, _ := decoder.InputPos()
+			rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+			break
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "BkToCstmrStmt":
+			isStatement = true
+		case "Stmt":
+			accountID = ""
+		case "Acct":
+			var acct account
+			if err := decoder.DecodeElement(&acct, &start); err != nil {
+				line, _ := decoder.InputPos()
+				rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+				return transactions, rowErrors
+			}
+			accountID = acct.IBAN
+			if accountID == "" {
+				accountID = acct.Other
+			}
+		case "Ntry":
+			line, _ := decoder.InputPos()
+
+			var ntry entry
+			if err := decoder.DecodeElement(&ntry, &start); err != nil {
+				rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+				return transactions, rowErrors
+			}
+
+			if ntry.status() != BOOKED {
+				continue
+			}
+
+			read, err := readEntry(ntry, accountID, line)
+			if err != nil {
+				rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			transactions = append(transactions, read...)
+		}
+	}
+
+	if !isStatement && len(rowErrors) < 1 {
+		return nil, []importer.RowError{{Line: 0, Message: "not a camt.053 statement"}}
+	}
+
+	return transactions, rowErrors
+}
+
+func readEntry(ntry entry, accountID string, line int) ([]importer.Transaction, error) {
+	bookingDate, err := ntry.BookingDate.parse()
+	if err != nil {
+		return nil, errors.New("invalid booking date")
+	}
+
+	// The value date is optional, a missing one stays zero
+	valueDate, _ := ntry.ValueDate.parse()
+
+	details := ntry.Transactions
+	if len(details) < 1 {
+		details = []txDetails{{}}
+	}
+
+	transactions := []importer.Transaction{}
+	for i, tx := range details {
+		amountValue := firstOf(tx.Amount, tx.TxAmount)
+		if amountValue == "" || len(details) == 1 {
+			amountValue = ntry.Amount
+		}
+
+		amount, err := strconv.ParseFloat(strings.TrimSpace(amountValue), 64)
+		if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return nil, errors.New("invalid amount '" + amountValue + "'")
+		}
+
+		creditDebit := firstOf(tx.CreditDebit, ntry.CreditDebit)
+		isIncome := !strings.EqualFold(strings.TrimSpace(creditDebit), DEBIT)
+
+		counterparty := firstOf(append(tx.Creditor, tx.CreditorPty...)...)
+		if isIncome {
+			counterparty = firstOf(append(tx.Debtor, tx.DebtorPty...)...)
+		}
+
+		remittance := strings.Join(fields(tx.Unstructured), " ")
+		if remittance == "" {
+			remittance = firstOf(append([]string{tx.Additional}, ntry.Remittance...)...)
+		}
+
+		description := counterparty
+		notes := remittance
+		if description == "" {
+			description, notes = remittance, ""
+		}
+
+		reference := firstOf(tx.ServicerRef, notProvided(tx.EndToEndID))
+		if reference == "" {
+			reference = firstOf(ntry.ServicerRef, ntry.Reference)
+			if reference != "" && len(details) > 1 {
+				reference += "/" + strconv.Itoa(i+1)
+			}
+		}
+
+		externalID := ""
+		if reference != "" {
+			externalID = EXTERNAL_ID_PREFIX + accountID + ":" + reference
+		}
+
+		transactions = append(transactions, importer.Transaction{
+			Line:         line,
+			Date:         bookingDate,
+			ValueDate:    valueDate,
+			Amount:       math.Abs(amount),
+			Description:  description,
+			Counterparty: counterparty,
+			Notes:        notes,
+			IsIncome:     isIncome,
+			ExternalID:   externalID,
+		})
+	}
+
+	return transactions, nil
+}
+
+func (e entry) status() string {
+	return strings.ToUpper(strings.TrimSpace(firstOf(e.Status.Code, e.Status.Text)))
+}
+
+/**
+* Parses a camt date, given either as a plain date or as a date and time.
+* The calendar date of the statement is kept, whatever its time zone.
+ */
+func (d dateOrTime) parse() (time.Time, error) {
+	value := strings.TrimSpace(d.Date)
+	if value == "" {
+		value = strings.TrimSpace(d.DateTime)
+	}
+
+	if len(value) < 10 {
+		return time.Time{}, errors.New("invalid date")
+	}
+
+	return time.Parse("2006-01-02", value[:10])
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func fields(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func notProvided(value string) string {
+	if strings.EqualFold(strings.TrimSpace(value), NOT_PROVIDED) {
+		return ""
+	}
+
+	return value
+}
@@ -0,0 +1,72 @@
+package camt
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTransactions(t *testing.T) {
+	file, err := os.Open("./testdata/statement.xml")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	transactions, rowErrors := ReadTransactions(file)
+	if len(rowErrors) != 1 || rowErrors[0].Line != 72 || !strings.Contains(rowErrors[0].Message, "amount") {
+		t.Errorf("ReadTransactions() errors = %v, want the invalid amount on line 72", rowErrors)
+	}
+
+	if len(transactions) != 4 {
+		t.Fatalf("ReadTransactions() count = %v, want 4 booked transactions", len(transactions))
+	}
+
+	uber := transactions[0]
+	if uber.Description != "Uber BV" || uber.Counterparty != "Uber BV" || uber.Notes != "Trip 12.09. Airport" {
+		t.Errorf("ReadTransactions() uber = %+v", uber)
+	}
+	if uber.Amount != 12.5 || uber.IsIncome || uber.Line != 13 {
+		t.Errorf("ReadTransactions() uber amount = %v, income = %v, line = %v", uber.Amount, uber.IsIncome, uber.Line)
+	}
+	if !uber.Date.Equal(time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC)) || !uber.ValueDate.Equal(time.Date(2025, 9, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ReadTransactions() uber dates = %v, %v", uber.Date, uber.ValueDate)
+	}
+	if uber.ExternalID != "camt:DE89370400440532013000:BANKREF-001" {
+		t.Errorf("ReadTransactions() uber external id = %v", uber.ExternalID)
+	}
+
+	salary := transactions[1]
+	if !salary.IsIncome || salary.Counterparty != "ACME GmbH" || salary.Amount != 2000 {
+		t.Errorf("ReadTransactions() salary = %+v", salary)
+	}
+	if !salary.Date.Equal(time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC)) || !salary.ValueDate.IsZero() {
+		t.Errorf("ReadTransactions() salary dates = %v, %v", salary.Date, salary.ValueDate)
+	}
+	if salary.ExternalID != "camt:DE89370400440532013000:BANKREF-002" {
+		t.Errorf("ReadTransactions() salary external id = %v", salary.ExternalID)
+	}
+
+	utilities, phone := transactions[2], transactions[3]
+	if utilities.Amount != 50 || utilities.Description != "City Utilities" || utilities.ExternalID != "camt:DE89370400440532013000:BATCH-7/1" {
+		t.Errorf("ReadTransactions() first batch transaction = %+v", utilities)
+	}
+	if phone.Amount != 25 || phone.Description != "Phone bill" || phone.Notes != "" || phone.ExternalID != "camt:DE89370400440532013000:BATCH-7/2" {
+		t.Errorf("ReadTransactions() second batch transaction = %+v", phone)
+	}
+}
+
+func TestReadTransactionsNotCamt(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("<OFX><STMTTRN></STMTTRN></OFX>"))
+	if len(rowErrors) != 1 {
+		t.Errorf("ReadTransactions() should reject other XML, got %v", rowErrors)
+	}
+}
+
+func TestReadTransactionsMalformed(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("<Document><BkToCstmrStmt><Stmt>\n<Ntry></Stmt>"))
+	if len(rowErrors) != 1 || rowErrors[0].Line != 2 {
+		t.Errorf("ReadTransactions() errors = %v", rowErrors)
+	}
+}
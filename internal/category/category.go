@@ -0,0 +1,279 @@
+package category
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+type Category struct {
+	Name string `json:"name"`
+}
+
+const (
+	DEFAULT_CATEGORY_FILE_PATH = "./data/categories.json"
+	SEPARATOR                  = ":"
+)
+
+/**
+* Trims every segment of a category path and collapses inner whitespace,
+* so "  Food :  Fast   food " becomes "Food:Fast food".
+ */
+func Normalize(name string) string {
+	segments := strings.Split(name, SEPARATOR)
+	result := []string{}
+
+	for _, segment := range segments {
+		segment = strings.Join(strings.Fields(segment), " ")
+		if segment == "" {
+			continue
+		}
+		result = append(result, segment)
+	}
+
+	return strings.Join(result, SEPARATOR)
+}
+
+/**
+* Returns the case-insensitive matching key of a category.
+ */
+func Key(name string) string {
+	return strings.ToLower(Normalize(name))
+}
+
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+/**
+* Reports whether name is parent itself or one of its descendants,
+* e.g. "food:groceries" is within "Food".
+ */
+func IsWithin(name, parent string) bool {
+	nameKey := Key(name)
+	parentKey := Key(parent)
+
+	if parentKey == "" {
+		return nameKey == ""
+	}
+
+	return nameKey == parentKey || strings.HasPrefix(nameKey, parentKey+SEPARATOR)
+}
+
+/**
+* Returns the direct parent of a category, or an empty string for top-level ones.
+ */
+func Parent(name string) string {
+	normalized := Normalize(name)
+
+	idx := strings.LastIndex(normalized, SEPARATOR)
+	if idx == -1 {
+		return ""
+	}
+
+	return normalized[:idx]
+}
+
+/**
+* Returns every ancestor of a category from the top level down,
+* e.g. "Food:Groceries:Organic" has "Food" and "Food:Groceries".
+ */
+func Ancestors(name string) []string {
+	ancestors := []string{}
+
+	for parent := Parent(name); parent != ""; parent = Parent(parent) {
+		ancestors = append([]string{parent}, ancestors...)
+	}
+
+	return ancestors
+}
+
+func Depth(name string) int {
+	return len(Ancestors(name))
+}
+
+/**
+* Maps a category onto the canonical spelling of the registry, segment by
+* segment. Segments that are not registered keep their normalized spelling.
+ */
+func Resolve(categories []Category, name string) string {
+	canonical := map[string]string{}
+	for _, c := range categories {
+		canonical[Key(c.Name)] = c.Name
+	}
+
+	resolved := ""
+	for _, segment := range strings.Split(Normalize(name), SEPARATOR) {
+		path := segment
+		if resolved != "" {
+			path = resolved + SEPARATOR + segment
+		}
+
+		if registered, ok := canonical[Key(path)]; ok {
+			resolved = registered
+		} else {
+			resolved = path
+		}
+	}
+
+	return resolved
+}
+
+func GetCategories() ([]Category, error) {
+	data, err := storage.GetFileData(DEFAULT_CATEGORY_FILE_PATH)
+	if err != nil {
+		return []Category{}, err
+	}
+
+	if len(data) < 1 {
+		return []Category{}, nil
+	}
+
+	var categories []Category
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return []Category{}, err
+	}
+
+	return categories, nil
+}
+
+/**
+* Returns the canonical spelling of a category without registering it.
+ */
+func Canonical(name string) (string, error) {
+	categories, err := GetCategories()
+	if err != nil {
+		return "", err
+	}
+
+	return Resolve(categories, name), nil
+}
+
+/**
+* Registers a category and all of its ancestors unless they are already known.
+*
+* @param name The category name in any spelling.
+* @return The canonical category name, or an error if the name is empty or the registry cannot be written.
+ */
+func Register(name string) (string, error) {
+	if Normalize(name) == "" {
+		return "", errors.New("category not set")
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		return "", err
+	}
+
+	canonical := Resolve(categories, name)
+	categories, changed := WithCategory(categories, canonical)
+	if !changed {
+		return canonical, nil
+	}
+
+	if err := SaveCategories(categories); err != nil {
+		return "", err
+	}
+
+	return canonical, nil
+}
+
+/**
+* Explicitly adds a new category to the registry.
+*
+* @return The canonical category name, or an error if the category is already registered.
+ */
+func AddCategory(name string) (string, error) {
+	categories, err := GetCategories()
+	if err != nil {
+		return "", err
+	}
+
+	if isRegistered(categories, name) {
+		return "", errors.New("category already exists")
+	}
+
+	return Register(name)
+}
+
+/**
+* Removes a category from the registry. Categories that still have
+* registered children cannot be removed.
+ */
+func RemoveCategory(name string) error {
+	categories, err := GetCategories()
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(categories, func(c Category) bool {
+		return Equal(c.Name, name)
+	})
+	if idx == -1 {
+		return errors.New("category not found")
+	}
+
+	for _, c := range categories {
+		if !Equal(c.Name, name) && IsWithin(c.Name, name) {
+			return errors.New("category has subcategories")
+		}
+	}
+
+	categories = append(categories[:idx], categories[idx+1:]...)
+
+	return SaveCategories(categories)
+}
+
+func SaveCategories(categories []Category) error {
+	SortCategories(categories)
+
+	data, err := json.Marshal(categories)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.WriteFileData(DEFAULT_CATEGORY_FILE_PATH, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/**
+* Sorts categories so that every parent comes right before its children.
+ */
+func SortCategories(categories []Category) {
+	sort.Slice(categories, func(i, j int) bool {
+		return Key(categories[i].Name) < Key(categories[j].Name)
+	})
+}
+
+func isRegistered(categories []Category, name string) bool {
+	return slices.ContainsFunc(categories, func(c Category) bool {
+		return Equal(c.Name, name)
+	})
+}
+
+/**
+* Adds a category and its missing ancestors to a registry slice without saving it.
+*
+* @return The updated registry and whether anything was added.
+ */
+func WithCategory(categories []Category, canonical string) ([]Category, bool) {
+	changed := false
+
+	for _, name := range append(Ancestors(canonical), canonical) {
+		if isRegistered(categories, name) {
+			continue
+		}
+
+		categories = append(categories, Category{Name: name})
+		changed = true
+	}
+
+	return categories, changed
+}
@@ -0,0 +1,170 @@
+package category
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Plain", "Food", "Food"},
+		{"Surrounding spaces", "  Food ", "Food"},
+		{"Inner spaces", "Fast   food", "Fast food"},
+		{"Hierarchy", " Food :  Groceries ", "Food:Groceries"},
+		{"Empty segments", "Food::Groceries:", "Food:Groceries"},
+		{"Empty", "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		name   string
+		child  string
+		parent string
+		want   bool
+	}{
+		{"Same", "Food", "Food", true},
+		{"Different case", "food ", "FOOD", true},
+		{"Child", "Food:Groceries", "food", true},
+		{"Grandchild", "Food:Groceries:Organic", "Food", true},
+		{"Parent is not within child", "Food", "Food:Groceries", false},
+		{"Shared prefix", "Foodstuff", "Food", false},
+		{"Empty parent", "Food", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWithin(tt.child, tt.parent); got != tt.want {
+				t.Errorf("IsWithin(%q, %q) = %v, want %v", tt.child, tt.parent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	ancestors := Ancestors("Food:Groceries:Organic")
+	if len(ancestors) != 2 || ancestors[0] != "Food" || ancestors[1] != "Food:Groceries" {
+		t.Errorf("Ancestors() = %v", ancestors)
+	}
+
+	if len(Ancestors("Food")) != 0 {
+		t.Errorf("Ancestors() of a top-level category should be empty")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	registry := []Category{{Name: "Food"}, {Name: "Food:Groceries"}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Registered", "food", "Food"},
+		{"Registered child", "FOOD:groceries ", "Food:Groceries"},
+		{"New child of registered parent", "food:Takeaway", "Food:Takeaway"},
+		{"Unregistered", " Travel ", "Travel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(registry, tt.input); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	name, err := Register(" Food : Groceries")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if name != "Food:Groceries" {
+		t.Errorf("Register() = %q, want Food:Groceries", name)
+	}
+
+	name, err = Register("food")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if name != "Food" {
+		t.Errorf("Register() = %q, want canonical Food", name)
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		t.Fatalf("GetCategories() error = %v", err)
+	}
+	if len(categories) != 2 {
+		t.Errorf("GetCategories() count = %v, want 2 (parent registered once)", len(categories))
+	}
+
+	if _, err := Register("  "); err == nil {
+		t.Errorf("Register() should fail for an empty name")
+	}
+}
+
+func TestAddAndRemoveCategory(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if _, err := AddCategory("Food:Groceries"); err != nil {
+		t.Fatalf("AddCategory() error = %v", err)
+	}
+
+	if _, err := AddCategory("FOOD"); err == nil {
+		t.Errorf("AddCategory() should fail for an existing category")
+	}
+
+	if err := RemoveCategory("food"); err == nil {
+		t.Errorf("RemoveCategory() should fail for a category with subcategories")
+	}
+
+	if err := RemoveCategory("food:groceries"); err != nil {
+		t.Errorf("RemoveCategory() error = %v", err)
+	}
+
+	if err := RemoveCategory("Food"); err != nil {
+		t.Errorf("RemoveCategory() error = %v", err)
+	}
+
+	if err := RemoveCategory("Food"); err == nil {
+		t.Errorf("RemoveCategory() should fail for an unknown category")
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+	// Create empty categories.json file
+	err = os.WriteFile("./data/categories.json", []byte("[]"), 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test categories file: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
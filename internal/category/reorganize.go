@@ -0,0 +1,219 @@
+package category
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+type ExpenseChange struct {
+	ID   int
+	From string
+	To   string
+}
+
+type BudgetChange struct {
+	Year     int
+	Month    int
+	From     string
+	To       string
+	Limit    float64
+	IsMerged bool
+}
+
+type Change struct {
+	From     string
+	To       string
+	Expenses []ExpenseChange
+	Budgets  []BudgetChange
+}
+
+type ledger struct {
+	expenses   []expense.Expense
+	budgets    []budget.Budget
+	categories []Category
+}
+
+/**
+* Renames a category and its subcategories in every expense, budget and the
+* registry. The target name must not be in use yet; use Merge for that.
+*
+* @param from The category to rename.
+* @param to The new category name.
+* @param dryRun When true, only reports the affected records without writing anything.
+* @return The affected records, or an error if the rename is not possible or cannot be written.
+ */
+func Rename(from, to string, dryRun bool) (Change, error) {
+	return reorganize(from, to, false, dryRun)
+}
+
+/**
+* Moves every expense and budget of a category and its subcategories into
+* another category. Budgets that end up on the same month and category are
+* combined by adding their limits, and the merged category is unregistered.
+*
+* @param from The category to merge away.
+* @param to The category to merge into.
+* @param dryRun When true, only reports the affected records without writing anything.
+* @return The affected records, or an error if the merge is not possible or cannot be written.
+ */
+func Merge(from, to string, dryRun bool) (Change, error) {
+	return reorganize(from, to, true, dryRun)
+}
+
+func reorganize(from, to string, isMerge, dryRun bool) (Change, error) {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return Change{}, err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return Change{}, err
+	}
+
+	categories, err := GetCategories()
+	if err != nil {
+		return Change{}, err
+	}
+
+	current := ledger{expenses: expenses, budgets: budgets, categories: categories}
+
+	change, updated, err := planReorganize(current, from, to, isMerge)
+	if err != nil {
+		return Change{}, err
+	}
+
+	if dryRun {
+		return change, nil
+	}
+
+	if err := saveLedger(updated); err != nil {
+		return Change{}, err
+	}
+
+	return change, nil
+}
+
+func planReorganize(current ledger, from, to string, isMerge bool) (Change, ledger, error) {
+	if Normalize(from) == "" || Normalize(to) == "" {
+		return Change{}, ledger{}, errors.New("both --from and --to categories must be set")
+	}
+
+	from = Resolve(current.categories, from)
+	to = Resolve(current.categories, to)
+
+	if IsWithin(to, from) {
+		return Change{}, ledger{}, errors.New("cannot move a category into itself or its subcategory")
+	}
+
+	isKnown := isRegistered(current.categories, from) ||
+		slices.ContainsFunc(current.expenses, func(e expense.Expense) bool { return IsWithin(e.Category, from) }) ||
+		slices.ContainsFunc(current.budgets, func(b budget.Budget) bool { return IsWithin(b.Category, from) })
+	if !isKnown {
+		return Change{}, ledger{}, errors.New("category not found")
+	}
+
+	isTargetUsed := isRegistered(current.categories, to) ||
+		slices.ContainsFunc(current.expenses, func(e expense.Expense) bool { return IsWithin(e.Category, to) }) ||
+		slices.ContainsFunc(current.budgets, func(b budget.Budget) bool { return IsWithin(b.Category, to) })
+	if !isMerge && isTargetUsed {
+		return Change{}, ledger{}, errors.New("category " + to + " already exists, merge into it instead")
+	}
+
+	change := Change{From: from, To: to}
+	updated := ledger{
+		expenses:   slices.Clone(current.expenses),
+		budgets:    []budget.Budget{},
+		categories: []Category{},
+	}
+
+	for i, exp := range updated.expenses {
+		if !IsWithin(exp.Category, from) {
+			continue
+		}
+
+		newName := Resolve(current.categories, moveName(exp.Category, from, to))
+		change.Expenses = append(change.Expenses, ExpenseChange{ID: exp.ID, From: exp.Category, To: newName})
+		updated.expenses[i].Category = newName
+	}
+
+	for _, b := range current.budgets {
+		if !IsWithin(b.Category, from) {
+			updated.budgets = append(updated.budgets, b)
+		}
+	}
+
+	for _, b := range current.budgets {
+		if !IsWithin(b.Category, from) {
+			continue
+		}
+
+		newName := Resolve(current.categories, moveName(b.Category, from, to))
+		budgetChange := BudgetChange{Year: b.Year, Month: b.Month, From: b.Category, To: newName, Limit: b.Limit}
+
+		idx := slices.IndexFunc(updated.budgets, func(existing budget.Budget) bool {
+			return existing.Year == b.Year && existing.Month == b.Month && Equal(existing.Category, newName)
+		})
+		if idx == -1 {
+			b.Category = newName
+			updated.budgets = append(updated.budgets, b)
+		} else {
+			updated.budgets[idx].Limit += b.Limit
+			budgetChange.IsMerged = true
+		}
+
+		change.Budgets = append(change.Budgets, budgetChange)
+	}
+
+	for _, c := range current.categories {
+		if IsWithin(c.Name, from) {
+			updated.categories, _ = WithCategory(updated.categories, moveName(c.Name, from, to))
+			continue
+		}
+
+		if !isRegistered(updated.categories, c.Name) {
+			updated.categories = append(updated.categories, c)
+		}
+	}
+	updated.categories, _ = WithCategory(updated.categories, to)
+
+	return change, updated, nil
+}
+
+/**
+* Replaces the from prefix of a category in the from subtree with to,
+* e.g. moving "Snacks:Chips" from "Snacks" to "Food" gives "Food:Chips".
+ */
+func moveName(name, from, to string) string {
+	normalized := Normalize(name)
+	return Normalize(to + normalized[len(Normalize(from)):])
+}
+
+func saveLedger(l ledger) error {
+	expensesData, err := json.Marshal(l.expenses)
+	if err != nil {
+		return err
+	}
+
+	budgetsData, err := json.Marshal(l.budgets)
+	if err != nil {
+		return err
+	}
+
+	SortCategories(l.categories)
+	categoriesData, err := json.Marshal(l.categories)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFilesData(map[string][]byte{
+		expense.EXPENSES_FILE_PATH:      expensesData,
+		budget.DEFAULT_BUDGET_FILE_PATH: budgetsData,
+		DEFAULT_CATEGORY_FILE_PATH:      categoriesData,
+	})
+}
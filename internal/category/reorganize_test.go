@@ -0,0 +1,161 @@
+package category
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func testLedger() ledger {
+	return ledger{
+		expenses: []expense.Expense{
+			{ID: 0, Amount: 10, Category: "Snacks"},
+			{ID: 1, Amount: 20, Category: "snacks:Chips"},
+			{ID: 2, Amount: 30, Category: "Food"},
+			{ID: 3, Amount: 40, Category: "Snacksbar"},
+		},
+		budgets: []budget.Budget{
+			{Year: 2025, Month: 9, Category: "Snacks", Limit: 50},
+			{Year: 2025, Month: 9, Category: "Food", Limit: 300},
+			{Year: 2025, Month: 10, Category: "Snacks", Limit: 60},
+		},
+		categories: []Category{{Name: "Food"}, {Name: "Snacks"}, {Name: "Snacks:Chips"}, {Name: "Snacksbar"}},
+	}
+}
+
+func TestPlanReorganizeRename(t *testing.T) {
+	change, updated, err := planReorganize(testLedger(), "snacks", "Treats", false)
+	if err != nil {
+		t.Fatalf("planReorganize() error = %v", err)
+	}
+
+	if len(change.Expenses) != 2 || len(change.Budgets) != 2 {
+		t.Errorf("planReorganize() change = %+v", change)
+	}
+
+	wantCategories := []string{"Treats", "Treats:Chips", "Food", "Snacksbar"}
+	for i, want := range wantCategories {
+		if updated.expenses[i].Category != want {
+			t.Errorf("planReorganize() expense %d category = %v, want %v", i, updated.expenses[i].Category, want)
+		}
+	}
+
+	if isRegistered(updated.categories, "Snacks") || isRegistered(updated.categories, "Snacks:Chips") {
+		t.Errorf("planReorganize() should unregister the old names: %+v", updated.categories)
+	}
+	if !isRegistered(updated.categories, "Treats:Chips") || !isRegistered(updated.categories, "Snacksbar") {
+		t.Errorf("planReorganize() should register the new names: %+v", updated.categories)
+	}
+}
+
+func TestPlanReorganizeRenameIntoExisting(t *testing.T) {
+	if _, _, err := planReorganize(testLedger(), "Snacks", "food", false); err == nil {
+		t.Errorf("planReorganize() rename into an existing category should fail")
+	}
+}
+
+func TestPlanReorganizeIntoItself(t *testing.T) {
+	if _, _, err := planReorganize(testLedger(), "Snacks", "Snacks:Old", true); err == nil {
+		t.Errorf("planReorganize() into a subcategory should fail")
+	}
+}
+
+func TestPlanReorganizeUnknown(t *testing.T) {
+	if _, _, err := planReorganize(testLedger(), "Travel", "Food", true); err == nil {
+		t.Errorf("planReorganize() of an unknown category should fail")
+	}
+}
+
+func TestPlanReorganizeMerge(t *testing.T) {
+	change, updated, err := planReorganize(testLedger(), "Snacks", "food", true)
+	if err != nil {
+		t.Fatalf("planReorganize() error = %v", err)
+	}
+
+	if updated.expenses[0].Category != "Food" || updated.expenses[1].Category != "Food:Chips" {
+		t.Errorf("planReorganize() expenses = %+v", updated.expenses)
+	}
+
+	if len(updated.budgets) != 2 {
+		t.Fatalf("planReorganize() budgets = %+v, want 2", updated.budgets)
+	}
+
+	for _, b := range updated.budgets {
+		if b.Month == 9 && b.Limit != 350 {
+			t.Errorf("planReorganize() merged September limit = %v, want 350", b.Limit)
+		}
+		if b.Month == 10 && (b.Limit != 60 || b.Category != "Food") {
+			t.Errorf("planReorganize() moved October budget = %+v", b)
+		}
+	}
+
+	merged := 0
+	for _, b := range change.Budgets {
+		if b.IsMerged {
+			merged++
+		}
+	}
+	if merged != 1 {
+		t.Errorf("planReorganize() merged budgets = %v, want 1", merged)
+	}
+}
+
+func TestRenameDryRunAndWrite(t *testing.T) {
+	setupLedgerTestData(t)
+	defer cleanupTestData(t)
+
+	change, err := Rename("Snacks", "Treats", true)
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if len(change.Expenses) != 2 {
+		t.Errorf("Rename() dry run expenses = %v, want 2", len(change.Expenses))
+	}
+
+	expenses, _ := expense.GetExpenses()
+	if expenses[0].Category != "Snacks" {
+		t.Errorf("Rename() dry run should not write expenses")
+	}
+
+	if _, err := Rename("Snacks", "Treats", false); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	expenses, _ = expense.GetExpenses()
+	if expenses[0].Category != "Treats" || expenses[1].Category != "Treats:Chips" {
+		t.Errorf("Rename() expenses = %+v", expenses)
+	}
+
+	budgets, _ := budget.GetBudgets()
+	for _, b := range budgets {
+		if Equal(b.Category, "Snacks") {
+			t.Errorf("Rename() left a budget on the old name: %+v", b)
+		}
+	}
+
+	categories, _ := GetCategories()
+	if isRegistered(categories, "Snacks") {
+		t.Errorf("Rename() left the old name registered")
+	}
+}
+
+func setupLedgerTestData(t *testing.T) {
+	setupTestData(t)
+
+	l := testLedger()
+	files := map[string]any{
+		"./data/expenses.json":   l.expenses,
+		"./data/budgets.json":    l.budgets,
+		"./data/categories.json": l.categories,
+	}
+
+	for fileName, v := range files {
+		data, _ := json.Marshal(v)
+		if err := os.WriteFile(fileName, data, 0755); err != nil {
+			t.Fatalf("Failed to create %v: %v", fileName, err)
+		}
+	}
+}
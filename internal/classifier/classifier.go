@@ -0,0 +1,169 @@
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+const (
+	MIN_TOKEN_LENGTH = 2
+	// Additive smoothing for unseen tokens; kept well below 1 so that a
+	// single matching past expense outweighs the category priors.
+	SMOOTHING = 0.1
+)
+
+type Suggestion struct {
+	Category   string
+	Confidence float64
+}
+
+/**
+* A multinomial naive Bayes model over description tokens,
+* trained locally from already categorised expenses.
+ */
+type Model struct {
+	names          map[string]string
+	documentCounts map[string]int
+	tokenCounts    map[string]map[string]int
+	tokenTotals    map[string]int
+	vocabulary     map[string]bool
+	documents      int
+}
+
+/**
+* Trains a model on every non-deleted expense that has both a description and a category.
+* Incomes are left out, as their payees say nothing about spending categories.
+* Categories are grouped case-insensitively.
+ */
+func Train(expenses []expense.Expense) Model {
+	model := Model{
+		names:          map[string]string{},
+		documentCounts: map[string]int{},
+		tokenCounts:    map[string]map[string]int{},
+		tokenTotals:    map[string]int{},
+		vocabulary:     map[string]bool{},
+	}
+
+	for _, exp := range expenses {
+		if exp.IsDeleted || exp.IsIncome || category.Normalize(exp.Category) == "" {
+			continue
+		}
+
+		tokens := Tokenize(exp.Description)
+		if len(tokens) < 1 {
+			continue
+		}
+
+		key := category.Key(exp.Category)
+		if _, ok := model.names[key]; !ok {
+			model.names[key] = category.Normalize(exp.Category)
+			model.tokenCounts[key] = map[string]int{}
+		}
+
+		model.documents++
+		model.documentCounts[key]++
+
+		for _, token := range tokens {
+			model.tokenCounts[key][token]++
+			model.tokenTotals[key]++
+			model.vocabulary[token] = true
+		}
+	}
+
+	return model
+}
+
+/**
+* Ranks categories for a description.
+*
+* @param description The expense description to classify.
+* @param limit The maximum number of suggestions to return.
+* @return Suggestions ordered by confidence, or none if no token of the description has been seen before.
+ */
+func (m Model) Suggest(description string, limit int) []Suggestion {
+	tokens := []string{}
+	for _, token := range Tokenize(description) {
+		if m.vocabulary[token] {
+			tokens = append(tokens, token)
+		}
+	}
+
+	if len(tokens) < 1 || m.documents < 1 {
+		return []Suggestion{}
+	}
+
+	smoothedVocabulary := float64(len(m.vocabulary)) * SMOOTHING
+	scores := map[string]float64{}
+	maxScore := math.Inf(-1)
+
+	for key, documents := range m.documentCounts {
+		score := math.Log(float64(documents) / float64(m.documents))
+
+		for _, token := range tokens {
+			count := float64(m.tokenCounts[key][token])
+			score += math.Log((count + SMOOTHING) / (float64(m.tokenTotals[key]) + smoothedVocabulary))
+		}
+
+		scores[key] = score
+		maxScore = math.Max(maxScore, score)
+	}
+
+	// Turn the log scores into probabilities, shifted by the maximum to avoid underflow
+	total := 0.0
+	for key, score := range scores {
+		scores[key] = math.Exp(score - maxScore)
+		total += scores[key]
+	}
+
+	suggestions := []Suggestion{}
+	for key, score := range scores {
+		suggestions = append(suggestions, Suggestion{
+			Category:   m.names[key],
+			Confidence: score / total,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence == suggestions[j].Confidence {
+			return suggestions[i].Category < suggestions[j].Category
+		}
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+
+	return suggestions[:min(limit, len(suggestions))]
+}
+
+/**
+* Splits a description into lowercase word tokens, dropping
+* numbers and tokens shorter than MIN_TOKEN_LENGTH.
+ */
+func Tokenize(description string) []string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := []string{}
+	for _, word := range words {
+		if len([]rune(word)) < MIN_TOKEN_LENGTH || isNumber(word) {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+
+	return tokens
+}
+
+func isNumber(word string) bool {
+	for _, r := range word {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,102 @@
+package classifier
+
+import (
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func trainingExpenses() []expense.Expense {
+	return []expense.Expense{
+		{Description: "Lidl weekly shop", Category: "Food:Groceries"},
+		{Description: "Lidl", Category: "food:groceries"},
+		{Description: "Aldi groceries", Category: "Food:Groceries"},
+		{Description: "Uber to office", Category: "Transport"},
+		{Description: "Uber airport", Category: "Transport"},
+		{Description: "Metro ticket", Category: "Transport"},
+		{Description: "Netflix subscription", Category: "Subscriptions"},
+		{Description: "Lidl", Category: "Transport", IsDeleted: true},
+		{Description: "Lidl", Category: ""},
+		{Description: "Employer salary", Category: "Salary", IsIncome: true},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("UBER *Trip, 2025-09-12 to the Airport (x)")
+	want := []string{"uber", "trip", "to", "the", "airport"}
+
+	if len(tokens) != len(want) {
+		t.Fatalf("Tokenize() = %v, want %v", tokens, want)
+	}
+
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("Tokenize()[%d] = %v, want %v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	model := Train(trainingExpenses())
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{"Known shop", "LIDL Berlin", "Food:Groceries"},
+		{"Known ride", "uber home", "Transport"},
+		{"Known subscription", "Netflix", "Subscriptions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := model.Suggest(tt.description, 3)
+			if len(suggestions) < 1 {
+				t.Fatalf("Suggest() returned no suggestions")
+			}
+			if suggestions[0].Category != tt.want {
+				t.Errorf("Suggest() top = %v, want %v", suggestions[0].Category, tt.want)
+			}
+			if suggestions[0].Confidence <= 0.5 {
+				t.Errorf("Suggest() confidence = %v, want > 0.5", suggestions[0].Confidence)
+			}
+		})
+	}
+}
+
+func TestSuggestConfidenceAndLimit(t *testing.T) {
+	model := Train(trainingExpenses())
+
+	suggestions := model.Suggest("lidl", 2)
+	if len(suggestions) != 2 {
+		t.Fatalf("Suggest() count = %v, want 2", len(suggestions))
+	}
+	if suggestions[0].Confidence < suggestions[1].Confidence {
+		t.Errorf("Suggest() should be ordered by confidence: %+v", suggestions)
+	}
+
+	total := 0.0
+	for _, s := range model.Suggest("lidl", 10) {
+		total += s.Confidence
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("Suggest() confidences sum = %v, want 1", total)
+	}
+}
+
+func TestSuggestUnknown(t *testing.T) {
+	model := Train(trainingExpenses())
+
+	if suggestions := model.Suggest("Dentist", 3); len(suggestions) != 0 {
+		t.Errorf("Suggest() for unseen tokens = %+v, want none", suggestions)
+	}
+
+	if suggestions := model.Suggest("Employer salary", 3); len(suggestions) != 0 {
+		t.Errorf("Suggest() learned from an income = %+v, want none", suggestions)
+	}
+
+	if suggestions := Train(nil).Suggest("lidl", 3); len(suggestions) != 0 {
+		t.Errorf("Suggest() of an empty model = %+v, want none", suggestions)
+	}
+}
@@ -0,0 +1,206 @@
+package csvio
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+const (
+	COLUMN_ID          = "id"
+	COLUMN_DATE        = "date"
+	COLUMN_DESCRIPTION = "description"
+	COLUMN_AMOUNT      = "amount"
+	COLUMN_CATEGORY    = "category"
+	COLUMN_MONTH       = "month"
+	COLUMN_YEAR        = "year"
+	COLUMN_TAGS        = "tags"
+	COLUMN_DELETED     = "deleted"
+)
+
+const (
+	DEFAULT_DELIMITER   = ','
+	DEFAULT_DATE_FORMAT = "2006-01-02"
+	TAGS_SEPARATOR      = ";"
+)
+
+var (
+	ALL_COLUMNS = []string{
+		COLUMN_ID,
+		COLUMN_DATE,
+		COLUMN_DESCRIPTION,
+		COLUMN_AMOUNT,
+		COLUMN_CATEGORY,
+		COLUMN_MONTH,
+		COLUMN_YEAR,
+		COLUMN_TAGS,
+		COLUMN_DELETED,
+	}
+	DEFAULT_COLUMNS = []string{
+		COLUMN_ID,
+		COLUMN_DATE,
+		COLUMN_DESCRIPTION,
+		COLUMN_AMOUNT,
+		COLUMN_CATEGORY,
+		COLUMN_MONTH,
+	}
+	DATE_FORMAT_ALIASES = map[string]string{
+		"iso":      "2006-01-02",
+		"rfc3339":  time.RFC3339,
+		"datetime": "2006-01-02 15:04:05",
+		"us":       "01/02/2006",
+		"eu":       "02.01.2006",
+	}
+)
+
+type ExportOptions struct {
+	Columns    []string
+	Delimiter  rune
+	DateFormat string
+}
+
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{
+		Columns:    DEFAULT_COLUMNS,
+		Delimiter:  DEFAULT_DELIMITER,
+		DateFormat: DEFAULT_DATE_FORMAT,
+	}
+}
+
+/**
+* Parses a comma-separated column list such as "date,amount,description".
+*
+* @return The lowercase column names, or an error naming the first unknown column.
+ */
+func ParseColumns(value string) ([]string, error) {
+	columns := []string{}
+
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+
+		if !slices.Contains(ALL_COLUMNS, column) {
+			return []string{}, errors.New("unknown column " + column + ", available: " + strings.Join(ALL_COLUMNS, ", "))
+		}
+
+		columns = append(columns, column)
+	}
+
+	if len(columns) < 1 {
+		return []string{}, errors.New("no columns selected")
+	}
+
+	return columns, nil
+}
+
+/**
+* Parses a delimiter given as a single character, or as "tab" / "\t".
+ */
+func ParseDelimiter(value string) (rune, error) {
+	if value == "tab" || value == `\t` {
+		return '\t', nil
+	}
+
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, errors.New("delimiter must be a single character other than a quote or a line break")
+	}
+
+	return runes[0], nil
+}
+
+/**
+* Resolves a date format alias (iso, rfc3339, datetime, us, eu),
+* or returns the value itself as a Go time layout.
+ */
+func ParseDateFormat(value string) string {
+	if layout, ok := DATE_FORMAT_ALIASES[strings.ToLower(value)]; ok {
+		return layout
+	}
+
+	return value
+}
+
+/**
+* Writes expenses as RFC 4180 CSV, one row at a time, so the whole
+* export never has to be held in memory. Fields containing the delimiter,
+* quotes or line breaks are quoted by encoding/csv.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param expenses The expenses to write, already filtered by the caller.
+* @param options The columns, delimiter and date format to use.
+* @return An error if the options are invalid or writing fails; otherwise, nil.
+ */
+func WriteExpenses(w io.Writer, expenses []expense.Expense, options ExportOptions) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = options.Delimiter
+
+	header := []string{}
+	for _, column := range options.Columns {
+		header = append(header, columnTitle(column))
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, exp := range expenses {
+		record := make([]string, 0, len(options.Columns))
+		for _, column := range options.Columns {
+			record = append(record, columnValue(exp, column, options.DateFormat))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func columnTitle(column string) string {
+	if column == COLUMN_ID {
+		return "ID"
+	}
+
+	return strings.ToUpper(column[:1]) + column[1:]
+}
+
+func columnValue(exp expense.Expense, column, dateFormat string) string {
+	switch column {
+	case COLUMN_ID:
+		return strconv.Itoa(exp.ID)
+	case COLUMN_DATE:
+		return exp.Date.Format(dateFormat)
+	case COLUMN_DESCRIPTION:
+		return exp.Description
+	case COLUMN_AMOUNT:
+		// Incomes are negative, as DefaultProfile reads them back
+		if exp.IsIncome {
+			return strconv.FormatFloat(-exp.Amount, 'f', 2, 64)
+		}
+		return strconv.FormatFloat(exp.Amount, 'f', 2, 64)
+	case COLUMN_CATEGORY:
+		return exp.Category
+	case COLUMN_MONTH:
+		return strconv.Itoa(exp.Month)
+	case COLUMN_YEAR:
+		return strconv.Itoa(exp.Date.Year())
+	case COLUMN_TAGS:
+		return strings.Join(exp.Tags, TAGS_SEPARATOR)
+	case COLUMN_DELETED:
+		return strconv.FormatBool(exp.IsDeleted)
+	}
+
+	return ""
+}
@@ -0,0 +1,162 @@
+package csvio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func exportExpenses() []expense.Expense {
+	return []expense.Expense{
+		{
+			ID:          0,
+			Amount:      12.5,
+			Date:        time.Date(2025, 9, 12, 18, 30, 0, 0, time.UTC),
+			Month:       9,
+			Description: `Dinner, "La Piazza"`,
+			Category:    "Food",
+			Tags:        []string{"work", "shared"},
+		},
+		{
+			ID:          1,
+			Amount:      3,
+			Date:        time.Date(2025, 9, 13, 8, 0, 0, 0, time.UTC),
+			Month:       9,
+			Description: "Coffee\nto go",
+			Category:    "Food",
+			IsDeleted:   true,
+		},
+	}
+}
+
+func TestWriteExpensesRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteExpenses(&buf, exportExpenses(), DefaultExportOptions()); err != nil {
+		t.Fatalf("WriteExpenses() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("WriteExpenses() output is not valid CSV: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("WriteExpenses() rows = %v, want 3", len(records))
+	}
+
+	wantHeader := []string{"ID", "Date", "Description", "Amount", "Category", "Month"}
+	for i, want := range wantHeader {
+		if records[0][i] != want {
+			t.Errorf("WriteExpenses() header[%d] = %v, want %v", i, records[0][i], want)
+		}
+	}
+
+	if records[1][2] != `Dinner, "La Piazza"` {
+		t.Errorf("WriteExpenses() description = %v", records[1][2])
+	}
+	if records[2][2] != "Coffee\nto go" {
+		t.Errorf("WriteExpenses() multiline description = %q", records[2][2])
+	}
+	if records[1][1] != "2025-09-12" || records[1][3] != "12.50" {
+		t.Errorf("WriteExpenses() row = %v", records[1])
+	}
+}
+
+func TestWriteExpensesIncomeRoundTrip(t *testing.T) {
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 12.5, Date: time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), Month: 9, Description: "Lunch"},
+		{ID: 1, Amount: 2000, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Month: 9, Description: "Salary", IsIncome: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExpenses(&buf, expenses, DefaultExportOptions()); err != nil {
+		t.Fatalf("WriteExpenses() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Salary,-2000.00") {
+		t.Errorf("WriteExpenses() must write incomes as negative amounts:\n%s", buf.String())
+	}
+
+	transactions, rowErrors := ReadTransactions(&buf, DefaultProfile())
+	if len(rowErrors) != 0 || len(transactions) != 2 {
+		t.Fatalf("ReadTransactions() = %+v, %v", transactions, rowErrors)
+	}
+
+	if transactions[0].IsIncome || transactions[0].Amount != 12.5 {
+		t.Errorf("ReadTransactions() expense = %+v", transactions[0])
+	}
+	if !transactions[1].IsIncome || transactions[1].Amount != 2000 {
+		t.Errorf("ReadTransactions() income = %+v", transactions[1])
+	}
+}
+
+func TestWriteExpensesOptions(t *testing.T) {
+	var buf bytes.Buffer
+
+	options := ExportOptions{
+		Columns:    []string{COLUMN_DATE, COLUMN_AMOUNT, COLUMN_TAGS, COLUMN_DELETED},
+		Delimiter:  ';',
+		DateFormat: ParseDateFormat("eu"),
+	}
+
+	if err := WriteExpenses(&buf, exportExpenses()[:1], options); err != nil {
+		t.Fatalf("WriteExpenses() error = %v", err)
+	}
+
+	// Tags are joined with ";" too, so the field has to be quoted
+	want := "Date;Amount;Tags;Deleted\n12.09.2025;12.50;\"work;shared\";false\n"
+	if buf.String() != want {
+		t.Errorf("WriteExpenses() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns(" Date, amount ,,DESCRIPTION")
+	if err != nil {
+		t.Fatalf("ParseColumns() error = %v", err)
+	}
+	if len(columns) != 3 || columns[0] != COLUMN_DATE || columns[2] != COLUMN_DESCRIPTION {
+		t.Errorf("ParseColumns() = %v", columns)
+	}
+
+	if _, err := ParseColumns("date,price"); err == nil {
+		t.Errorf("ParseColumns() should fail for an unknown column")
+	}
+
+	if _, err := ParseColumns(" , "); err == nil {
+		t.Errorf("ParseColumns() should fail without columns")
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr bool
+	}{
+		{";", ';', false},
+		{"tab", '\t', false},
+		{`\t`, '\t', false},
+		{"|", '|', false},
+		{`"`, 0, true},
+		{";;", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDelimiter(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDelimiter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDelimiter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package csvio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* Describes how to read the CSV statement of one bank. Columns are matched by
+* header name (case-insensitive) or, when HasHeader is false, by 1-based position.
+ */
+type Profile struct {
+	Name              string `json:"name"`
+	DateColumn        string `json:"date_column"`
+	AmountColumn      string `json:"amount_column"`
+	DescriptionColumn string `json:"description_column"`
+	CategoryColumn    string `json:"category_column"`
+	DateFormat        string `json:"date_format"`
+	Delimiter         string `json:"delimiter"`
+	DecimalSeparator  string `json:"decimal_separator"`
+	NegativeIsExpense bool   `json:"negative_is_expense"`
+	HasHeader         bool   `json:"has_header"`
+}
+
+const (
+	DEFAULT_PROFILES_FILE_PATH = "./data/import_profiles.json"
+)
+
+/**
+* Returns the profile matching the tracker's own CSV export.
+ */
+func DefaultProfile() Profile {
+	return Profile{
+		Name:              "default",
+		DateColumn:        COLUMN_DATE,
+		AmountColumn:      COLUMN_AMOUNT,
+		DescriptionColumn: COLUMN_DESCRIPTION,
+		CategoryColumn:    COLUMN_CATEGORY,
+		DateFormat:        DEFAULT_DATE_FORMAT,
+		Delimiter:         string(DEFAULT_DELIMITER),
+		DecimalSeparator:  ".",
+		HasHeader:         true,
+	}
+}
+
+func ValidateProfile(profile Profile) error {
+	if profile.Name == "" {
+		return errors.New("profile name not set")
+	}
+
+	if profile.DateColumn == "" || profile.AmountColumn == "" {
+		return errors.New("date and amount columns must be set")
+	}
+
+	if profile.DecimalSeparator != "." && profile.DecimalSeparator != "," {
+		return errors.New("decimal separator must be '.' or ','")
+	}
+
+	if _, err := ParseDelimiter(profile.Delimiter); err != nil {
+		return err
+	}
+
+	if !profile.HasHeader {
+		for _, column := range []string{profile.DateColumn, profile.AmountColumn, profile.DescriptionColumn, profile.CategoryColumn} {
+			if column == "" {
+				continue
+			}
+			if n, err := strconv.Atoi(column); err != nil || n < 1 {
+				return errors.New("columns must be 1-based positions when the file has no header")
+			}
+		}
+	}
+
+	return nil
+}
+
+func GetProfiles() ([]Profile, error) {
+	data, err := storage.GetFileData(DEFAULT_PROFILES_FILE_PATH)
+	if err != nil {
+		return []Profile{}, err
+	}
+
+	if len(data) < 1 {
+		return []Profile{}, nil
+	}
+
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return []Profile{}, err
+	}
+
+	return profiles, nil
+}
+
+func GetProfile(name string) (Profile, error) {
+	profiles, err := GetProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Name, name) {
+			return profile, nil
+		}
+	}
+
+	return Profile{}, errors.New("import profile not found")
+}
+
+/**
+* Adds a profile or replaces the one with the same name.
+ */
+func SetProfile(profile Profile) error {
+	if err := ValidateProfile(profile); err != nil {
+		return err
+	}
+
+	profiles, err := GetProfiles()
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(profiles, func(p Profile) bool {
+		return strings.EqualFold(p.Name, profile.Name)
+	})
+	if idx == -1 {
+		profiles = append(profiles, profile)
+	} else {
+		profiles[idx] = profile
+	}
+
+	return saveProfiles(profiles)
+}
+
+func RemoveProfile(name string) error {
+	profiles, err := GetProfiles()
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(profiles, func(p Profile) bool {
+		return strings.EqualFold(p.Name, name)
+	})
+	if idx == -1 {
+		return errors.New("import profile not found")
+	}
+
+	profiles = append(profiles[:idx], profiles[idx+1:]...)
+
+	return saveProfiles(profiles)
+}
+
+/**
+* Reads a bank statement with the given profile. Every row is checked, so all
+* problems are reported at once instead of stopping at the first one.
+*
+* @param r The CSV statement.
+* @param profile The column mapping and number conventions of the statement.
+* @return The parsed transactions and the errors of the rows that could not be read.
+ */
+func ReadTransactions(r io.Reader, profile Profile) ([]importer.Transaction, []importer.RowError) {
+	delimiter, err := ParseDelimiter(profile.Delimiter)
+	if err != nil {
+		return nil, []importer.RowError{{Line: 0, Message: err.Error()}}
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+
+	var columns map[string]int
+	if !profile.HasHeader {
+		columns = positionalColumns(profile)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				rowErrors = append(rowErrors, importer.RowError{Line: 0, Message: err.Error()})
+				break
+			}
+
+			rowErrors = append(rowErrors, importer.RowError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if columns == nil {
+			columns, err = headerColumns(record, profile)
+			if err != nil {
+				return nil, []importer.RowError{{Line: line, Message: err.Error()}}
+			}
+			continue
+		}
+
+		if isBlankRecord(record) {
+			continue
+		}
+
+		tx, err := readTransaction(record, columns, profile)
+		if err != nil {
+			rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		tx.Line = line
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, rowErrors
+}
+
+/**
+* Parses an amount such as "-1.234,56" or "1,234.56" with the given decimal separator.
+ */
+func ParseAmount(value, decimalSeparator string) (float64, error) {
+	value = strings.TrimSpace(value)
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(value)
+
+	if decimalSeparator == "," {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	} else {
+		value = strings.ReplaceAll(value, ",", "")
+	}
+
+	// Some banks write negative amounts as "(12.50)" or "12.50-"
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = "-" + value[1:len(value)-1]
+	}
+	if strings.HasSuffix(value, "-") {
+		value = "-" + value[:len(value)-1]
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, errors.New("invalid amount")
+	}
+
+	return amount, nil
+}
+
+func readTransaction(record []string, columns map[string]int, profile Profile) (importer.Transaction, error) {
+	field := func(name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	dateValue := field(COLUMN_DATE)
+	date, err := time.Parse(ParseDateFormat(profile.DateFormat), dateValue)
+	if err != nil {
+		return importer.Transaction{}, errors.New("invalid date '" + dateValue + "'")
+	}
+
+	amountValue := field(COLUMN_AMOUNT)
+	amount, err := ParseAmount(amountValue, profile.DecimalSeparator)
+	if err != nil {
+		return importer.Transaction{}, errors.New("invalid amount '" + amountValue + "'")
+	}
+
+	isIncome := amount < 0
+	if profile.NegativeIsExpense {
+		isIncome = amount > 0
+	}
+
+	return importer.Transaction{
+		Date:        date,
+		Amount:      math.Abs(amount),
+		Description: field(COLUMN_DESCRIPTION),
+		Category:    field(COLUMN_CATEGORY),
+		IsIncome:    isIncome,
+	}, nil
+}
+
+func headerColumns(header []string, profile Profile) (map[string]int, error) {
+	columns := map[string]int{}
+	mapping := map[string]string{
+		COLUMN_DATE:        profile.DateColumn,
+		COLUMN_AMOUNT:      profile.AmountColumn,
+		COLUMN_DESCRIPTION: profile.DescriptionColumn,
+		COLUMN_CATEGORY:    profile.CategoryColumn,
+	}
+
+	for field, name := range mapping {
+		if name == "" {
+			continue
+		}
+
+		idx := slices.IndexFunc(header, func(h string) bool {
+			return strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), name)
+		})
+		if idx == -1 {
+			if field == COLUMN_DATE || field == COLUMN_AMOUNT {
+				return nil, errors.New("column '" + name + "' not found in header")
+			}
+			continue
+		}
+
+		columns[field] = idx
+	}
+
+	return columns, nil
+}
+
+func positionalColumns(profile Profile) map[string]int {
+	columns := map[string]int{}
+	mapping := map[string]string{
+		COLUMN_DATE:        profile.DateColumn,
+		COLUMN_AMOUNT:      profile.AmountColumn,
+		COLUMN_DESCRIPTION: profile.DescriptionColumn,
+		COLUMN_CATEGORY:    profile.CategoryColumn,
+	}
+
+	for field, position := range mapping {
+		if n, err := strconv.Atoi(position); err == nil && n > 0 {
+			columns[field] = n - 1
+		}
+	}
+
+	return columns
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func saveProfiles(profiles []Profile) error {
+	data, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.WriteFileData(DEFAULT_PROFILES_FILE_PATH, data); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package csvio
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bankProfile() Profile {
+	return Profile{
+		Name:              "bank",
+		DateColumn:        "Booking Date",
+		AmountColumn:      "Amount",
+		DescriptionColumn: "Text",
+		DateFormat:        "eu",
+		Delimiter:         ";",
+		DecimalSeparator:  ",",
+		NegativeIsExpense: true,
+		HasHeader:         true,
+	}
+}
+
+func TestReadTransactions(t *testing.T) {
+	statement := "\ufeffbooking date;Text;Amount\n" +
+		"12.09.2025;\"Rent; September\";-1.234,50\n" +
+		"\n" +
+		"13.09.2025;Uber;-12,00\n" +
+		"14.09.2025;Salary;2.000,00\n"
+
+	transactions, rowErrors := ReadTransactions(strings.NewReader(statement), bankProfile())
+	if len(rowErrors) != 0 {
+		t.Fatalf("ReadTransactions() errors = %v", rowErrors)
+	}
+
+	if len(transactions) != 3 {
+		t.Fatalf("ReadTransactions() count = %v, want 3", len(transactions))
+	}
+
+	rent := transactions[0]
+	if rent.Description != "Rent; September" || rent.Amount != 1234.5 || rent.IsIncome {
+		t.Errorf("ReadTransactions() rent = %+v", rent)
+	}
+	if !rent.Date.Equal(time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ReadTransactions() date = %v", rent.Date)
+	}
+	if rent.Line != 2 {
+		t.Errorf("ReadTransactions() line = %v, want 2", rent.Line)
+	}
+
+	if transactions[1].Line != 4 {
+		t.Errorf("ReadTransactions() line after a blank row = %v, want 4", transactions[1].Line)
+	}
+
+	if !transactions[2].IsIncome || transactions[2].Amount != 2000 {
+		t.Errorf("ReadTransactions() salary = %+v", transactions[2])
+	}
+}
+
+func TestReadTransactionsRowErrors(t *testing.T) {
+	statement := "Booking Date;Text;Amount\n" +
+		"31.02.2025;Bad date;-1,00\n" +
+		"01.03.2025;Fine;-2,00\n" +
+		"02.03.2025;Bad amount;twelve\n"
+
+	transactions, rowErrors := ReadTransactions(strings.NewReader(statement), bankProfile())
+	if len(rowErrors) != 2 {
+		t.Fatalf("ReadTransactions() errors = %v, want 2", rowErrors)
+	}
+
+	if rowErrors[0].Line != 2 || rowErrors[1].Line != 4 {
+		t.Errorf("ReadTransactions() error lines = %v", rowErrors)
+	}
+
+	if len(transactions) != 1 {
+		t.Errorf("ReadTransactions() count = %v, want 1", len(transactions))
+	}
+}
+
+func TestReadTransactionsMissingColumn(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("Date;Text\n"), bankProfile())
+	if len(rowErrors) != 1 {
+		t.Errorf("ReadTransactions() should report the missing column, got %v", rowErrors)
+	}
+}
+
+func TestReadTransactionsWithoutHeader(t *testing.T) {
+	profile := Profile{
+		Name:              "positional",
+		DateColumn:        "2",
+		AmountColumn:      "3",
+		DescriptionColumn: "1",
+		DateFormat:        "2006-01-02",
+		Delimiter:         ",",
+		DecimalSeparator:  ".",
+	}
+
+	transactions, rowErrors := ReadTransactions(strings.NewReader("Lidl,2025-09-01,23.10\nRefund,2025-09-02,-5\n"), profile)
+	if len(rowErrors) != 0 {
+		t.Fatalf("ReadTransactions() errors = %v", rowErrors)
+	}
+
+	if len(transactions) != 2 || transactions[0].Description != "Lidl" || transactions[0].Amount != 23.1 {
+		t.Errorf("ReadTransactions() = %+v", transactions)
+	}
+
+	if !transactions[1].IsIncome {
+		t.Errorf("ReadTransactions() negative amount should be income when negative is not expense")
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		decimal string
+		want    float64
+		wantErr bool
+	}{
+		{"12.50", ".", 12.5, false},
+		{"1,234.56", ".", 1234.56, false},
+		{"-1.234,56", ",", -1234.56, false},
+		{"1 234,56", ",", 1234.56, false},
+		{"(12.50)", ".", -12.5, false},
+		{"12.50-", ".", -12.5, false},
+		{"", ".", 0, true},
+		{"NaN", ".", 0, true},
+		{"abc", ".", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAmount(tt.value, tt.decimal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if err := SetProfile(bankProfile()); err != nil {
+		t.Fatalf("SetProfile() error = %v", err)
+	}
+
+	updated := bankProfile()
+	updated.Delimiter = ","
+	if err := SetProfile(updated); err != nil {
+		t.Fatalf("SetProfile() error = %v", err)
+	}
+
+	profiles, _ := GetProfiles()
+	if len(profiles) != 1 {
+		t.Errorf("SetProfile() should replace a profile with the same name, got %v", len(profiles))
+	}
+
+	profile, err := GetProfile("BANK")
+	if err != nil || profile.Delimiter != "," {
+		t.Errorf("GetProfile() = %+v, %v", profile, err)
+	}
+
+	invalid := bankProfile()
+	invalid.DecimalSeparator = ";"
+	if err := SetProfile(invalid); err == nil {
+		t.Errorf("SetProfile() should fail for an invalid decimal separator")
+	}
+
+	positional := bankProfile()
+	positional.HasHeader = false
+	if err := SetProfile(positional); err == nil {
+		t.Errorf("SetProfile() should fail for named columns without a header")
+	}
+
+	if err := RemoveProfile("bank"); err != nil {
+		t.Errorf("RemoveProfile() error = %v", err)
+	}
+	if _, err := GetProfile("bank"); err == nil {
+		t.Errorf("GetProfile() should fail after removal")
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
@@ -0,0 +1,401 @@
+package duplicates
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* Two expenses that look like the same payment. Original is the one that was
+* recorded first, i.e. the one with the lower ID.
+ */
+type Pair struct {
+	Original   expense.Expense
+	Duplicate  expense.Expense
+	Similarity float64
+}
+
+/**
+* A pair the user has reviewed and kept as two separate expenses.
+ */
+type Dismissal struct {
+	First  int `json:"first"`
+	Second int `json:"second"`
+}
+
+type Options struct {
+	WindowDays    int
+	MinSimilarity float64
+}
+
+const (
+	DEFAULT_DUPLICATES_FILE_PATH = "./data/dismissed_duplicates.json"
+	DEFAULT_WINDOW_DAYS          = 3
+	DEFAULT_MIN_SIMILARITY       = 0.6
+	AMOUNT_TOLERANCE             = 0.005
+)
+
+func DefaultOptions() Options {
+	return Options{
+		WindowDays:    DEFAULT_WINDOW_DAYS,
+		MinSimilarity: DEFAULT_MIN_SIMILARITY,
+	}
+}
+
+/**
+* Reports whether two expenses look like the same payment: the same amount
+* in the same direction, dates at most WindowDays apart and similar
+* descriptions. Deleted expenses are never duplicates.
+*
+* @return The similarity of the descriptions and whether the expenses are duplicate candidates.
+ */
+func IsCandidate(a, b expense.Expense, options Options) (float64, bool) {
+	if a.IsDeleted || b.IsDeleted || a.IsIncome != b.IsIncome {
+		return 0, false
+	}
+
+	if math.Abs(a.Amount-b.Amount) > AMOUNT_TOLERANCE {
+		return 0, false
+	}
+
+	if daysApart(a.Date, b.Date) > options.WindowDays {
+		return 0, false
+	}
+
+	similarity := Similarity(a.Description, b.Description)
+
+	return similarity, similarity >= options.MinSimilarity
+}
+
+/**
+* Finds the expenses a new expense may duplicate, most similar first.
+*
+* @param existing The expenses recorded so far.
+* @param exp The new expense, which may or may not be part of existing already.
+ */
+func FindCandidates(existing []expense.Expense, exp expense.Expense, options Options) []Pair {
+	pairs := []Pair{}
+
+	for _, other := range existing {
+		if other.ID == exp.ID {
+			continue
+		}
+
+		if similarity, ok := IsCandidate(other, exp, options); ok {
+			pairs = append(pairs, newPair(other, exp, similarity))
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Similarity > pairs[j].Similarity
+	})
+
+	return pairs
+}
+
+/**
+* Finds every pair of duplicate candidates among the expenses, leaving out
+* the pairs that were dismissed.
+*
+* @return The pairs, ordered by the IDs of their expenses.
+ */
+func FindPairs(expenses []expense.Expense, dismissals []Dismissal, options Options) []Pair {
+	active := []expense.Expense{}
+	for _, exp := range expenses {
+		if !exp.IsDeleted {
+			active = append(active, exp)
+		}
+	}
+
+	// With the expenses in date order, only the ones inside the window need comparing
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].Date.Before(active[j].Date)
+	})
+
+	pairs := []Pair{}
+	for i, a := range active {
+		for _, b := range active[i+1:] {
+			if daysApart(a.Date, b.Date) > options.WindowDays {
+				break
+			}
+
+			similarity, ok := IsCandidate(a, b, options)
+			if !ok || IsDismissed(dismissals, a.ID, b.ID) {
+				continue
+			}
+
+			pairs = append(pairs, newPair(a, b, similarity))
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Original.ID != pairs[j].Original.ID {
+			return pairs[i].Original.ID < pairs[j].Original.ID
+		}
+		return pairs[i].Duplicate.ID < pairs[j].Duplicate.ID
+	})
+
+	return pairs
+}
+
+/**
+* Scores how alike two descriptions are, from 0 to 1. Case, punctuation and
+* numbers such as references or store numbers are ignored. The score is the
+* higher of the share of the shorter description's words found in the other
+* one ("Uber" and "UBER *TRIP HELP.UBER.COM") and the overlap of letter pairs,
+* which tolerates typos ("Starbucks" and "Starbuck's").
+ */
+func Similarity(a, b string) float64 {
+	wordsA, wordsB := words(a), words(b)
+
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	return max(wordOverlap(wordsA, wordsB), letterPairOverlap(strings.Join(wordsA, " "), strings.Join(wordsB, " ")))
+}
+
+func GetDismissals() ([]Dismissal, error) {
+	data, err := storage.GetFileData(DEFAULT_DUPLICATES_FILE_PATH)
+	if err != nil {
+		return []Dismissal{}, err
+	}
+
+	if len(data) < 1 {
+		return []Dismissal{}, nil
+	}
+
+	dismissals := []Dismissal{}
+	if err := json.Unmarshal(data, &dismissals); err != nil {
+		return []Dismissal{}, err
+	}
+
+	return dismissals, nil
+}
+
+func IsDismissed(dismissals []Dismissal, a, b int) bool {
+	return slices.Contains(dismissals, newDismissal(a, b))
+}
+
+/**
+* Remembers that two expenses are not duplicates, so the pair is not
+* reported again.
+ */
+func Dismiss(a, b int) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	if err := checkPair(expenses, a, b); err != nil {
+		return err
+	}
+
+	dismissals, err := GetDismissals()
+	if err != nil {
+		return err
+	}
+
+	if IsDismissed(dismissals, a, b) {
+		return nil
+	}
+
+	dismissals = append(dismissals, newDismissal(a, b))
+
+	data, err := json.Marshal(dismissals)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_DUPLICATES_FILE_PATH, data)
+}
+
+/**
+* Merges a duplicate into the expense that is kept. The kept expense takes
+* over whatever it is missing—category, notes, external ID, value date and
+* counterparty—and the tags of both. The duplicate is deleted, as with
+* `delete`, so that IDs stay stable.
+*
+* @param keepID The ID of the expense that is kept.
+* @param dropID The ID of the duplicate.
+* @return The merged expense, or an error if either ID is unknown or deleted.
+ */
+func Merge(keepID, dropID int) (expense.Expense, error) {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return expense.Expense{}, err
+	}
+
+	if err := checkPair(expenses, keepID, dropID); err != nil {
+		return expense.Expense{}, err
+	}
+
+	merged := mergeExpenses(expenses[keepID], expenses[dropID])
+	expenses[keepID] = merged
+	expenses[dropID].IsDeleted = true
+
+	if err := expense.SaveExpenses(expenses); err != nil {
+		return expense.Expense{}, err
+	}
+
+	return merged, nil
+}
+
+func mergeExpenses(keep, drop expense.Expense) expense.Expense {
+	if keep.Description == "" {
+		keep.Description = drop.Description
+	}
+
+	if keep.Category == "" {
+		keep.Category = drop.Category
+	}
+
+	tags := slices.Clone(keep.Tags)
+	for _, tag := range drop.Tags {
+		if !slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			tags = append(tags, tag)
+		}
+	}
+	keep.Tags = tags
+
+	switch {
+	case keep.Notes == "":
+		keep.Notes = drop.Notes
+	case drop.Notes != "" && drop.Notes != keep.Notes:
+		keep.Notes += "\n" + drop.Notes
+	}
+
+	if keep.ExternalID == "" {
+		keep.ExternalID = drop.ExternalID
+	}
+
+	if keep.ValueDate.IsZero() {
+		keep.ValueDate = drop.ValueDate
+	}
+
+	if keep.Counterparty == "" {
+		keep.Counterparty = drop.Counterparty
+	}
+
+	return keep
+}
+
+func checkPair(expenses []expense.Expense, a, b int) error {
+	if a == b {
+		return errors.New("an expense cannot duplicate itself")
+	}
+
+	for _, id := range []int{a, b} {
+		if id < 0 || id >= len(expenses) {
+			return errors.New("cannot find expense with provided id")
+		}
+		if expenses[id].IsDeleted {
+			return errors.New("expense with provided id is deleted")
+		}
+	}
+
+	return nil
+}
+
+func newPair(a, b expense.Expense, similarity float64) Pair {
+	if b.ID < a.ID {
+		a, b = b, a
+	}
+
+	return Pair{Original: a, Duplicate: b, Similarity: similarity}
+}
+
+func newDismissal(a, b int) Dismissal {
+	return Dismissal{First: min(a, b), Second: max(a, b)}
+}
+
+func daysApart(a, b time.Time) int {
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(math.Abs(dayA.Sub(dayB).Hours()) / 24)
+}
+
+/**
+* Splits a description into lowercase words, dropping words made of digits only.
+ */
+func words(value string) []string {
+	result := []string{}
+
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, field := range fields {
+		if strings.IndexFunc(field, unicode.IsLetter) == -1 {
+			continue
+		}
+		result = append(result, field)
+	}
+
+	return result
+}
+
+func wordOverlap(a, b []string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	found := 0
+	for _, word := range a {
+		if slices.Contains(b, word) {
+			found++
+		}
+	}
+
+	return float64(found) / float64(len(a))
+}
+
+/**
+* Returns the Dice coefficient of the letter pairs of two strings.
+ */
+func letterPairOverlap(a, b string) float64 {
+	pairsA, pairsB := letterPairs(a), letterPairs(b)
+	if len(pairsA) == 0 || len(pairsB) == 0 {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+
+	counts := map[string]int{}
+	for _, pair := range pairsA {
+		counts[pair]++
+	}
+
+	shared := 0
+	for _, pair := range pairsB {
+		if counts[pair] > 0 {
+			counts[pair]--
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(pairsA)+len(pairsB))
+}
+
+func letterPairs(value string) []string {
+	runes := []rune(strings.ReplaceAll(value, " ", ""))
+	pairs := []string{}
+	for i := 0; i+1 < len(runes); i++ {
+		pairs = append(pairs, string(runes[i:i+2]))
+	}
+
+	return pairs
+}
@@ -0,0 +1,150 @@
+package duplicates
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func day(d int) time.Time {
+	return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{"Uber", "UBER *TRIP HELP.UBER.COM", 1},
+		{"Starbucks", "Starbuck's", 1},
+		{"REWE 1234", "rewe", 1},
+		{"", "", 1},
+		{"Rent", "", 0},
+		{"Netflix", "Spotify", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); got != tt.want {
+				t.Errorf("Similarity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := Similarity("Amazon Marketplace", "Amazn Marketplace"); got < DEFAULT_MIN_SIMILARITY || got >= 1 {
+		t.Errorf("Similarity() for a typo = %v", got)
+	}
+}
+
+func TestIsCandidate(t *testing.T) {
+	base := expense.Expense{ID: 0, Amount: 12.5, Date: day(10), Description: "Uber"}
+
+	tests := []struct {
+		name  string
+		other expense.Expense
+		want  bool
+	}{
+		{"Same payment two days later", expense.Expense{ID: 1, Amount: 12.5, Date: day(12), Description: "UBER TRIP"}, true},
+		{"Different amount", expense.Expense{ID: 1, Amount: 12.51, Date: day(10), Description: "Uber"}, false},
+		{"Outside the window", expense.Expense{ID: 1, Amount: 12.5, Date: day(14), Description: "Uber"}, false},
+		{"Different description", expense.Expense{ID: 1, Amount: 12.5, Date: day(10), Description: "Lidl"}, false},
+		{"Income", expense.Expense{ID: 1, Amount: 12.5, Date: day(10), Description: "Uber", IsIncome: true}, false},
+		{"Deleted", expense.Expense{ID: 1, Amount: 12.5, Date: day(10), Description: "Uber", IsDeleted: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := IsCandidate(base, tt.other, DefaultOptions()); got != tt.want {
+				t.Errorf("IsCandidate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPairs(t *testing.T) {
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 12.5, Date: day(10), Description: "Uber"},
+		{ID: 1, Amount: 40, Date: day(10), Description: "Lidl"},
+		{ID: 2, Amount: 12.5, Date: day(11), Description: "UBER *TRIP"},
+		{ID: 3, Amount: 40, Date: day(20), Description: "Lidl"},
+		{ID: 4, Amount: 9, Date: day(21), Description: "Coffee"},
+		{ID: 5, Amount: 9, Date: day(21), Description: "Coffee"},
+		{ID: 6, Amount: 12.5, Date: day(9), Description: "Uber", IsDeleted: true},
+	}
+
+	pairs := FindPairs(expenses, []Dismissal{{First: 4, Second: 5}}, DefaultOptions())
+	if len(pairs) != 1 || pairs[0].Original.ID != 0 || pairs[0].Duplicate.ID != 2 {
+		t.Errorf("FindPairs() = %+v", pairs)
+	}
+
+	candidates := FindCandidates(expenses, expense.Expense{ID: 7, Amount: 9, Date: day(22), Description: "coffee"}, DefaultOptions())
+	if len(candidates) != 2 || candidates[0].Duplicate.ID != 7 {
+		t.Errorf("FindCandidates() = %+v", candidates)
+	}
+}
+
+func TestMergeAndDismiss(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 12.5, Date: day(10), Description: "Uber", Tags: []string{"work"}, Notes: "airport"},
+		{ID: 1, Amount: 12.5, Date: day(11), Description: "UBER *TRIP", Category: "Transport", Tags: []string{"Work", "late"}, ExternalID: "ofx:1:A", Notes: "card"},
+		{ID: 2, Amount: 12.5, Date: day(11), Description: "Uber"},
+	}
+	data, _ := json.Marshal(expenses)
+	os.WriteFile("./data/expenses.json", data, 0755)
+
+	if err := Dismiss(2, 0); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	if err := Dismiss(0, 2); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	dismissals, _ := GetDismissals()
+	if len(dismissals) != 1 || dismissals[0] != (Dismissal{First: 0, Second: 2}) {
+		t.Errorf("GetDismissals() = %+v", dismissals)
+	}
+
+	merged, err := Merge(0, 1)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if merged.Category != "Transport" || merged.ExternalID != "ofx:1:A" || merged.Notes != "airport\ncard" || len(merged.Tags) != 2 || merged.Description != "Uber" {
+		t.Errorf("Merge() = %+v", merged)
+	}
+
+	stored, _ := expense.GetExpenses()
+	if !stored[1].IsDeleted || stored[0].Category != "Transport" {
+		t.Errorf("Merge() stored = %+v", stored)
+	}
+
+	if _, err := Merge(0, 1); err == nil {
+		t.Errorf("Merge() should fail for a deleted expense")
+	}
+	if _, err := Merge(0, 0); err == nil {
+		t.Errorf("Merge() should fail for the same expense")
+	}
+	if err := Dismiss(0, 9); err == nil {
+		t.Errorf("Dismiss() should fail for an unknown expense")
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
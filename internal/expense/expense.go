@@ -3,20 +3,27 @@ package expense
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
 )
 
 type Expense struct {
-	Amount      float64   `json:"amount"`
-	Date        time.Time `json:"date"`
-	ID          int       `json:"id"`
-	Month       int       `json:"month"`
-	IsDeleted   bool      `json:"is_deleted"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
+	Amount       float64   `json:"amount"`
+	Date         time.Time `json:"date"`
+	ID           int       `json:"id"`
+	Month        int       `json:"month"`
+	IsDeleted    bool      `json:"is_deleted"`
+	Description  string    `json:"description"`
+	Category     string    `json:"category"`
+	Tags         []string  `json:"tags,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	IsIncome     bool      `json:"is_income,omitempty"`
+	ExternalID   string    `json:"external_id,omitempty"`
+	ValueDate    time.Time `json:"value_date,omitzero"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
 }
 
 const (
@@ -42,6 +49,14 @@ func CreateExpenseObj(amount float64, desc, category string) (Expense, error) {
 	}, nil
 }
 
+/**
+* Reports whether an expense counts as spending: deleted expenses and
+* incoming payments never do.
+ */
+func IsSpending(exp Expense) bool {
+	return !exp.IsDeleted && !exp.IsIncome
+}
+
 func GetExpenses() ([]Expense, error) {
 	fileData, err := storage.GetFileData(EXPENSES_FILE_PATH)
 	if err != nil {
@@ -66,7 +81,7 @@ func GetExpense(id int) (Expense, error) {
 		return Expense{}, err
 	}
 
-	if id > len(expenses) || id < 0 {
+	if id >= len(expenses) || id < 0 {
 		return Expense{}, errors.New("cannot find expense with provided id")
 	}
 
@@ -110,27 +125,36 @@ func AddExpense(exp Expense) error {
 	return nil
 }
 
+func SaveExpenses(expenses []Expense) error {
+	data, err := json.Marshal(expenses)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.WriteFileData(EXPENSES_FILE_PATH, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func DeleteExpense(id int) error {
 	expenses, err := GetExpenses()
 	if err != nil {
 		return err
 	}
 
-	if id > len(expenses) || id < 0 {
+	if id >= len(expenses) || id < 0 {
 		return errors.New("cannot find expense with provided id")
 	}
 
 	expenses[id].IsDeleted = true
 
-	fmt.Println(expenses)
-
 	data, err := json.Marshal(expenses)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(string(data))
-
 	if err := storage.WriteFileData(EXPENSES_FILE_PATH, data); err != nil {
 		return err
 	}
@@ -144,7 +168,7 @@ func UpdateExpense(id int, amount float64, desc, category string) error {
 		return err
 	}
 
-	if id > len(expenses) || id < 0 {
+	if id >= len(expenses) || id < 0 {
 		return errors.New("cannot find expense with provided id")
 	}
 
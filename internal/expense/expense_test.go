@@ -154,6 +154,11 @@ func TestGetExpense(t *testing.T) {
 			id:      10,
 			wantErr: true,
 		},
+		{
+			name:    "Invalid ID - one past the last",
+			id:      1,
+			wantErr: true,
+		},
 		{
 			name:    "Invalid ID - negative",
 			id:      -1,
@@ -318,6 +323,11 @@ func TestDeleteExpense(t *testing.T) {
 			id:      10,
 			wantErr: true,
 		},
+		{
+			name:    "Invalid ID - one past the last",
+			id:      1,
+			wantErr: true,
+		},
 		{
 			name:    "Invalid ID - negative",
 			id:      -1,
@@ -392,6 +402,14 @@ func TestUpdateExpense(t *testing.T) {
 			category:    "Transport",
 			wantErr:     true,
 		},
+		{
+			name:        "Invalid ID - one past the last",
+			id:          1,
+			amount:      200.0,
+			description: "Updated expense",
+			category:    "Transport",
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
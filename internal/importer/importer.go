@@ -0,0 +1,168 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* A transaction read from a statement, before it becomes an expense.
+* Amount is always positive; IsIncome marks money coming in. Date is the
+* booking date; ValueDate and Counterparty are only set by formats that carry
+* them. ExternalID is the bank's own transaction reference, when the
+* statement format has one.
+ */
+type Transaction struct {
+	Line         int
+	Date         time.Time
+	ValueDate    time.Time
+	Amount       float64
+	Description  string
+	Counterparty string
+	Category     string
+	Tags         []string
+	Notes        string
+	IsIncome     bool
+	ExternalID   string
+}
+
+/**
+* A problem with a single statement row. Any row error aborts the import.
+ */
+type RowError struct {
+	Line    int
+	Message string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+type Skipped struct {
+	Line        int
+	Description string
+	Reason      string
+}
+
+type Result struct {
+	Expenses   []expense.Expense
+	Skipped    []Skipped
+	Duplicates []duplicates.Pair
+	categories []category.Category
+	existing   []expense.Expense
+}
+
+/**
+* Turns transactions into new expenses without writing anything: IDs continue
+* after the existing expenses, transactions without a category go through the
+* categorisation rules, and categories are mapped onto the registry.
+* Transactions whose external ID was already imported are skipped, and new
+* expenses that look like one recorded before are flagged as possible duplicates.
+*
+* @param transactions The parsed statement rows.
+* @return The expenses that would be added, the rows that would be skipped and the possible duplicates, or an error if stored data cannot be read.
+ */
+func Prepare(transactions []Transaction) (Result, error) {
+	existing, err := expense.GetExpenses()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allRules, err := rules.GetRules()
+	if err != nil {
+		return Result{}, err
+	}
+
+	registry, err := category.GetCategories()
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{existing: existing, categories: registry}
+
+	imported := map[string]bool{}
+	for _, exp := range existing {
+		if exp.ExternalID != "" {
+			imported[exp.ExternalID] = true
+		}
+	}
+
+	for _, tx := range transactions {
+		if tx.ExternalID != "" && imported[tx.ExternalID] {
+			result.Skipped = append(result.Skipped, Skipped{
+				Line:        tx.Line,
+				Description: tx.Description,
+				Reason:      "already imported",
+			})
+			continue
+		}
+
+		exp := expense.Expense{
+			ID:           len(existing) + len(result.Expenses),
+			Amount:       tx.Amount,
+			Date:         tx.Date.UTC(),
+			Month:        int(tx.Date.Month()),
+			Description:  strings.TrimSpace(tx.Description),
+			Category:     category.Normalize(tx.Category),
+			Tags:         tx.Tags,
+			Notes:        strings.TrimSpace(tx.Notes),
+			IsIncome:     tx.IsIncome,
+			ExternalID:   tx.ExternalID,
+			ValueDate:    tx.ValueDate.UTC(),
+			Counterparty: strings.TrimSpace(tx.Counterparty),
+		}
+
+		if tx.ExternalID != "" {
+			imported[tx.ExternalID] = true
+		}
+
+		// Rules describe spending, so incomes are left to the user
+		if exp.Category == "" && !exp.IsIncome {
+			exp, _ = rules.Apply(allRules, exp)
+		}
+
+		if exp.Category != "" {
+			exp.Category = category.Resolve(result.categories, exp.Category)
+			result.categories, _ = category.WithCategory(result.categories, exp.Category)
+		}
+
+		result.Expenses = append(result.Expenses, exp)
+		result.Duplicates = append(result.Duplicates, duplicates.FindCandidates(existing, exp, duplicates.DefaultOptions())...)
+	}
+
+	return result, nil
+}
+
+/**
+* Writes the prepared expenses and any new categories in one step,
+* so a failed import never leaves half of a statement behind.
+ */
+func Commit(result Result) error {
+	if len(result.Expenses) < 1 {
+		return nil
+	}
+
+	expensesData, err := json.Marshal(append(result.existing, result.Expenses...))
+	if err != nil {
+		return err
+	}
+
+	category.SortCategories(result.categories)
+	categoriesData, err := json.Marshal(result.categories)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFilesData(map[string][]byte{
+		expense.EXPENSES_FILE_PATH:          expensesData,
+		category.DEFAULT_CATEGORY_FILE_PATH: categoriesData,
+	})
+}
@@ -0,0 +1,125 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+)
+
+func TestPrepareAndCommit(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	existing := []expense.Expense{{ID: 0, Amount: 1, Description: "Old", Category: "Food", ExternalID: "ofx:1:A"}}
+	data, _ := json.Marshal(existing)
+	os.WriteFile("./data/expenses.json", data, 0755)
+	os.WriteFile("./data/categories.json", []byte(`[{"name":"Food"}]`), 0755)
+
+	rule := rules.NewRule()
+	rule.Contains = "uber"
+	rule.Category = "Transport"
+	if _, err := rules.AddRule(rule); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+
+	transactions := []Transaction{
+		{Line: 2, Date: time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), Amount: 12, Description: " Uber ride "},
+		{Line: 3, Date: time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC), Amount: 30, Description: "Lidl", Category: "food:groceries"},
+		{Line: 4, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Amount: 2000, Description: "Uber driver payout", IsIncome: true, ExternalID: "ofx:1:B"},
+		{Line: 5, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Amount: 2000, Description: "Uber driver payout", IsIncome: true, ExternalID: "ofx:1:B"},
+		{Line: 6, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Amount: 1, Description: "Old", ExternalID: "ofx:1:A"},
+	}
+
+	result, err := Prepare(transactions)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	if len(result.Expenses) != 3 || len(result.Skipped) != 2 {
+		t.Fatalf("Prepare() expenses = %v, skipped = %v", len(result.Expenses), len(result.Skipped))
+	}
+
+	uber := result.Expenses[0]
+	if uber.ID != 1 || uber.Category != "Transport" || uber.Description != "Uber ride" || uber.Month != 9 {
+		t.Errorf("Prepare() uber = %+v", uber)
+	}
+
+	if result.Expenses[1].ID != 2 || result.Expenses[1].Category != "Food:groceries" {
+		t.Errorf("Prepare() lidl = %+v", result.Expenses[1])
+	}
+
+	salary := result.Expenses[2]
+	if !salary.IsIncome || salary.ExternalID != "ofx:1:B" || salary.Category != "" {
+		t.Errorf("Prepare() salary = %+v", salary)
+	}
+
+	for _, skipped := range result.Skipped {
+		if skipped.Reason != "already imported" {
+			t.Errorf("Prepare() skipped = %+v", skipped)
+		}
+	}
+
+	stored, _ := expense.GetExpenses()
+	if len(stored) != 1 {
+		t.Errorf("Prepare() should not write anything")
+	}
+
+	if err := Commit(result); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	stored, _ = expense.GetExpenses()
+	if len(stored) != 4 {
+		t.Errorf("Commit() stored = %v, want 4", len(stored))
+	}
+
+	categories, _ := category.GetCategories()
+	if len(categories) != 3 {
+		t.Errorf("Commit() categories = %+v, want Food, Food:groceries and Transport", categories)
+	}
+}
+
+func TestPrepareFlagsDuplicates(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	existing := []expense.Expense{{ID: 0, Amount: 30, Date: time.Date(2025, 9, 12, 18, 0, 0, 0, time.UTC), Description: "Lidl"}}
+	data, _ := json.Marshal(existing)
+	os.WriteFile("./data/expenses.json", data, 0755)
+
+	result, err := Prepare([]Transaction{
+		{Line: 2, Date: time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC), Amount: 30, Description: "LIDL DIENSTLEISTUNG 4711"},
+		{Line: 3, Date: time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC), Amount: 31, Description: "Lidl"},
+	})
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	if len(result.Expenses) != 2 || len(result.Duplicates) != 1 {
+		t.Fatalf("Prepare() expenses = %v, duplicates = %+v", len(result.Expenses), result.Duplicates)
+	}
+
+	if result.Duplicates[0].Original.ID != 0 || result.Duplicates[0].Duplicate.ID != 1 {
+		t.Errorf("Prepare() duplicates = %+v", result.Duplicates)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
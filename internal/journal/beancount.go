@@ -0,0 +1,279 @@
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	BEANCOUNT_DATE_FORMAT = "2006-01-02"
+	BEANCOUNT_CURRENCY    = "USD"
+	BEANCOUNT_INDENT      = "  "
+)
+
+var (
+	beancountHeader = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2})\s+(\*|!|txn)(?:\s+(.*))?$`)
+	beancountMeta   = regexp.MustCompile(`^([a-z][A-Za-z0-9_-]*):\s*(.*)$`)
+	beancountString = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+)
+
+/**
+* Writes expenses as a beancount ledger. Accounts are opened on the date of
+* the first expense, and category names are turned into valid account names
+* (e.g. "Food:fast food" becomes "Expenses:Food:Fast-food"); the original
+* category is kept as metadata whenever the account cannot carry it.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param expenses The expenses to write, already filtered by the caller.
+* @param fundingAccount The account expenses are paid from and incomes paid into, e.g. "Assets:Checking".
+* @return An error if writing fails; otherwise, nil.
+ */
+func WriteBeancount(w io.Writer, expenses []expense.Expense, fundingAccount string) error {
+	writer := bufio.NewWriter(w)
+	fundingAccount = beancountAccount(fundingAccount)
+
+	fmt.Fprintf(writer, "option \"operating_currency\" \"%s\"\n", BEANCOUNT_CURRENCY)
+
+	if len(expenses) > 0 {
+		opened := expenses[0].Date
+		accounts := []string{fundingAccount}
+		for _, exp := range expenses {
+			if exp.Date.Before(opened) {
+				opened = exp.Date
+			}
+			if account := beancountAccount(categoryAccount(exp)); !slices.Contains(accounts, account) {
+				accounts = append(accounts, account)
+			}
+		}
+
+		fmt.Fprintln(writer)
+		for _, account := range accounts {
+			fmt.Fprintf(writer, "%s open %s\n", opened.Format(BEANCOUNT_DATE_FORMAT), account)
+		}
+	}
+
+	for _, exp := range expenses {
+		account := beancountAccount(categoryAccount(exp))
+
+		fmt.Fprintf(writer, "\n%s * %s", exp.Date.Format(BEANCOUNT_DATE_FORMAT), quote(exp.Description))
+		for _, tag := range exp.Tags {
+			fmt.Fprintf(writer, " #%s", tagName(tag, isBeancountTagRune))
+		}
+		fmt.Fprintln(writer)
+
+		for _, meta := range expenseMeta(exp, account) {
+			fmt.Fprintf(writer, "%s%s: %s\n", BEANCOUNT_INDENT, meta[0], quote(meta[1]))
+		}
+
+		amount := strconv.FormatFloat(exp.Amount, 'f', 2, 64) + " " + BEANCOUNT_CURRENCY
+		debit, credit := account, fundingAccount
+		if exp.IsIncome {
+			debit, credit = fundingAccount, account
+		}
+
+		fmt.Fprintf(writer, "%s%-40s  %s\n", BEANCOUNT_INDENT, debit, amount)
+		fmt.Fprintf(writer, "%s%s\n", BEANCOUNT_INDENT, credit)
+	}
+
+	return writer.Flush()
+}
+
+/**
+* Reads the transactions of a beancount ledger. Other directives, such as
+* "open", "balance" or "price", are skipped.
+*
+* @param r The beancount file.
+* @return The parsed transactions and the errors of the transactions that could not be read.
+ */
+func ReadBeancount(r io.Reader) ([]importer.Transaction, []importer.RowError) {
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+
+	scanner := bufio.NewScanner(r)
+	var current *entry
+	var currentErr error
+	line := 0
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		if currentErr == nil {
+			read, err := entryTransactions(*current)
+			if err == nil {
+				transactions = append(transactions, read...)
+			}
+			currentErr = err
+		}
+
+		if currentErr != nil {
+			rowErrors = append(rowErrors, importer.RowError{Line: current.line, Message: currentErr.Error()})
+		}
+
+		current, currentErr = nil, nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		if !unicode.IsSpace(rune(text[0])) {
+			flush()
+
+			match := beancountHeader.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+
+			current = &entry{line: line, meta: map[string]string{}}
+			readBeancountHeader(current, match[3])
+
+			date, err := time.Parse(BEANCOUNT_DATE_FORMAT, match[1])
+			if err != nil {
+				currentErr = errors.New("invalid date '" + match[1] + "'")
+				continue
+			}
+			current.date = date
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if match := beancountMeta.FindStringSubmatch(trimmed); match != nil {
+			current.meta[match[1]] = unquote(match[2])
+			continue
+		}
+
+		p, err := readBeancountPosting(trimmed)
+		if err != nil && currentErr == nil {
+			currentErr = err
+		}
+		current.postings = append(current.postings, p)
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+	}
+
+	return transactions, rowErrors
+}
+
+/**
+* Reads the payee, narration and #tags after the flag of a transaction.
+* With a single string it is the narration.
+ */
+func readBeancountHeader(e *entry, rest string) {
+	quoted := beancountString.FindAllString(rest, -1)
+	switch len(quoted) {
+	case 0:
+	case 1:
+		e.description = unquote(quoted[0])
+	default:
+		e.payee = unquote(quoted[0])
+		e.description = unquote(quoted[1])
+	}
+
+	for _, word := range strings.Fields(stripBeancountComment(beancountString.ReplaceAllString(rest, " "))) {
+		if len(word) > 1 && word[0] == '#' {
+			e.tags = append(e.tags, word[1:])
+		}
+	}
+}
+
+/**
+* Reads a posting such as "Expenses:Food  12.50 USD {1.1 EUR}" or "Assets:Checking".
+ */
+func readBeancountPosting(text string) (posting, error) {
+	fields := strings.Fields(stripBeancountComment(text))
+	if len(fields) > 0 && (fields[0] == "*" || fields[0] == "!") {
+		fields = fields[1:]
+	}
+
+	if len(fields) < 1 {
+		return posting{}, errors.New("invalid posting '" + text + "'")
+	}
+
+	p := posting{account: fields[0]}
+	if len(fields) < 2 {
+		return p, nil
+	}
+
+	value, err := parseAmount(strings.Join(fields[1:], " "))
+	if err != nil {
+		return p, err
+	}
+	p.amount, p.hasAmount = value, true
+
+	return p, nil
+}
+
+/**
+* Turns an account into a valid beancount account name: every component
+* starts with a capital letter or a digit and only holds letters, digits and dashes.
+ */
+func beancountAccount(account string) string {
+	components := []string{}
+	for _, component := range strings.Split(category.Normalize(account), category.SEPARATOR) {
+		runes := []rune(component)
+		for i, r := range runes {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' {
+				runes[i] = '-'
+			}
+		}
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+			if !unicode.IsLetter(runes[0]) && !unicode.IsDigit(runes[0]) {
+				runes = append([]rune{'X'}, runes...)
+			}
+		}
+		components = append(components, string(runes))
+	}
+
+	return strings.Join(components, category.SEPARATOR)
+}
+
+func quote(value string) string {
+	return strconv.Quote(singleLine(value))
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+
+	return value
+}
+
+func stripBeancountComment(text string) string {
+	if idx := strings.Index(text, ";"); idx != -1 {
+		return text[:idx]
+	}
+
+	return text
+}
+
+func isBeancountTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '/' || r == '.'
+}
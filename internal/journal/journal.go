@@ -0,0 +1,222 @@
+package journal
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	EXPENSES_ROOT = "Expenses"
+	INCOME_ROOT   = "Income"
+	UNCATEGORIZED = "Uncategorized"
+)
+
+// Metadata keys written on a transaction for whatever the accounts and tags
+// cannot carry, so an export can be imported again without losing anything
+const (
+	META_CATEGORY    = "category"
+	META_NOTES       = "notes"
+	META_EXTERNAL_ID = "external_id"
+)
+
+/**
+* A transaction of a plain-text journal, in the terms shared by ledger,
+* hledger and beancount.
+ */
+type entry struct {
+	line        int
+	date        time.Time
+	description string
+	payee       string
+	tags        []string
+	meta        map[string]string
+	postings    []posting
+}
+
+type posting struct {
+	account   string
+	amount    float64
+	hasAmount bool
+}
+
+/**
+* Returns the account an expense is booked to: Expenses:<Category> for
+* expenses and Income:<Category> for incomes. Income categories that already
+* start with "Income" are used as they are.
+ */
+func categoryAccount(exp expense.Expense) string {
+	root := EXPENSES_ROOT
+	if exp.IsIncome {
+		root = INCOME_ROOT
+	}
+
+	name := category.Normalize(exp.Category)
+	if name == "" {
+		if exp.IsIncome {
+			return INCOME_ROOT
+		}
+		return EXPENSES_ROOT + category.SEPARATOR + UNCATEGORIZED
+	}
+
+	if exp.IsIncome && category.IsWithin(name, INCOME_ROOT) {
+		return INCOME_ROOT + strings.TrimPrefix(name, name[:len(INCOME_ROOT)])
+	}
+
+	return root + category.SEPARATOR + name
+}
+
+/**
+* Maps an Expenses or Income account back onto a category.
+*
+* @return The category, or false if the account is neither an Expenses nor an Income account.
+ */
+func accountCategory(account string) (string, bool) {
+	for _, root := range []string{EXPENSES_ROOT, INCOME_ROOT} {
+		if !category.IsWithin(account, root) {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(account, account[:len(root)]), category.SEPARATOR)
+		if category.Equal(name, UNCATEGORIZED) {
+			name = ""
+		}
+
+		return name, true
+	}
+
+	return "", false
+}
+
+/**
+* Builds the metadata an expense needs besides its accounts and tags.
+*
+* @param account The category account as it is written, after any renaming the format needs.
+ */
+func expenseMeta(exp expense.Expense, account string) [][2]string {
+	meta := [][2]string{}
+
+	if name, _ := accountCategory(account); name != category.Normalize(exp.Category) {
+		meta = append(meta, [2]string{META_CATEGORY, exp.Category})
+	}
+
+	if exp.Notes != "" {
+		meta = append(meta, [2]string{META_NOTES, singleLine(exp.Notes)})
+	}
+
+	if exp.ExternalID != "" {
+		meta = append(meta, [2]string{META_EXTERNAL_ID, exp.ExternalID})
+	}
+
+	return meta
+}
+
+/**
+* Turns a journal transaction into tracker transactions, one for every
+* Expenses or Income posting. A missing posting amount is the one that
+* balances the transaction. Postings to other accounts, e.g. the funding
+* account, are the other side of the booking and are not imported.
+ */
+func entryTransactions(e entry) ([]importer.Transaction, error) {
+	if len(e.postings) < 1 {
+		return nil, errors.New("transaction has no postings")
+	}
+
+	missing := -1
+	total := 0.0
+	for i, p := range e.postings {
+		if p.hasAmount {
+			total += p.amount
+			continue
+		}
+		if missing != -1 {
+			return nil, errors.New("more than one posting without an amount")
+		}
+		missing = i
+	}
+	if missing != -1 {
+		e.postings[missing].amount = -total
+	}
+
+	transactions := []importer.Transaction{}
+	for _, p := range e.postings {
+		name, ok := accountCategory(p.account)
+		if !ok || p.amount == 0 {
+			continue
+		}
+
+		// A debit to an expense account is spending and a credit a refund, while
+		// income accounts are credited when money comes in: either way a
+		// negative amount is income
+		isIncome := p.amount < 0
+
+		transactions = append(transactions, importer.Transaction{
+			Line:         e.line,
+			Date:         e.date,
+			Amount:       math.Abs(p.amount),
+			Description:  e.description,
+			Counterparty: e.payee,
+			Category:     name,
+			Tags:         e.tags,
+			Notes:        e.meta[META_NOTES],
+			IsIncome:     isIncome,
+		})
+	}
+
+	if len(transactions) == 1 {
+		if name, ok := e.meta[META_CATEGORY]; ok {
+			transactions[0].Category = name
+		}
+		transactions[0].ExternalID = e.meta[META_EXTERNAL_ID]
+	}
+
+	return transactions, nil
+}
+
+/**
+* Parses an amount with its commodity, such as "$12.50", "-$1,234.50",
+* "$-3" or "12.50 USD". Prices and costs ("@ 1.1 EUR", "{10 USD}") are ignored.
+ */
+func parseAmount(value string) (float64, error) {
+	if idx := strings.IndexAny(value, "@{"); idx != -1 {
+		value = value[:idx]
+	}
+
+	number := strings.Builder{}
+	for _, r := range value {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			number.WriteRune(r)
+		}
+	}
+
+	amount, err := strconv.ParseFloat(number.String(), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, errors.New("invalid amount '" + strings.TrimSpace(value) + "'")
+	}
+
+	return amount, nil
+}
+
+/**
+* Replaces every character a format does not allow in a tag with a dash.
+ */
+func tagName(tag string, isAllowed func(r rune) bool) string {
+	runes := []rune(strings.TrimSpace(tag))
+	for i, r := range runes {
+		if !isAllowed(r) {
+			runes[i] = '-'
+		}
+	}
+
+	return string(runes)
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,216 @@
+package journal
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+func testExpenses() []expense.Expense {
+	return []expense.Expense{
+		{
+			Amount:      12.5,
+			Date:        time.Date(2025, 9, 12, 8, 30, 0, 0, time.UTC),
+			Description: "Uber",
+			Category:    "Transport",
+			Tags:        []string{"work", "late night"},
+			Notes:       "Airport\nrun",
+			ExternalID:  "ofx:1:A",
+		},
+		{
+			Amount:      40,
+			Date:        time.Date(2025, 9, 11, 0, 0, 0, 0, time.UTC),
+			Description: "Burger \"Joe's\"",
+			Category:    "Food:fast food",
+		},
+		{
+			Amount:      3,
+			Date:        time.Date(2025, 9, 13, 0, 0, 0, 0, time.UTC),
+			Description: "Gum",
+		},
+		{
+			Amount:      2000,
+			Date:        time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC),
+			Description: "Salary",
+			Category:    "Income:Salary",
+			IsIncome:    true,
+		},
+	}
+}
+
+func assertRoundTrip(t *testing.T, transactions []importer.Transaction, rowErrors []importer.RowError) {
+	t.Helper()
+
+	if len(rowErrors) != 0 {
+		t.Fatalf("read errors = %v", rowErrors)
+	}
+
+	expenses := testExpenses()
+	if len(transactions) != len(expenses) {
+		t.Fatalf("read %v transactions, want %v", len(transactions), len(expenses))
+	}
+
+	for i, tx := range transactions {
+		exp := expenses[i]
+
+		if tx.Amount != exp.Amount || tx.IsIncome != exp.IsIncome || tx.Category != exp.Category || tx.Description != exp.Description {
+			t.Errorf("transaction %d = %+v, want %+v", i, tx, exp)
+		}
+
+		if !tx.Date.Equal(time.Date(exp.Date.Year(), exp.Date.Month(), exp.Date.Day(), 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("transaction %d date = %v", i, tx.Date)
+		}
+	}
+
+	uber := transactions[0]
+	if uber.Notes != "Airport run" || uber.ExternalID != "ofx:1:A" || strings.Join(uber.Tags, ",") != "work,late-night" {
+		t.Errorf("uber = %+v", uber)
+	}
+}
+
+func TestLedgerRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLedger(&buf, testExpenses(), "Assets:Checking"); err != nil {
+		t.Fatalf("WriteLedger() error = %v", err)
+	}
+
+	want := "2025-09-12 Uber\n" +
+		"    ; :work:late-night:\n" +
+		"    ; notes: Airport run\n" +
+		"    ; external_id: ofx:1:A\n" +
+		"    Expenses:Transport                        $12.50\n" +
+		"    Assets:Checking\n"
+	if !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("WriteLedger() =\n%s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "    Assets:Checking                           $2000.00\n    Income:Salary\n") {
+		t.Errorf("WriteLedger() income should credit the income account:\n%s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "Expenses:Uncategorized") {
+		t.Errorf("WriteLedger() should book uncategorised expenses to Expenses:Uncategorized")
+	}
+
+	transactions, rowErrors := ReadLedger(&buf)
+	assertRoundTrip(t, transactions, rowErrors)
+}
+
+func TestBeancountRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBeancount(&buf, testExpenses(), "assets:checking account"); err != nil {
+		t.Fatalf("WriteBeancount() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"2025-09-11 open Assets:Checking-account\n",
+		"2025-09-11 open Expenses:Food:Fast-food\n",
+		"2025-09-12 * \"Uber\" #work #late-night\n  notes: \"Airport run\"\n",
+		"2025-09-11 * \"Burger \\\"Joe's\\\"\"\n  category: \"Food:fast food\"\n",
+		"  Expenses:Transport                        12.50 USD\n  Assets:Checking-account\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("WriteBeancount() is missing %q:\n%s", want, output)
+		}
+	}
+
+	transactions, rowErrors := ReadBeancount(&buf)
+	assertRoundTrip(t, transactions, rowErrors)
+}
+
+func TestReadLedger(t *testing.T) {
+	file, err := os.Open("./testdata/hledger.journal")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	transactions, rowErrors := ReadLedger(file)
+	if len(rowErrors) != 2 || rowErrors[0].Line != 29 || rowErrors[1].Line != 33 {
+		t.Errorf("ReadLedger() errors = %v, want lines 29 and 33", rowErrors)
+	}
+
+	if len(transactions) != 5 {
+		t.Fatalf("ReadLedger() count = %v, want 5", len(transactions))
+	}
+
+	uber := transactions[0]
+	if uber.Description != "Uber" || uber.Amount != 12.5 || uber.Category != "Transport" || len(uber.Tags) != 1 || uber.Line != 7 {
+		t.Errorf("ReadLedger() uber = %+v", uber)
+	}
+
+	if transactions[1].Category != "Food:Groceries" || transactions[2].Category != "Household" || transactions[2].Amount != 15 {
+		t.Errorf("ReadLedger() split = %+v, %+v", transactions[1], transactions[2])
+	}
+
+	salary := transactions[3]
+	if !salary.IsIncome || salary.Amount != 2000 || salary.Category != "Salary" {
+		t.Errorf("ReadLedger() salary = %+v", salary)
+	}
+
+	refund := transactions[4]
+	if !refund.IsIncome || refund.Amount != 20 || refund.Category != "Clothing" {
+		t.Errorf("ReadLedger() refund = %+v", refund)
+	}
+}
+
+func TestReadBeancount(t *testing.T) {
+	file, err := os.Open("./testdata/main.beancount")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	transactions, rowErrors := ReadBeancount(file)
+	if len(rowErrors) != 1 || rowErrors[0].Line != 18 {
+		t.Errorf("ReadBeancount() errors = %v, want line 18", rowErrors)
+	}
+
+	if len(transactions) != 2 {
+		t.Fatalf("ReadBeancount() count = %v, want 2", len(transactions))
+	}
+
+	bakery := transactions[0]
+	if bakery.Description != "Bread; rolls" || bakery.Counterparty != "Bakery" || bakery.Amount != 4.2 || strings.Join(bakery.Tags, ",") != "weekend" {
+		t.Errorf("ReadBeancount() bakery = %+v", bakery)
+	}
+
+	hotel := transactions[1]
+	if hotel.Amount != 100 || hotel.Category != "Travel:Hotel" || strings.Join(hotel.Tags, ",") != "travel,work" || hotel.Description != "Hotel" {
+		t.Errorf("ReadBeancount() hotel = %+v", hotel)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"$12.50", 12.5, false},
+		{"-$1,234.50", -1234.5, false},
+		{"$-3", -3, false},
+		{"12.50 USD", 12.5, false},
+		{"100 USD @ 0.9 EUR", 100, false},
+		{"10 HOOL {500 USD}", 10, false},
+		{"USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseAmount(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	LEDGER_DATE_FORMAT = "2006-01-02"
+	LEDGER_COMMODITY   = "$"
+	LEDGER_INDENT      = "    "
+)
+
+var (
+	// Date, optional auxiliary date, optional state and code, then the payee
+	ledgerHeader = regexp.MustCompile(`^([0-9]{4}[-/.][0-9]{1,2}[-/.][0-9]{1,2})(?:=\S+)?\s*(?:[*!]\s*)?(?:\([^)]*\)\s*)?(.*)$`)
+	ledgerMeta   = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9_-]*):\s+(.*)$`)
+)
+
+/**
+* Writes expenses as a ledger journal, which hledger reads as well. Every
+* expense is a transaction balanced against the funding account; tags go on a
+* ":tag:" comment and notes, unusual categories and external IDs are kept as
+* metadata comments.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param expenses The expenses to write, already filtered by the caller.
+* @param fundingAccount The account expenses are paid from and incomes paid into, e.g. "Assets:Checking".
+* @return An error if writing fails; otherwise, nil.
+ */
+func WriteLedger(w io.Writer, expenses []expense.Expense, fundingAccount string) error {
+	writer := bufio.NewWriter(w)
+
+	for i, exp := range expenses {
+		if i > 0 {
+			fmt.Fprintln(writer)
+		}
+
+		account := categoryAccount(exp)
+		fmt.Fprintf(writer, "%s %s\n", exp.Date.Format(LEDGER_DATE_FORMAT), singleLine(exp.Description))
+
+		if len(exp.Tags) > 0 {
+			tags := []string{}
+			for _, tag := range exp.Tags {
+				tags = append(tags, tagName(tag, isLedgerTagRune))
+			}
+			fmt.Fprintf(writer, "%s; :%s:\n", LEDGER_INDENT, strings.Join(tags, ":"))
+		}
+
+		for _, meta := range expenseMeta(exp, account) {
+			fmt.Fprintf(writer, "%s; %s: %s\n", LEDGER_INDENT, meta[0], meta[1])
+		}
+
+		amount := LEDGER_COMMODITY + strconv.FormatFloat(exp.Amount, 'f', 2, 64)
+		debit, credit := account, fundingAccount
+		if exp.IsIncome {
+			debit, credit = fundingAccount, account
+		}
+
+		fmt.Fprintf(writer, "%s%-40s  %s\n", LEDGER_INDENT, debit, amount)
+		fmt.Fprintf(writer, "%s%s\n", LEDGER_INDENT, credit)
+	}
+
+	return writer.Flush()
+}
+
+/**
+* Reads the transactions of a ledger or hledger journal. Directives such as
+* "account" or "P" and periodic or automated transactions are skipped.
+*
+* @param r The journal.
+* @return The parsed transactions and the errors of the journal transactions that could not be read.
+ */
+func ReadLedger(r io.Reader) ([]importer.Transaction, []importer.RowError) {
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+
+	scanner := bufio.NewScanner(r)
+	var current *entry
+	var currentErr error
+	line := 0
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		if currentErr == nil {
+			read, err := entryTransactions(*current)
+			if err == nil {
+				transactions = append(transactions, read...)
+			}
+			currentErr = err
+		}
+
+		if currentErr != nil {
+			rowErrors = append(rowErrors, importer.RowError{Line: current.line, Message: currentErr.Error()})
+		}
+
+		current, currentErr = nil, nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.TrimSpace(text) == "" {
+			flush()
+			continue
+		}
+
+		if !unicode.IsSpace(rune(text[0])) {
+			flush()
+
+			match := ledgerHeader.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+
+			current = &entry{line: line, meta: map[string]string{}}
+			current.description = strings.TrimSpace(stripLedgerComment(match[2]))
+
+			date, err := parseLedgerDate(match[1])
+			if err != nil {
+				currentErr = err
+				continue
+			}
+			current.date = date
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, ";") || strings.HasPrefix(text, "#") {
+			readLedgerComment(current, strings.TrimSpace(text[1:]))
+			continue
+		}
+
+		p, err := readLedgerPosting(text)
+		if err != nil && currentErr == nil {
+			currentErr = err
+		}
+		current.postings = append(current.postings, p)
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+	}
+
+	return transactions, rowErrors
+}
+
+/**
+* Reads a comment inside a transaction: ":tag1:tag2:" tags or
+* "key: value" metadata. Anything else is a plain comment.
+ */
+func readLedgerComment(e *entry, comment string) {
+	if strings.HasPrefix(comment, ":") && strings.HasSuffix(comment, ":") && !strings.Contains(comment, " ") {
+		for _, tag := range strings.Split(strings.Trim(comment, ":"), ":") {
+			if tag != "" {
+				e.tags = append(e.tags, tag)
+			}
+		}
+		return
+	}
+
+	if match := ledgerMeta.FindStringSubmatch(comment); match != nil {
+		e.meta[strings.ToLower(match[1])] = strings.TrimSpace(match[2])
+	}
+}
+
+/**
+* Reads a posting such as "Expenses:Food  $12.50" or "Assets:Checking".
+* The account ends at two spaces or a tab; virtual postings lose their brackets.
+ */
+func readLedgerPosting(text string) (posting, error) {
+	text = stripLedgerComment(text)
+	text = strings.TrimLeft(text, "*! ")
+
+	account, amount := text, ""
+	if idx := strings.Index(text, "  "); idx != -1 {
+		account, amount = text[:idx], text[idx:]
+	}
+	if idx := strings.Index(account, "\t"); idx != -1 {
+		account, amount = account[:idx], account[idx:]+amount
+	}
+
+	p := posting{account: strings.Trim(strings.TrimSpace(account), "()[]")}
+
+	// An assertion such as "= $100" only checks the balance
+	if idx := strings.Index(amount, "="); idx != -1 {
+		amount = amount[:idx]
+	}
+
+	if strings.TrimSpace(amount) == "" {
+		return p, nil
+	}
+
+	value, err := parseAmount(amount)
+	if err != nil {
+		return p, err
+	}
+	p.amount, p.hasAmount = value, true
+
+	return p, nil
+}
+
+func parseLedgerDate(value string) (time.Time, error) {
+	normalized := strings.NewReplacer("/", "-", ".", "-").Replace(value)
+
+	date, err := time.Parse("2006-1-2", normalized)
+	if err != nil {
+		return time.Time{}, errors.New("invalid date '" + value + "'")
+	}
+
+	return date, nil
+}
+
+/**
+* Cuts a trailing "  ; comment". A semicolon inside a word, e.g. in a payee
+* such as "Rent;September", is kept.
+ */
+func stripLedgerComment(text string) string {
+	for i, r := range text {
+		if r == ';' && (i == 0 || unicode.IsSpace(rune(text[i-1]))) {
+			return text[:i]
+		}
+	}
+
+	return text
+}
+
+func isLedgerTagRune(r rune) bool {
+	return !unicode.IsSpace(r) && r != ':' && r != ';'
+}
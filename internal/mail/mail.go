@@ -0,0 +1,347 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* The SMTP server emails are sent through and who they are sent to. Events
+* lists the emails wanted; an empty list wants every one. The password is
+* never stored: it is read from PASSWORD_ENV when sending.
+ */
+type Config struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Events   []string `json:"events,omitempty"`
+	// The last month the scheduled statement was sent for, as "2006-01"
+	LastStatement string `json:"last_statement,omitempty"`
+}
+
+/**
+* An email before it is composed: a subject with a plaintext and an HTML body.
+ */
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+const (
+	DEFAULT_EMAIL_FILE_PATH = "./data/email.json"
+	PASSWORD_ENV            = "ET_SMTP_PASSWORD"
+	DEFAULT_PORT            = 587
+	// The port of SMTP over TLS; other ports upgrade with STARTTLS when the server offers it
+	IMPLICIT_TLS_PORT = 465
+	SEND_TIMEOUT      = 15 * time.Second
+	PERIOD_FORMAT     = "2006-01"
+)
+
+const (
+	EVENT_STATEMENT = "statement"
+	EVENT_ALERT     = "alert"
+)
+
+var EVENTS = []string{
+	EVENT_STATEMENT,
+	EVENT_ALERT,
+}
+
+/**
+* Reads the email configuration. Until one is saved, the zero Config is
+* returned; see IsConfigured.
+ */
+func GetConfig() (Config, error) {
+	data, err := storage.GetFileData(DEFAULT_EMAIL_FILE_PATH)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if len(data) < 1 {
+		return Config{}, nil
+	}
+
+	config := Config{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func SaveConfig(config Config) error {
+	if err := ValidateConfig(config); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_EMAIL_FILE_PATH, data)
+}
+
+/**
+* Forgets the configuration, which stops every email.
+ */
+func RemoveConfig() error {
+	return storage.WriteFileData(DEFAULT_EMAIL_FILE_PATH, []byte{})
+}
+
+func IsConfigured(config Config) bool {
+	return config.Host != ""
+}
+
+func ValidateConfig(config Config) error {
+	if strings.TrimSpace(config.Host) == "" {
+		return errors.New("smtp host not set")
+	}
+
+	if config.Port < 1 || config.Port > 65535 {
+		return errors.New("smtp port must be between 1 and 65535")
+	}
+
+	if _, err := netmail.ParseAddress(config.From); err != nil {
+		return errors.New("sender '" + config.From + "' is not a valid email address")
+	}
+
+	if len(config.To) < 1 {
+		return errors.New("no recipient set")
+	}
+
+	for _, to := range config.To {
+		if _, err := netmail.ParseAddress(to); err != nil {
+			return errors.New("recipient '" + to + "' is not a valid email address")
+		}
+	}
+
+	for _, event := range config.Events {
+		if !slices.Contains(EVENTS, event) {
+			return errors.New("unknown email '" + event + "', expected one of " + strings.Join(EVENTS, ", "))
+		}
+	}
+
+	return nil
+}
+
+/**
+* Reports whether an email is to be sent: the configuration is complete
+* and wants the event.
+ */
+func Wants(config Config, event string) bool {
+	if !IsConfigured(config) {
+		return false
+	}
+
+	return len(config.Events) < 1 || slices.Contains(config.Events, event)
+}
+
+/**
+* Builds the raw email: the headers and a multipart/alternative body with
+* the plaintext and HTML versions, both quoted-printable.
+*
+* @param config The configuration naming the sender and the recipients.
+* @param message The subject and bodies.
+* @param now The time the email is dated with.
+ */
+func Compose(config Config, message Message, now time.Time) ([]byte, error) {
+	from, err := netmail.ParseAddress(config.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to := make([]string, 0, len(config.To))
+	for _, recipient := range config.To {
+		address, err := netmail.ParseAddress(recipient)
+		if err != nil {
+			return nil, err
+		}
+		to = append(to, address.String())
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	_, domain, _ := strings.Cut(from.Address, "@")
+
+	buffer := &bytes.Buffer{}
+	body := multipart.NewWriter(buffer)
+
+	headers := []string{
+		"From: " + from.String(),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"Message-ID: <" + id + "@" + domain + ">",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=\"" + body.Boundary() + "\"",
+	}
+	buffer.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, part := range parts {
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+/**
+* Sends a message to the configured recipients. Port IMPLICIT_TLS_PORT
+* speaks TLS from the start; any other port is upgraded with STARTTLS when
+* the server offers it. With a username the client logs in with PLAIN
+* authentication, which net/smtp only allows over TLS or to localhost.
+*
+* @param config A valid configuration.
+* @param password The SMTP password, see Password.
+* @param message The email to send.
+ */
+func Send(config Config, password string, message Message) error {
+	if err := ValidateConfig(config); err != nil {
+		return err
+	}
+
+	data, err := Compose(config, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	dialer := &net.Dialer{Timeout: SEND_TIMEOUT}
+	tlsConfig := &tls.Config{ServerName: config.Host}
+
+	var conn net.Conn
+	if config.Port == IMPLICIT_TLS_PORT {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(SEND_TIMEOUT)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && config.Port != IMPLICIT_TLS_PORT {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+
+		if err := client.Auth(smtp.PlainAuth("", config.Username, password, config.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := netmail.ParseAddress(config.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+
+	for _, recipient := range config.To {
+		address, _ := netmail.ParseAddress(recipient)
+		if err := client.Rcpt(address.Address); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+/**
+* Reads the SMTP password from PASSWORD_ENV. A configuration with a
+* username cannot send without one.
+ */
+func Password(config Config) (string, error) {
+	password := os.Getenv(PASSWORD_ENV)
+	if config.Username != "" && password == "" {
+		return "", errors.New("smtp password not set, export it as " + PASSWORD_ENV)
+	}
+
+	return password, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,313 @@
+package mail
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail/mailtest"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := Config{Host: "smtp.example.com", Port: 587, From: "Tracker <et@example.com>", To: []string{"me@example.com"}}
+	if err := ValidateConfig(valid); err != nil {
+		t.Fatalf("ValidateConfig() error = %v", err)
+	}
+
+	tests := map[string]func(c *Config){
+		"no host":        func(c *Config) { c.Host = " " },
+		"bad port":       func(c *Config) { c.Port = 0 },
+		"bad sender":     func(c *Config) { c.From = "not an address" },
+		"no recipients":  func(c *Config) { c.To = nil },
+		"bad recipient":  func(c *Config) { c.To = []string{"me@example.com", "nope"} },
+		"unknown events": func(c *Config) { c.Events = []string{"weekly"} },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			change(&config)
+			if err := ValidateConfig(config); err == nil {
+				t.Errorf("ValidateConfig() accepted %+v", config)
+			}
+		})
+	}
+}
+
+func TestWants(t *testing.T) {
+	if Wants(Config{}, EVENT_ALERT) {
+		t.Errorf("an unconfigured Config must not want emails")
+	}
+
+	config := Config{Host: "localhost", Port: 25, From: "et@example.com", To: []string{"me@example.com"}}
+	if !Wants(config, EVENT_ALERT) || !Wants(config, EVENT_STATEMENT) {
+		t.Errorf("a Config without events must want every email")
+	}
+
+	config.Events = []string{EVENT_STATEMENT}
+	if Wants(config, EVENT_ALERT) || !Wants(config, EVENT_STATEMENT) {
+		t.Errorf("a Config must only want its events")
+	}
+}
+
+func TestCompose(t *testing.T) {
+	config := Config{From: "Tracker <et@example.com>", To: []string{"me@example.com", "you@example.com"}}
+	message := Message{Subject: "Budget alert: Café", Text: "Plain €\n", HTML: "<p>Rich €</p>"}
+
+	data, err := Compose(config, message, time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("composed email cannot be parsed: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("Subject = %q, want %q", subject, message.Subject)
+	}
+
+	if to := parsed.Header.Get("To"); to != "<me@example.com>, <you@example.com>" {
+		t.Errorf("To = %q", to)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", parsed.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", "Plain €\r\n"},
+		{"text/html; charset=utf-8", "<p>Rich €</p>"},
+	}
+	for _, w := range want {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+
+		// The multipart reader decodes quoted-printable parts itself
+		body, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Type") != w.contentType || string(body) != w.body {
+			t.Errorf("part %q = %q, want %q %q", part.Header.Get("Content-Type"), body, w.contentType, w.body)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	server := mailtest.NewServer(t)
+	config := Config{
+		Host:     server.Host,
+		Port:     server.Port,
+		Username: "et",
+		From:     "et@example.com",
+		To:       []string{"me@example.com", "Partner <you@example.com>"},
+	}
+
+	if err := Send(config, "secret", Message{Subject: "Hello", Text: "Hi", HTML: "<p>Hi</p>"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("stand-in got %d emails, want 1", len(messages))
+	}
+
+	got := messages[0]
+	if got.From != "et@example.com" || strings.Join(got.To, ",") != "me@example.com,you@example.com" || got.Username != "et" {
+		t.Errorf("envelope = %+v", got)
+	}
+
+	if !strings.Contains(got.Data, "Subject: Hello") {
+		t.Errorf("email does not carry its subject:\n%s", got.Data)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	t.Setenv(PASSWORD_ENV, "")
+
+	if _, err := Password(Config{Username: "et"}); err == nil {
+		t.Errorf("Password() must fail for a username without a password")
+	}
+
+	if _, err := Password(Config{}); err != nil {
+		t.Errorf("Password() error = %v without a username", err)
+	}
+
+	t.Setenv(PASSWORD_ENV, "secret")
+	if password, err := Password(Config{Username: "et"}); err != nil || password != "secret" {
+		t.Errorf("Password() = %q, %v", password, err)
+	}
+}
+
+func TestBuildStatement(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2026, 9, day, 12, 0, 0, 0, time.UTC) }
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 40, Description: "Groceries", Category: "Food", Date: date(2)},
+		{ID: 1, Amount: 900, Description: "Rent", Category: "Housing", Date: date(1)},
+		{ID: 2, Amount: 25, Description: "Lunch", Category: "Food", Date: date(3)},
+		{ID: 3, Amount: 500, Description: "Deleted", Category: "Food", Date: date(4), IsDeleted: true},
+		{ID: 4, Amount: 2000, Description: "Salary", Date: date(5), IsIncome: true},
+		{ID: 5, Amount: 10, Description: "August", Category: "Food", Date: date(1).AddDate(0, -1, 0)},
+	}
+	budgets := []budget.Budget{{Year: 2026, Month: 9, Category: "Food", Limit: 100}}
+
+	statement := BuildStatement(expenses, budgets, nil, 2026, 9, date(30))
+
+	if statement.Expenses != 965 || statement.Income != 2000 || statement.Count != 3 {
+		t.Errorf("totals = %.2f spent, %.2f income, %d expenses", statement.Expenses, statement.Income, statement.Count)
+	}
+
+	if len(statement.Largest) != 3 || statement.Largest[0].Description != "Rent" || statement.Largest[2].Description != "Lunch" {
+		t.Errorf("Largest = %+v", statement.Largest)
+	}
+
+	if len(statement.Budgets) != 1 || statement.Budgets[0].Spent != 65 {
+		t.Errorf("Budgets = %+v", statement.Budgets)
+	}
+
+	if len(statement.Categories) != 2 {
+		t.Errorf("Categories = %+v", statement.Categories)
+	}
+}
+
+func TestStatementMessage(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	exp, err := expense.CreateExpenseObj(42.5, "Fish & chips", "Food")
+	if err != nil {
+		t.Fatalf("CreateExpenseObj() error = %v", err)
+	}
+	exp.Date = time.Date(2026, 9, 10, 12, 0, 0, 0, time.UTC)
+	if err := expense.AddExpense(exp); err != nil {
+		t.Fatalf("AddExpense() error = %v", err)
+	}
+
+	message, err := StatementMessage(2026, 9, time.Now())
+	if err != nil {
+		t.Fatalf("StatementMessage() error = %v", err)
+	}
+
+	if message.Subject != "Expense statement for September 2026" {
+		t.Errorf("Subject = %q", message.Subject)
+	}
+
+	if !strings.Contains(message.Text, "Spent:    42.50 $ in 1 expenses") || !strings.Contains(message.Text, "Fish & chips (Food)") {
+		t.Errorf("Text is missing the totals or the expense:\n%s", message.Text)
+	}
+
+	// The HTML body escapes what it is given
+	if !strings.Contains(message.HTML, "Fish &amp; chips") {
+		t.Errorf("HTML does not escape the description:\n%s", message.HTML)
+	}
+}
+
+func TestAlertMessage(t *testing.T) {
+	crossing := report.Crossing{
+		BudgetLine: report.BudgetLine{Year: 2026, Month: 10, Category: "Food", Limit: 100, Spent: 110, Remaining: -10, PercentUsed: 110},
+		Threshold:  100,
+	}
+
+	message, err := AlertMessage([]report.Crossing{crossing})
+	if err != nil {
+		t.Fatalf("AlertMessage() error = %v", err)
+	}
+
+	if message.Subject != "Budget alert: Food has reached 100% of its limit" {
+		t.Errorf("Subject = %q", message.Subject)
+	}
+
+	if !strings.Contains(message.Text, "110.00 of 100.00 $ spent, over by 10.00 $") {
+		t.Errorf("Text = %q", message.Text)
+	}
+
+	message, err = AlertMessage([]report.Crossing{crossing, crossing})
+	if err != nil || message.Subject != "Budget alert: 2 budgets need attention" {
+		t.Errorf("Subject = %q, error = %v", message.Subject, err)
+	}
+}
+
+func TestDueStatement(t *testing.T) {
+	config := Config{Host: "localhost", Port: 25, From: "et@example.com", To: []string{"me@example.com"}}
+	now := time.Date(2026, 1, 3, 9, 0, 0, 0, time.UTC)
+
+	year, month, ok := DueStatement(config, now)
+	if !ok || year != 2025 || month != 12 {
+		t.Errorf("DueStatement() = %d, %d, %v, want December 2025", year, month, ok)
+	}
+
+	config.LastStatement = "2025-12"
+	if _, _, ok := DueStatement(config, now); ok {
+		t.Errorf("a statement that has been sent must not be due")
+	}
+
+	config.LastStatement = "2025-11"
+	config.Events = []string{EVENT_ALERT}
+	if _, _, ok := DueStatement(config, now); ok {
+		t.Errorf("a statement must not be due when statements are not wanted")
+	}
+}
+
+func TestMarkStatementSent(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	config := Config{Host: "localhost", Port: 25, From: "et@example.com", To: []string{"me@example.com"}}
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	if err := MarkStatementSent(2026, 9); err != nil {
+		t.Fatalf("MarkStatementSent() error = %v", err)
+	}
+
+	saved, err := GetConfig()
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+
+	if saved.LastStatement != "2026-09" || saved.Host != "localhost" {
+		t.Errorf("GetConfig() = %+v", saved)
+	}
+
+	if err := RemoveConfig(); err != nil {
+		t.Fatalf("RemoveConfig() error = %v", err)
+	}
+
+	if saved, _ := GetConfig(); IsConfigured(saved) {
+		t.Errorf("GetConfig() = %+v after RemoveConfig()", saved)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+	// Create empty expenses.json and budgets.json files
+	for _, fileName := range []string{"./data/expenses.json", "./data/budgets.json"} {
+		if err := os.WriteFile(fileName, []byte("[]"), 0755); err != nil {
+			t.Fatalf("Failed to create test data file: %v", err)
+		}
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package mailtest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+)
+
+/**
+* A local SMTP stand-in for tests, in the spirit of httptest: it accepts
+* every sender and recipient and keeps the emails it is sent. It offers
+* PLAIN authentication, which net/smtp allows to a loopback host without
+* TLS, and accepts any credentials.
+ */
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+/**
+* An email the stand-in has received. Data is the raw email, without the
+* terminating dot line.
+ */
+type Message struct {
+	From     string
+	To       []string
+	Username string
+	Data     string
+}
+
+/**
+* Starts a stand-in on a free loopback port; it is closed when the test
+* ends.
+ */
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+
+	addr := listener.Addr().(*net.TCPAddr)
+	server := &Server{Host: addr.IP.String(), Port: addr.Port, listener: listener}
+
+	server.wg.Add(1)
+	go server.accept()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+/**
+* The emails received so far, oldest first.
+ */
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message{}, s.messages...)
+}
+
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) session(conn *textproto.Conn) {
+	conn.PrintfLine("220 localhost SMTP stand-in")
+
+	message := Message{}
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			conn.PrintfLine("250-localhost")
+			conn.PrintfLine("250-8BITMIME")
+			conn.PrintfLine("250 AUTH PLAIN")
+		case "HELO":
+			conn.PrintfLine("250 localhost")
+		case "AUTH":
+			// AUTH PLAIN <base64 of "\x00username\x00password">
+			_, encoded, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			if parts := strings.Split(string(decoded), "\x00"); len(parts) == 3 {
+				message.Username = parts[1]
+			}
+			conn.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			message.From = address(arg)
+			conn.PrintfLine("250 OK")
+		case "RCPT":
+			message.To = append(message.To, address(arg))
+			conn.PrintfLine("250 OK")
+		case "DATA":
+			conn.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+
+			data, err := readData(conn.R)
+			if err != nil {
+				return
+			}
+			message.Data = data
+
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+
+			message = Message{Username: message.Username}
+			conn.PrintfLine("250 OK")
+		case "RSET":
+			message = Message{Username: message.Username}
+			conn.PrintfLine("250 OK")
+		case "NOOP":
+			conn.PrintfLine("250 OK")
+		case "QUIT":
+			conn.PrintfLine("221 Bye")
+			return
+		default:
+			conn.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func readData(r *bufio.Reader) (string, error) {
+	data, err := textproto.NewReader(r).ReadDotBytes()
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// "FROM:<someone@example.com>" → "someone@example.com"
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value, _, _ = strings.Cut(value, " ")
+
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,195 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* What a monthly statement reports: the same totals, category breakdown
+* and budget report as `summary` for the month, and its largest expenses.
+ */
+type Statement struct {
+	Year       int
+	Month      int
+	Expenses   float64
+	Income     float64
+	Count      int
+	Categories []report.CategoryTotal
+	Budgets    []report.BudgetLine
+	Largest    []expense.Expense
+}
+
+/**
+* The budgets a change pushed over a threshold, for an alert.
+ */
+type Alert struct {
+	Crossings []report.Crossing
+}
+
+const (
+	// How many of the largest expenses of the month a statement lists
+	STATEMENT_LARGEST_LIMIT = 5
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+var templateFuncs = map[string]any{
+	"money":     func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+	"neg":       func(amount float64) float64 { return -amount },
+	"percent":   func(percent float64) string { return fmt.Sprintf("%.1f%%", percent) },
+	"date":      func(date time.Time) string { return date.Format("2006-01-02") },
+	"category":  categoryName,
+	"indent":    func(depth int) string { return strings.Repeat("  ", depth) },
+	"monthName": func(month int) string { return time.Month(month).String() },
+	"padding":   func(depth int) int { return 8 + depth*16 },
+}
+
+var textTemplates = template.Must(template.New("").Funcs(templateFuncs).ParseFS(templateFiles, "templates/*.txt"))
+
+var htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(templateFuncs).ParseFS(templateFiles, "templates/*.html"))
+
+/**
+* Builds the statement of a month. Deleted expenses are left out, as in
+* `summary`.
+*
+* @param now The current time, used for the days left of the budget report.
+ */
+func BuildStatement(
+	expenses []expense.Expense,
+	budgets []budget.Budget,
+	registry []category.Category,
+	year, month int,
+	now time.Time,
+) Statement {
+	totals := report.Totals(expenses, year, month, "")
+
+	largest := slices.Clone(totals.Spending)
+	slices.SortStableFunc(largest, func(a, b expense.Expense) int {
+		switch {
+		case a.Amount > b.Amount:
+			return -1
+		case a.Amount < b.Amount:
+			return 1
+		}
+		return 0
+	})
+
+	return Statement{
+		Year:       year,
+		Month:      month,
+		Expenses:   totals.Expenses,
+		Income:     totals.Income,
+		Count:      len(totals.Spending),
+		Categories: report.CategoryTotals(totals.Spending, registry),
+		Budgets:    report.BudgetVsActual(expenses, budgets, year, month, "", now),
+		Largest:    largest[:min(STATEMENT_LARGEST_LIMIT, len(largest))],
+	}
+}
+
+/**
+* Reads the stored expenses, budgets and categories and renders the
+* statement of a month.
+ */
+func StatementMessage(year, month int, now time.Time) (Message, error) {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return Message{}, err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return Message{}, err
+	}
+
+	registry, err := category.GetCategories()
+	if err != nil {
+		return Message{}, err
+	}
+
+	statement := BuildStatement(expenses, budgets, registry, year, month, now)
+	subject := fmt.Sprintf("Expense statement for %s %d", time.Month(month).String(), year)
+
+	return render(subject, "statement", statement)
+}
+
+/**
+* Renders an alert for budgets that have crossed a threshold.
+ */
+func AlertMessage(crossings []report.Crossing) (Message, error) {
+	subject := fmt.Sprintf("Budget alert: %d budgets need attention", len(crossings))
+	if len(crossings) == 1 {
+		crossing := crossings[0]
+		subject = fmt.Sprintf(
+			"Budget alert: %s has reached %.0f%% of its limit",
+			categoryName(crossing.Category),
+			crossing.Threshold,
+		)
+	}
+
+	return render(subject, "alert", Alert{Crossings: crossings})
+}
+
+/**
+* Finds the statement the schedule has yet to send: the one of the month
+* before now, once that month is over, unless it has been sent already.
+*
+* @return The year and month of the statement, and whether one is due.
+ */
+func DueStatement(config Config, now time.Time) (int, int, bool) {
+	previous := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	if !Wants(config, EVENT_STATEMENT) || config.LastStatement >= previous.Format(PERIOD_FORMAT) {
+		return 0, 0, false
+	}
+
+	return previous.Year(), int(previous.Month()), true
+}
+
+/**
+* Records that the scheduled statement of a month has been sent, so it is
+* not sent again.
+ */
+func MarkStatementSent(year, month int) error {
+	config, err := GetConfig()
+	if err != nil {
+		return err
+	}
+
+	config.LastStatement = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format(PERIOD_FORMAT)
+
+	return SaveConfig(config)
+}
+
+func render(subject, name string, data any) (Message, error) {
+	text := &bytes.Buffer{}
+	if err := textTemplates.ExecuteTemplate(text, name+".txt", data); err != nil {
+		return Message{}, err
+	}
+
+	html := &bytes.Buffer{}
+	if err := htmlTemplates.ExecuteTemplate(html, name+".html", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+func categoryName(name string) string {
+	if name == "" {
+		return "(uncategorized)"
+	}
+
+	return name
+}
@@ -0,0 +1,297 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+* A metric family in the Prometheus text exposition format: every sample
+* of one metric name, with its help text and type.
+ */
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+/**
+* One sample of a family. Suffix is appended to the family name, e.g.
+* "_bucket" for the buckets of a histogram.
+ */
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+/**
+* A counter split by labels, e.g. requests by method and status.
+ */
+type CounterVec struct {
+	mu         sync.Mutex
+	labelNames []string
+	series     map[string]*counterSeries
+}
+
+/**
+* A histogram split by labels, e.g. request durations by route.
+ */
+type HistogramVec struct {
+	mu         sync.Mutex
+	labelNames []string
+	buckets    []float64
+	series     map[string]*histogramSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+const (
+	TYPE_COUNTER   = "counter"
+	TYPE_GAUGE     = "gauge"
+	TYPE_HISTOGRAM = "histogram"
+	CONTENT_TYPE   = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// Request duration buckets in seconds, from a cached read to a slow rewrite of every file
+var DEFAULT_BUCKETS = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+func NewCounterVec(labelNames ...string) *CounterVec {
+	return &CounterVec{
+		labelNames: labelNames,
+		series:     map[string]*counterSeries{},
+	}
+}
+
+/**
+* Adds one to the counter of the given label values, in the order of the
+* label names.
+ */
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{labelValues: slices.Clone(labelValues)}
+		c.series[key] = series
+	}
+	series.value++
+}
+
+func (c *CounterVec) Family(name, help string) Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	family := Family{Name: name, Help: help, Type: TYPE_COUNTER}
+	for _, key := range sortedKeys(c.series) {
+		series := c.series[key]
+		family.Samples = append(family.Samples, Sample{
+			Labels: labels(c.labelNames, series.labelValues),
+			Value:  series.value,
+		})
+	}
+
+	return family
+}
+
+/**
+* Creates a histogram with the given upper bounds, in ascending order; the
+* +Inf bucket is added on output.
+ */
+func NewHistogramVec(buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     map[string]*histogramSeries{},
+	}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := seriesKey(labelValues)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{
+			labelValues: slices.Clone(labelValues),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = series
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+func (h *HistogramVec) Family(name, help string) Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	family := Family{Name: name, Help: help, Type: TYPE_HISTOGRAM}
+	for _, key := range sortedKeys(h.series) {
+		series := h.series[key]
+		base := labels(h.labelNames, series.labelValues)
+
+		for i, bound := range h.buckets {
+			family.Samples = append(family.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: append(slices.Clone(base), Label{"le", formatValue(bound)}),
+				Value:  float64(series.counts[i]),
+			})
+		}
+
+		family.Samples = append(family.Samples,
+			Sample{Suffix: "_bucket", Labels: append(slices.Clone(base), Label{"le", "+Inf"}), Value: float64(series.count)},
+			Sample{Suffix: "_sum", Labels: base, Value: series.sum},
+			Sample{Suffix: "_count", Labels: base, Value: float64(series.count)},
+		)
+	}
+
+	return family
+}
+
+/**
+* Describes the running process: when it started, its goroutines, memory
+* and garbage collections, and the Go version it was built with.
+ */
+func ProcessFamilies(start time.Time) []Family {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return []Family{
+		gauge("process_start_time_seconds", "Start time of the process since the Unix epoch in seconds.", float64(start.UnixNano())/1e9),
+		gauge("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gauge("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", float64(memStats.HeapAlloc)),
+		gauge("go_memstats_sys_bytes", "Number of bytes obtained from the system.", float64(memStats.Sys)),
+		{
+			Name:    "go_gc_cycles_total",
+			Help:    "Number of completed garbage collection cycles.",
+			Type:    TYPE_COUNTER,
+			Samples: []Sample{{Value: float64(memStats.NumGC)}},
+		},
+		{
+			Name:    "go_info",
+			Help:    "Information about the Go environment.",
+			Type:    TYPE_GAUGE,
+			Samples: []Sample{{Labels: []Label{{"version", runtime.Version()}}, Value: 1}},
+		},
+	}
+}
+
+/**
+* Writes families in the Prometheus text exposition format. Families
+* without samples are left out.
+ */
+func Write(w io.Writer, families []Family) error {
+	buffered := bufio.NewWriter(w)
+
+	for _, family := range families {
+		if len(family.Samples) < 1 {
+			continue
+		}
+
+		buffered.WriteString("# HELP " + family.Name + " " + escapeHelp(family.Help) + "\n")
+		buffered.WriteString("# TYPE " + family.Name + " " + family.Type + "\n")
+
+		for _, sample := range family.Samples {
+			buffered.WriteString(family.Name + sample.Suffix)
+
+			if len(sample.Labels) > 0 {
+				pairs := make([]string, 0, len(sample.Labels))
+				for _, label := range sample.Labels {
+					pairs = append(pairs, label.Name+"=\""+escapeLabelValue(label.Value)+"\"")
+				}
+				buffered.WriteString("{" + strings.Join(pairs, ",") + "}")
+			}
+
+			buffered.WriteString(" " + formatValue(sample.Value) + "\n")
+		}
+	}
+
+	return buffered.Flush()
+}
+
+func gauge(name, help string, value float64) Family {
+	return Family{Name: name, Help: help, Type: TYPE_GAUGE, Samples: []Sample{{Value: value}}}
+}
+
+func labels(names, values []string) []Label {
+	result := make([]Label, 0, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		result = append(result, Label{name, value})
+	}
+
+	return result
+}
+
+// Label values cannot contain this byte, so keys of different values never collide
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
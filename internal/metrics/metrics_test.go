@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	families := []Family{
+		{
+			Name: "expense_tracker_month_spent",
+			Help: "Spending of the current month.\nBy category.",
+			Type: TYPE_GAUGE,
+			Samples: []Sample{
+				{Labels: []Label{{"category", "Food"}}, Value: 42.5},
+				{Labels: []Label{{"category", `Say "hi" \ bye`}}, Value: 1e6},
+			},
+		},
+		{Name: "empty_family", Help: "Left out.", Type: TYPE_GAUGE},
+		{Name: "plain", Help: "No labels.", Type: TYPE_COUNTER, Samples: []Sample{{Value: 3}}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, families); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# HELP expense_tracker_month_spent Spending of the current month.\nBy category.
+# TYPE expense_tracker_month_spent gauge
+expense_tracker_month_spent{category="Food"} 42.5
+expense_tracker_month_spent{category="Say \"hi\" \\ bye"} 1e+06
+# HELP plain No labels.
+# TYPE plain counter
+plain 3
+`
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestCounterVec(t *testing.T) {
+	counter := NewCounterVec("method", "status")
+	counter.Inc("GET", "200")
+	counter.Inc("GET", "200")
+	counter.Inc("POST", "201")
+
+	family := counter.Family("requests_total", "Requests.")
+	if len(family.Samples) != 2 {
+		t.Fatalf("Family() = %d samples, want 2", len(family.Samples))
+	}
+
+	if got := family.Samples[0]; got.Value != 2 || got.Labels[0].Value != "GET" || got.Labels[1].Value != "200" {
+		t.Errorf("first sample = %+v, want GET 200 counted twice", got)
+	}
+
+	if got := family.Samples[1]; got.Value != 1 || got.Labels[0].Value != "POST" {
+		t.Errorf("second sample = %+v, want POST counted once", got)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	histogram := NewHistogramVec([]float64{0.1, 1}, "route")
+	for _, value := range []float64{0.05, 0.5, 0.5, 3} {
+		histogram.Observe(value, "/api/expenses")
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []Family{histogram.Family("duration_seconds", "Durations.")}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, line := range []string{
+		`duration_seconds_bucket{route="/api/expenses",le="0.1"} 1`,
+		`duration_seconds_bucket{route="/api/expenses",le="1"} 3`,
+		`duration_seconds_bucket{route="/api/expenses",le="+Inf"} 4`,
+		`duration_seconds_sum{route="/api/expenses"} 4.05`,
+		`duration_seconds_count{route="/api/expenses"} 4`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("histogram output lacks %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestProcessFamilies(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	names := map[string]bool{}
+	for _, family := range ProcessFamilies(start) {
+		names[family.Name] = true
+
+		if family.Name == "process_start_time_seconds" && family.Samples[0].Value != 1700000000 {
+			t.Errorf("process_start_time_seconds = %v, want 1700000000", family.Samples[0].Value)
+		}
+	}
+
+	for _, name := range []string{"process_start_time_seconds", "go_goroutines", "go_memstats_heap_alloc_bytes", "go_info"} {
+		if !names[name] {
+			t.Errorf("ProcessFamilies() lacks %s", name)
+		}
+	}
+}
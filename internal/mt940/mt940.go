@@ -0,0 +1,274 @@
+package mt940
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	EXTERNAL_ID_PREFIX = "mt940:"
+	NO_REFERENCE       = "NONREF"
+	SEPA_REMITTANCE    = "SVWZ+"
+)
+
+var (
+	fieldStart = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):`)
+	// Value date, optional booking date (MMDD), debit/credit mark, optional
+	// funds code, amount, transaction type, customer reference and, after
+	// "//", the bank reference. A second line holds supplementary details.
+	statementLine = regexp.MustCompile(`^([0-9]{6})([0-9]{4})?(R?[CD])([A-Z])?([0-9]+,[0-9]*)([A-Z][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?`)
+	// Structured information such as "166?00SEPA-UEBERWEISUNG?20...?32NAME"
+	structuredDetails = regexp.MustCompile(`^[0-9]{3}([^0-9A-Za-z\s])`)
+)
+
+/**
+* One tagged field of a message, e.g. ":61:" with its continuation lines.
+ */
+type field struct {
+	tag   string
+	value string
+	line  int
+}
+
+/**
+* Reads the transactions of a SWIFT MT940 statement. Every ":61:" statement
+* line becomes a transaction and the ":86:" field after it provides the
+* description and the counterparty. Reversals count in the opposite
+* direction of the original transaction. The bank reference, together with
+* the account from ":25:", becomes the external ID used to skip
+* transactions that were already imported.
+*
+* @param r The MT940 statement, which may hold several messages.
+* @return The parsed transactions and the errors of the statement lines that could not be read.
+ */
+func ReadTransactions(r io.Reader) ([]importer.Transaction, []importer.RowError) {
+	fields, err := readFields(r)
+	if err != nil {
+		return nil, []importer.RowError{{Line: 0, Message: err.Error()}}
+	}
+
+	if len(fields) < 1 {
+		return nil, []importer.RowError{{Line: 0, Message: "not an MT940 statement"}}
+	}
+
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+	references := map[string]int{}
+	account := ""
+	last := -1
+
+	for _, f := range fields {
+		switch f.tag {
+		case "25":
+			account = strings.TrimSpace(f.value)
+			last = -1
+		case "61":
+			last = -1
+
+			tx, reference, err := readStatementLine(f)
+			if err != nil {
+				rowErrors = append(rowErrors, importer.RowError{Line: f.line, Message: err.Error()})
+				continue
+			}
+
+			if reference != "" {
+				// Some banks repeat a reference, so repeats get a counter
+				// that stays the same when the statement is imported again
+				key := account + ":" + reference
+				references[key]++
+				if references[key] > 1 {
+					reference += "/" + strconv.Itoa(references[key])
+				}
+				tx.ExternalID = EXTERNAL_ID_PREFIX + account + ":" + reference
+			}
+
+			transactions = append(transactions, tx)
+			last = len(transactions) - 1
+		case "86":
+			if last == -1 {
+				continue
+			}
+
+			description, counterparty := readDetails(f.value)
+			tx := &transactions[last]
+			tx.Counterparty = counterparty
+			if counterparty != "" {
+				tx.Description = counterparty
+				tx.Notes = description
+			} else if description != "" {
+				tx.Description = description
+			}
+			last = -1
+		default:
+			last = -1
+		}
+	}
+
+	return transactions, rowErrors
+}
+
+/**
+* Splits the messages into tagged fields. SWIFT block headers such as
+* "{1:F01...}{2:...}{4:" and the "-" or "-}" message trailers are dropped.
+ */
+func readFields(r io.Reader) ([]field, error) {
+	fields := []field{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || trimmed == "-" || trimmed == "-}" || strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+
+		if match := fieldStart.FindStringSubmatch(text); match != nil {
+			fields = append(fields, field{tag: match[1][:2], value: text[len(match[0]):], line: line})
+			continue
+		}
+
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + text
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+/**
+* Parses a ":61:" statement line.
+*
+* @return The transaction, the reference identifying it at the bank, or an error if the line is malformed.
+ */
+func readStatementLine(f field) (importer.Transaction, string, error) {
+	match := statementLine.FindStringSubmatch(f.value)
+	if match == nil {
+		return importer.Transaction{}, "", errors.New("invalid statement line '" + firstLine(f.value) + "'")
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+	if err != nil {
+		return importer.Transaction{}, "", errors.New("invalid value date '" + match[1] + "'")
+	}
+
+	bookingDate := valueDate
+	if match[2] != "" {
+		bookingDate, err = bookingDateNear(valueDate, match[2])
+		if err != nil {
+			return importer.Transaction{}, "", errors.New("invalid booking date '" + match[2] + "'")
+		}
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(match[5], ",", ".", 1), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return importer.Transaction{}, "", errors.New("invalid amount '" + match[5] + "'")
+	}
+
+	// C is a credit and D a debit; RC reverses a credit and RD a debit
+	isIncome := match[3] == "C" || match[3] == "RD"
+
+	reference := strings.TrimSpace(match[8])
+	if reference == "" {
+		reference = strings.TrimSpace(match[7])
+	}
+	if strings.EqualFold(reference, NO_REFERENCE) {
+		reference = ""
+	}
+
+	return importer.Transaction{
+		Line:      f.line,
+		Date:      bookingDate,
+		ValueDate: valueDate,
+		Amount:    amount,
+		IsIncome:  isIncome,
+	}, reference, nil
+}
+
+/**
+* The booking date only has a month and a day. It takes the year of the value
+* date, moved by one when the two dates straddle the turn of the year.
+ */
+func bookingDateNear(valueDate time.Time, monthDay string) (time.Time, error) {
+	date, err := time.Parse("20060102", strconv.Itoa(valueDate.Year())+monthDay)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch {
+	case date.Sub(valueDate) > 180*24*time.Hour:
+		return date.AddDate(-1, 0, 0), nil
+	case valueDate.Sub(date) > 180*24*time.Hour:
+		return date.AddDate(1, 0, 0), nil
+	}
+
+	return date, nil
+}
+
+/**
+* Reads the ":86:" information to account owner. Structured details (the
+* German "?NN" subfields) give the remittance in ?20-?29 and ?60-?63 and the
+* counterparty in ?32-?33; anything else is free text.
+*
+* @return The remittance text and the counterparty, if known.
+ */
+func readDetails(value string) (string, string) {
+	match := structuredDetails.FindStringSubmatch(value)
+	if match == nil {
+		return strings.Join(strings.Fields(value), " "), ""
+	}
+
+	// Structured details are wrapped at a fixed width, often mid-word
+	value = strings.ReplaceAll(value, "\n", "")
+
+	remittance := ""
+	counterparty := ""
+	for _, part := range strings.Split(value[4:], match[1]) {
+		if len(part) < 2 {
+			continue
+		}
+
+		code, err := strconv.Atoi(part[:2])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code >= 20 && code <= 29, code >= 60 && code <= 63:
+			remittance += part[2:]
+		case code == 32 || code == 33:
+			counterparty += part[2:]
+		}
+	}
+
+	if idx := strings.Index(remittance, SEPA_REMITTANCE); idx != -1 {
+		remittance = remittance[idx+len(SEPA_REMITTANCE):]
+	}
+
+	return strings.Join(strings.Fields(remittance), " "), strings.Join(strings.Fields(counterparty), " ")
+}
+
+func firstLine(value string) string {
+	if idx := strings.IndexByte(value, '\n'); idx != -1 {
+		return value[:idx]
+	}
+
+	return value
+}
@@ -0,0 +1,86 @@
+package mt940
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTransactions(t *testing.T) {
+	file, err := os.Open("./testdata/statement.sta")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	transactions, rowErrors := ReadTransactions(file)
+	if len(rowErrors) != 1 || rowErrors[0].Line != 15 {
+		t.Errorf("ReadTransactions() errors = %v, want the broken statement line 15", rowErrors)
+	}
+
+	if len(transactions) != 5 {
+		t.Fatalf("ReadTransactions() count = %v, want 5", len(transactions))
+	}
+
+	uber := transactions[0]
+	if uber.Description != "UBER BV" || uber.Counterparty != "UBER BV" || uber.Notes != "Trip 12.09. Airport" {
+		t.Errorf("ReadTransactions() uber = %+v", uber)
+	}
+	if uber.Amount != 12.5 || !uber.IsIncome || uber.Line != 6 {
+		t.Errorf("ReadTransactions() uber reversal of a debit should be income, got %+v", uber)
+	}
+	if uber.ExternalID != "mt940:10020030/1234567890:BANKREF001" {
+		t.Errorf("ReadTransactions() uber external id = %v", uber.ExternalID)
+	}
+
+	salary := transactions[1]
+	if salary.IsIncome || salary.Amount != 2000 || salary.Counterparty != "ACME GMBHPAYROLL" || salary.Notes != "Salary September" {
+		t.Errorf("ReadTransactions() reversal of a credit should be an expense, got %+v", salary)
+	}
+
+	groceries := transactions[2]
+	if groceries.Description != "Groceries Smith & Sons weekly shop" || groceries.Counterparty != "" || groceries.IsIncome {
+		t.Errorf("ReadTransactions() groceries = %+v", groceries)
+	}
+	if !groceries.Date.Equal(time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC)) || !groceries.ValueDate.Equal(groceries.Date) {
+		t.Errorf("ReadTransactions() groceries dates = %v, %v", groceries.Date, groceries.ValueDate)
+	}
+
+	if groceries.ExternalID != "mt940:10020030/1234567890:KREF" || transactions[3].ExternalID != "mt940:10020030/1234567890:KREF/2" {
+		t.Errorf("ReadTransactions() repeated references = %v, %v", groceries.ExternalID, transactions[3].ExternalID)
+	}
+
+	reversal := transactions[4]
+	if !reversal.Date.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) || !reversal.ValueDate.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ReadTransactions() reversal dates = %v, %v", reversal.Date, reversal.ValueDate)
+	}
+}
+
+func TestBookingDateNear(t *testing.T) {
+	tests := []struct {
+		valueDate time.Time
+		monthDay  string
+		want      time.Time
+	}{
+		{time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), "0911", time.Date(2025, 9, 11, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), "1231", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), "0102", time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.monthDay, func(t *testing.T) {
+			got, err := bookingDateNear(tt.valueDate, tt.monthDay)
+			if err != nil || !got.Equal(tt.want) {
+				t.Errorf("bookingDateNear() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTransactionsNotMT940(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("date,amount\n2025-09-01,12\n"))
+	if len(rowErrors) != 1 {
+		t.Errorf("ReadTransactions() should reject a file without MT940 fields, got %v", rowErrors)
+	}
+}
@@ -0,0 +1,288 @@
+package ofx
+
+import (
+	"errors"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	EXTERNAL_ID_PREFIX = "ofx:"
+)
+
+/**
+* An element of an OFX document. SGML files (OFX 1.x) leave leaf elements
+* unclosed, so a leaf is just a name with a value.
+ */
+type node struct {
+	name     string
+	value    string
+	line     int
+	children []*node
+}
+
+type token struct {
+	name    string
+	text    string
+	line    int
+	isOpen  bool
+	isClose bool
+}
+
+/**
+* Reads the bank and credit card transactions of an OFX or QFX statement, in
+* both the SGML (1.x) and the XML (2.x) flavour. Negative amounts are expenses
+* and positive ones are incoming payments. The FITID of every transaction,
+* together with the account, becomes the external ID used to skip
+* transactions that were already imported.
+*
+* @param r The OFX statement.
+* @return The parsed transactions and the errors of the entries that could not be read.
+ */
+func ReadTransactions(r io.Reader) ([]importer.Transaction, []importer.RowError) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, []importer.RowError{{Line: 0, Message: err.Error()}}
+	}
+
+	root := parse(tokenize(decode(data)))
+	document := root.find("OFX")
+	if document == nil {
+		return nil, []importer.RowError{{Line: 0, Message: "not an OFX statement"}}
+	}
+
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+
+	var collect func(n *node, account string)
+	collect = func(n *node, account string) {
+		for _, from := range []string{"BANKACCTFROM", "CCACCTFROM"} {
+			if acct := n.child(from); acct != nil {
+				account = acct.childValue("ACCTID")
+			}
+		}
+
+		if n.name == "STMTTRN" {
+			tx, err := readTransaction(n, account)
+			if err != nil {
+				rowErrors = append(rowErrors, importer.RowError{Line: n.line, Message: err.Error()})
+				return
+			}
+			transactions = append(transactions, tx)
+			return
+		}
+
+		for _, c := range n.children {
+			collect(c, account)
+		}
+	}
+	collect(document, "")
+
+	return transactions, rowErrors
+}
+
+/**
+* Parses an OFX date such as "20250912", "20250912103000" or
+* "20250912103000.000[-5:EST]". The calendar date of the statement is kept,
+* whatever its time zone.
+ */
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if idx := strings.IndexAny(value, ".["); idx != -1 {
+		value = value[:idx]
+	}
+
+	if len(value) < 8 {
+		return time.Time{}, errors.New("invalid date")
+	}
+
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, errors.New("invalid date")
+	}
+
+	return date, nil
+}
+
+func readTransaction(n *node, account string) (importer.Transaction, error) {
+	dateValue := n.childValue("DTPOSTED")
+	date, err := ParseDate(dateValue)
+	if err != nil {
+		return importer.Transaction{}, errors.New("invalid date '" + dateValue + "'")
+	}
+
+	amountValue := n.childValue("TRNAMT")
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(amountValue), ",", "."), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return importer.Transaction{}, errors.New("invalid amount '" + amountValue + "'")
+	}
+
+	description := n.childValue("NAME")
+	if description == "" {
+		description = n.childValue("MEMO")
+	}
+
+	externalID := ""
+	if fitID := n.childValue("FITID"); fitID != "" {
+		externalID = EXTERNAL_ID_PREFIX + account + ":" + fitID
+	}
+
+	return importer.Transaction{
+		Line:        n.line,
+		Date:        date,
+		Amount:      math.Abs(amount),
+		Description: description,
+		IsIncome:    amount > 0,
+		ExternalID:  externalID,
+	}, nil
+}
+
+/**
+* Splits a document into tags and text. Headers before the first tag,
+* processing instructions and comments are dropped.
+ */
+func tokenize(data string) []token {
+	tokens := []token{}
+	line := 1
+
+	for len(data) > 0 {
+		start := strings.IndexByte(data, '<')
+		if start == -1 {
+			break
+		}
+
+		if text := strings.TrimSpace(data[:start]); text != "" && len(tokens) > 0 {
+			tokens = append(tokens, token{text: html.UnescapeString(text), line: line})
+		}
+		line += strings.Count(data[:start], "\n")
+		data = data[start:]
+
+		end := strings.IndexByte(data, '>')
+		if end == -1 {
+			break
+		}
+
+		tag := data[1:end]
+		tagLine := line
+		line += strings.Count(data[:end+1], "\n")
+		data = data[end+1:]
+
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+
+		isClose := strings.HasPrefix(tag, "/")
+		isSelfClosing := strings.HasSuffix(tag, "/")
+		name := strings.Trim(tag, "/ \t\r\n")
+		if idx := strings.IndexAny(name, " \t\r\n"); idx != -1 {
+			name = name[:idx]
+		}
+		name = strings.ToUpper(name)
+
+		if name == "" {
+			continue
+		}
+
+		tokens = append(tokens, token{name: name, line: tagLine, isOpen: !isClose, isClose: isClose || isSelfClosing})
+	}
+
+	return tokens
+}
+
+/**
+* Builds the element tree. An element that got a value and is followed by
+* another tag before its closing tag is an unclosed SGML leaf.
+ */
+func parse(tokens []token) *node {
+	root := &node{}
+	stack := []*node{root}
+
+	for _, t := range tokens {
+		top := stack[len(stack)-1]
+
+		switch {
+		case t.name == "":
+			top.value = t.text
+		case t.isOpen:
+			if top != root && top.value != "" {
+				stack = stack[:len(stack)-1]
+				top = stack[len(stack)-1]
+			}
+
+			n := &node{name: t.name, line: t.line}
+			top.children = append(top.children, n)
+			if t.isClose {
+				continue
+			}
+			stack = append(stack, n)
+		default:
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == t.name {
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+	}
+
+	return root
+}
+
+/**
+* Finds the first element with the given name, searching depth-first.
+ */
+func (n *node) find(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+		if found := c.find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (n *node) childValue(name string) string {
+	c := n.child(name)
+	if c == nil {
+		return ""
+	}
+
+	return c.value
+}
+
+/**
+* SGML statements are often Windows-1252 rather than UTF-8; bytes that are
+* not valid UTF-8 are read as Latin-1 so descriptions stay readable.
+ */
+func decode(data []byte) string {
+	if utf8.Valid(data) {
+		return strings.TrimPrefix(string(data), "\ufeff")
+	}
+
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+
+	return string(runes)
+}
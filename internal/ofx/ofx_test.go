@@ -0,0 +1,121 @@
+package ofx
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) *os.File {
+	file, err := os.Open("./testdata/" + name)
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func TestReadTransactionsSGML(t *testing.T) {
+	transactions, rowErrors := ReadTransactions(readFixture(t, "statement_sgml.ofx"))
+	if len(rowErrors) != 0 {
+		t.Fatalf("ReadTransactions() errors = %v", rowErrors)
+	}
+
+	if len(transactions) != 3 {
+		t.Fatalf("ReadTransactions() count = %v, want 3", len(transactions))
+	}
+
+	uber := transactions[0]
+	if uber.Description != "UBER TRIP" || uber.Amount != 12.5 || uber.IsIncome {
+		t.Errorf("ReadTransactions() uber = %+v", uber)
+	}
+	if !uber.Date.Equal(time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ReadTransactions() date = %v", uber.Date)
+	}
+	if uber.ExternalID != "ofx:1234567:20250912001" {
+		t.Errorf("ReadTransactions() external id = %v", uber.ExternalID)
+	}
+	if uber.Line != 39 {
+		t.Errorf("ReadTransactions() line = %v, want 39", uber.Line)
+	}
+
+	if transactions[1].Description != "Smith & Sons Groceries" {
+		t.Errorf("ReadTransactions() should decode entities, got %v", transactions[1].Description)
+	}
+
+	salary := transactions[2]
+	if !salary.IsIncome || salary.Amount != 2000 || salary.Description != "Salary September" {
+		t.Errorf("ReadTransactions() salary = %+v", salary)
+	}
+}
+
+func TestReadTransactionsXML(t *testing.T) {
+	transactions, rowErrors := ReadTransactions(readFixture(t, "statement_xml.ofx"))
+	if len(rowErrors) != 2 {
+		t.Fatalf("ReadTransactions() errors = %v, want 2", rowErrors)
+	}
+
+	if rowErrors[0].Line != 29 || !strings.Contains(rowErrors[0].Message, "date") {
+		t.Errorf("ReadTransactions() first error = %v", rowErrors[0])
+	}
+	if rowErrors[1].Line != 36 || !strings.Contains(rowErrors[1].Message, "amount") {
+		t.Errorf("ReadTransactions() second error = %v", rowErrors[1])
+	}
+
+	if len(transactions) != 1 {
+		t.Fatalf("ReadTransactions() count = %v, want 1", len(transactions))
+	}
+
+	netflix := transactions[0]
+	if netflix.Description != "Netflix" || netflix.Amount != 9.99 || netflix.ExternalID != "ofx:4111222233334444:CC-0001" {
+		t.Errorf("ReadTransactions() netflix = %+v", netflix)
+	}
+}
+
+func TestReadTransactionsNotOFX(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("date,amount\n2025-09-01,12\n"))
+	if len(rowErrors) != 1 {
+		t.Errorf("ReadTransactions() should reject a file without an OFX element, got %v", rowErrors)
+	}
+}
+
+func TestReadTransactionsLatin1(t *testing.T) {
+	statement := "<OFX><STMTTRN><DTPOSTED>20250901<TRNAMT>-4.00<FITID>1<NAME>Caf\xe9</STMTTRN></OFX>"
+
+	transactions, rowErrors := ReadTransactions(strings.NewReader(statement))
+	if len(rowErrors) != 0 || len(transactions) != 1 {
+		t.Fatalf("ReadTransactions() = %v, %v", transactions, rowErrors)
+	}
+
+	if transactions[0].Description != "Café" {
+		t.Errorf("ReadTransactions() description = %q, want Café", transactions[0].Description)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"20250912", time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), false},
+		{"20250912103000", time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), false},
+		{"20250912233000.000[-5:EST]", time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), false},
+		{"202509", time.Time{}, true},
+		{"2025-09-12", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
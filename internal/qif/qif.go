@@ -0,0 +1,309 @@
+package qif
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/importer"
+)
+
+const (
+	DATE_FORMAT     = "01/02/2006"
+	TYPE_HEADER     = "!Type:"
+	ACCOUNT_TYPE    = "Bank"
+	RECORD_END      = "^"
+	CLASS_SEPARATOR = "/"
+	SPLIT_TOLERANCE = 0.005
+)
+
+var (
+	// Account types whose records are transactions. Other sections, such as
+	// category lists or investment accounts, are skipped.
+	TRANSACTION_TYPES = []string{"bank", "cash", "ccard", "oth a", "oth l"}
+	DATE_LAYOUTS      = []string{"1/2/2006", "1/2/06"}
+)
+
+/**
+* A QIF record: the fields of one transaction up to the "^" line.
+ */
+type record struct {
+	line   int
+	fields []field
+}
+
+type field struct {
+	code  byte
+	value string
+}
+
+type split struct {
+	category string
+	memo     string
+	amount   float64
+}
+
+/**
+* Reads the transactions of a QIF file. Negative amounts are expenses and
+* positive ones are incoming payments. A split transaction becomes one
+* transaction per split, each with its own category and memo.
+*
+* @param r The QIF file.
+* @param dateFormat A Go layout for the dates, or an empty string for the usual US forms such as "9/12/2025" and "9/12'25".
+* @return The parsed transactions and the errors of the records that could not be read.
+ */
+func ReadTransactions(r io.Reader, dateFormat string) ([]importer.Transaction, []importer.RowError) {
+	transactions := []importer.Transaction{}
+	rowErrors := []importer.RowError{}
+
+	scanner := bufio.NewScanner(r)
+	isTransactionSection := true
+	current := record{}
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "!") {
+			if strings.HasPrefix(strings.ToLower(text), strings.ToLower(TYPE_HEADER)) {
+				accountType := strings.ToLower(strings.TrimSpace(text[len(TYPE_HEADER):]))
+				isTransactionSection = slices.Contains(TRANSACTION_TYPES, accountType)
+			} else {
+				isTransactionSection = false
+			}
+			current = record{}
+			continue
+		}
+
+		if text == RECORD_END {
+			if isTransactionSection && len(current.fields) > 0 {
+				read, err := readRecord(current, dateFormat)
+				if err != nil {
+					rowErrors = append(rowErrors, importer.RowError{Line: current.line, Message: err.Error()})
+				} else {
+					transactions = append(transactions, read...)
+				}
+			}
+			current = record{}
+			continue
+		}
+
+		if len(current.fields) == 0 {
+			current.line = line
+		}
+		current.fields = append(current.fields, field{code: text[0], value: strings.TrimSpace(text[1:])})
+	}
+
+	if err := scanner.Err(); err != nil {
+		rowErrors = append(rowErrors, importer.RowError{Line: line, Message: err.Error()})
+	}
+
+	if isTransactionSection && len(current.fields) > 0 {
+		rowErrors = append(rowErrors, importer.RowError{Line: current.line, Message: "record not terminated with '^'"})
+	}
+
+	return transactions, rowErrors
+}
+
+/**
+* Writes expenses as a QIF bank account, one record per expense.
+* Expenses are written as negative amounts and incomes as positive ones.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param expenses The expenses to write, already filtered by the caller.
+* @return An error if writing fails; otherwise, nil.
+ */
+func WriteExpenses(w io.Writer, expenses []expense.Expense) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintf(writer, "%s%s\n", TYPE_HEADER, ACCOUNT_TYPE)
+
+	for _, exp := range expenses {
+		amount := -exp.Amount
+		if exp.IsIncome {
+			amount = exp.Amount
+		}
+
+		fmt.Fprintf(writer, "D%s\n", exp.Date.Format(DATE_FORMAT))
+		fmt.Fprintf(writer, "T%s\n", strconv.FormatFloat(amount, 'f', 2, 64))
+		fmt.Fprintf(writer, "P%s\n", singleLine(exp.Description))
+		if exp.Notes != "" {
+			fmt.Fprintf(writer, "M%s\n", singleLine(exp.Notes))
+		}
+		if exp.Category != "" {
+			fmt.Fprintf(writer, "L%s\n", singleLine(exp.Category))
+		}
+		fmt.Fprintf(writer, "%s\n", RECORD_END)
+	}
+
+	return writer.Flush()
+}
+
+/**
+* Parses a QIF date. Quicken writes years after 1999 with an apostrophe,
+* e.g. "9/12'25", and pads days with spaces, e.g. "9/ 2/2025".
+ */
+func ParseDate(value, layout string) (time.Time, error) {
+	if layout != "" {
+		date, err := time.Parse(layout, strings.TrimSpace(value))
+		if err != nil {
+			return time.Time{}, errors.New("invalid date '" + value + "'")
+		}
+		return date, nil
+	}
+
+	normalized := strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "'", "/")
+	normalized = strings.ReplaceAll(normalized, "-", "/")
+
+	for _, l := range DATE_LAYOUTS {
+		if date, err := time.Parse(l, normalized); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid date '" + value + "'")
+}
+
+func readRecord(rec record, dateFormat string) ([]importer.Transaction, error) {
+	var (
+		date      time.Time
+		amount    float64
+		hasDate   bool
+		hasAmount bool
+		payee     string
+		memo      string
+		category  string
+		splits    []split
+	)
+
+	for _, f := range rec.fields {
+		switch f.code {
+		case 'D':
+			d, err := ParseDate(f.value, dateFormat)
+			if err != nil {
+				return nil, err
+			}
+			date, hasDate = d, true
+		case 'T', 'U':
+			a, err := parseAmount(f.value)
+			if err != nil {
+				return nil, err
+			}
+			amount, hasAmount = a, true
+		case 'P':
+			payee = f.value
+		case 'M':
+			memo = f.value
+		case 'L':
+			category = parseCategory(f.value)
+		case 'S':
+			splits = append(splits, split{category: parseCategory(f.value)})
+		case 'E':
+			if len(splits) > 0 {
+				splits[len(splits)-1].memo = f.value
+			}
+		case '$':
+			if len(splits) < 1 {
+				return nil, errors.New("split amount without a split category")
+			}
+			a, err := parseAmount(f.value)
+			if err != nil {
+				return nil, err
+			}
+			splits[len(splits)-1].amount = a
+		}
+	}
+
+	if !hasDate {
+		return nil, errors.New("date not set")
+	}
+
+	if !hasAmount && len(splits) < 1 {
+		return nil, errors.New("amount not set")
+	}
+
+	description := payee
+	if description == "" {
+		description = memo
+	}
+
+	if len(splits) < 1 {
+		return []importer.Transaction{newTransaction(rec.line, date, amount, description, category, memo)}, nil
+	}
+
+	total := 0.0
+	for _, s := range splits {
+		total += s.amount
+	}
+	if hasAmount && math.Abs(total-amount) > SPLIT_TOLERANCE {
+		return nil, fmt.Errorf("splits add up to %.2f, not to the total of %.2f", total, amount)
+	}
+
+	transactions := []importer.Transaction{}
+	for _, s := range splits {
+		notes := s.memo
+		if notes == "" {
+			notes = memo
+		}
+		transactions = append(transactions, newTransaction(rec.line, date, s.amount, description, s.category, notes))
+	}
+
+	return transactions, nil
+}
+
+func newTransaction(line int, date time.Time, amount float64, description, category, notes string) importer.Transaction {
+	return importer.Transaction{
+		Line:        line,
+		Date:        date,
+		Amount:      math.Abs(amount),
+		Description: description,
+		Category:    category,
+		Notes:       notes,
+		IsIncome:    amount > 0,
+	}
+}
+
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, errors.New("invalid amount '" + value + "'")
+	}
+
+	return amount, nil
+}
+
+/**
+* Strips the class from a category ("Food:Groceries/Holiday") and drops
+* transfers to other accounts ("[Savings]"), which have no category here.
+ */
+func parseCategory(value string) string {
+	if idx := strings.Index(value, CLASS_SEPARATOR); idx != -1 {
+		value = value[:idx]
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		return ""
+	}
+
+	return value
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,125 @@
+package qif
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func TestReadTransactions(t *testing.T) {
+	file, err := os.Open("./testdata/checking.qif")
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer file.Close()
+
+	transactions, rowErrors := ReadTransactions(file, "")
+	if len(rowErrors) != 2 {
+		t.Fatalf("ReadTransactions() errors = %v, want 2", rowErrors)
+	}
+
+	if rowErrors[0].Line != 39 || !strings.Contains(rowErrors[0].Message, "splits") {
+		t.Errorf("ReadTransactions() split error = %v", rowErrors[0])
+	}
+	if rowErrors[1].Line != 45 || !strings.Contains(rowErrors[1].Message, "date") {
+		t.Errorf("ReadTransactions() date error = %v", rowErrors[1])
+	}
+
+	if len(transactions) != 5 {
+		t.Fatalf("ReadTransactions() count = %v, want 5", len(transactions))
+	}
+
+	uber := transactions[0]
+	if uber.Description != "UBER TRIP" || uber.Amount != 12.5 || uber.Category != "Transport" || uber.Notes != "Airport" || uber.IsIncome {
+		t.Errorf("ReadTransactions() uber = %+v", uber)
+	}
+	if !uber.Date.Equal(time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC)) || uber.Line != 12 {
+		t.Errorf("ReadTransactions() uber date = %v, line = %v", uber.Date, uber.Line)
+	}
+
+	fruit, household := transactions[1], transactions[2]
+	if fruit.Category != "Food:Groceries" || fruit.Amount != 45.1 || fruit.Notes != "Fruit" || fruit.Description != "Smith Groceries" {
+		t.Errorf("ReadTransactions() first split = %+v", fruit)
+	}
+	if household.Category != "Household" || household.Amount != 1000 || household.Notes != "Weekly shop" {
+		t.Errorf("ReadTransactions() second split = %+v", household)
+	}
+
+	salary := transactions[3]
+	if !salary.IsIncome || salary.Amount != 2000 || salary.Category != "Income:Salary" {
+		t.Errorf("ReadTransactions() salary = %+v", salary)
+	}
+
+	if transactions[4].Category != "" {
+		t.Errorf("ReadTransactions() transfer category = %v, want none", transactions[4].Category)
+	}
+}
+
+func TestReadTransactionsUnterminated(t *testing.T) {
+	_, rowErrors := ReadTransactions(strings.NewReader("!Type:Bank\nD9/12/2025\nT-1.00\n"), "")
+	if len(rowErrors) != 1 || rowErrors[0].Line != 2 {
+		t.Errorf("ReadTransactions() errors = %v", rowErrors)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		layout  string
+		wantErr bool
+	}{
+		{"9/2/2025", "", false},
+		{"09/02/2025", "", false},
+		{"9/ 2'25", "", false},
+		{"9/2/25", "", false},
+		{"02.09.2025", "02.01.2006", false},
+		{"13/2/2025", "", true},
+		{"2025-09-02", "02.01.2006", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDate(tt.value, tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(want) {
+				t.Errorf("ParseDate() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteExpensesRoundTrip(t *testing.T) {
+	expenses := []expense.Expense{
+		{Amount: 12.5, Date: time.Date(2025, 9, 12, 8, 0, 0, 0, time.UTC), Description: "Uber", Category: "Transport", Notes: "Airport\nlate"},
+		{Amount: 2000, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Description: "Salary", IsIncome: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExpenses(&buf, expenses); err != nil {
+		t.Fatalf("WriteExpenses() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "!Type:Bank\nD09/12/2025\nT-12.50\nPUber\nMAirport late\nLTransport\n^\n") {
+		t.Errorf("WriteExpenses() =\n%s", buf.String())
+	}
+
+	transactions, rowErrors := ReadTransactions(&buf, "")
+	if len(rowErrors) != 0 || len(transactions) != 2 {
+		t.Fatalf("ReadTransactions() = %v, %v", transactions, rowErrors)
+	}
+
+	if transactions[0].Amount != 12.5 || transactions[0].IsIncome || transactions[0].Category != "Transport" {
+		t.Errorf("round trip expense = %+v", transactions[0])
+	}
+	if !transactions[1].IsIncome || transactions[1].Description != "Salary" {
+		t.Errorf("round trip income = %+v", transactions[1])
+	}
+}
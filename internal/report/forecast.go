@@ -0,0 +1,237 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+const (
+	RECURRING_LOOKBACK_MONTHS = 2
+)
+
+type RecurringItem struct {
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	Amount      float64 `json:"amount"`
+	IsPaid      bool    `json:"is_paid"`
+}
+
+type ForecastLine struct {
+	Category   string  `json:"category"`
+	Spent      float64 `json:"spent"`
+	Recurring  float64 `json:"recurring"`
+	Forecast   float64 `json:"forecast"`
+	Limit      float64 `json:"limit"`
+	HasBudget  bool    `json:"has_budget"`
+	OverBudget bool    `json:"over_budget"`
+}
+
+/**
+* Projects end-of-month spending per category for the given period.
+* Recurring items (same description and category seen in each of the previous
+* RECURRING_LOOKBACK_MONTHS months) are counted once at their expected amount,
+* everything else is extrapolated linearly from the pace so far. A budget set on
+* a parent category is compared against the forecast of the whole subtree.
+*
+* @param expenses All known expenses.
+* @param budgets All known budgets; categories with a budget are always reported.
+* @param year The year of the period.
+* @param month The month of the period (1-12).
+* @param categoryFilter Optional category filter including its subcategories; empty string means all categories.
+* @param now The current time, used to compute how much of the period has elapsed.
+* @return One line per category, ordered by category.
+ */
+func Forecast(
+	expenses []expense.Expense,
+	budgets []budget.Budget,
+	year, month int,
+	categoryFilter string,
+	now time.Time,
+) []ForecastLine {
+	daysInMonth := DaysInMonth(year, month)
+	daysElapsed := daysElapsedInPeriod(year, month, now)
+
+	recurring := RecurringItems(expenses, year, month)
+	isRecurring := map[string]bool{}
+	for _, item := range recurring {
+		isRecurring[recurringKey(item.Description, item.Category)] = true
+	}
+
+	lines := map[string]*ForecastLine{}
+	getLine := func(name string) *ForecastLine {
+		key := category.Key(name)
+		if _, ok := lines[key]; !ok {
+			lines[key] = &ForecastLine{Category: category.Normalize(name)}
+		}
+		return lines[key]
+	}
+
+	paceSpent := map[string]float64{}
+
+	for _, exp := range expenses {
+		if !IsInPeriod(exp, year, month) {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(exp.Category, categoryFilter) {
+			continue
+		}
+
+		line := getLine(exp.Category)
+		line.Spent += exp.Amount
+
+		if isRecurring[recurringKey(exp.Description, exp.Category)] {
+			line.Recurring += exp.Amount
+		} else {
+			paceSpent[category.Key(exp.Category)] += exp.Amount
+		}
+	}
+
+	for _, item := range recurring {
+		if item.IsPaid {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(item.Category, categoryFilter) {
+			continue
+		}
+
+		getLine(item.Category).Recurring += item.Amount
+	}
+
+	for key, line := range lines {
+		pace := paceSpent[key]
+		if daysElapsed > 0 {
+			pace = pace / float64(daysElapsed) * float64(daysInMonth)
+		}
+
+		line.Forecast = line.Recurring + pace
+	}
+
+	leaves := map[string]ForecastLine{}
+	for key, line := range lines {
+		leaves[key] = *line
+	}
+
+	for _, b := range budgets {
+		if b.Year != year || b.Month != month {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(b.Category, categoryFilter) {
+			continue
+		}
+
+		line := getLine(b.Category)
+		line.Spent, line.Recurring, line.Forecast = 0, 0, 0
+		for key, leaf := range leaves {
+			if category.IsWithin(key, b.Category) {
+				line.Spent += leaf.Spent
+				line.Recurring += leaf.Recurring
+				line.Forecast += leaf.Forecast
+			}
+		}
+
+		line.Limit = b.Limit
+		line.HasBudget = true
+		line.OverBudget = line.Forecast > line.Limit
+	}
+
+	result := []ForecastLine{}
+	for _, line := range lines {
+		result = append(result, *line)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return category.Key(result[i].Category) < category.Key(result[j].Category)
+	})
+
+	return result
+}
+
+/**
+* Finds items that were recorded in each of the previous RECURRING_LOOKBACK_MONTHS
+* months, matched by description and category. The expected amount is the most
+* recent one, and an item is marked paid once it shows up in the given period.
+ */
+func RecurringItems(expenses []expense.Expense, year, month int) []RecurringItem {
+	type occurrence struct {
+		months map[int]bool
+		latest expense.Expense
+		isPaid bool
+	}
+
+	periodIndex := func(y, m int) int {
+		return y*12 + m - 1
+	}
+	current := periodIndex(year, month)
+
+	seen := map[string]*occurrence{}
+	for _, exp := range expenses {
+		if !expense.IsSpending(exp) || strings.TrimSpace(exp.Description) == "" {
+			continue
+		}
+
+		idx := periodIndex(exp.Date.Year(), int(exp.Date.Month()))
+		if idx > current || idx < current-RECURRING_LOOKBACK_MONTHS {
+			continue
+		}
+
+		key := recurringKey(exp.Description, exp.Category)
+		occ, ok := seen[key]
+		if !ok {
+			occ = &occurrence{months: map[int]bool{}}
+			seen[key] = occ
+		}
+
+		if idx == current {
+			occ.isPaid = true
+			continue
+		}
+
+		occ.months[idx] = true
+		if exp.Date.After(occ.latest.Date) {
+			occ.latest = exp
+		}
+	}
+
+	items := []RecurringItem{}
+	for _, occ := range seen {
+		if len(occ.months) < RECURRING_LOOKBACK_MONTHS {
+			continue
+		}
+
+		items = append(items, RecurringItem{
+			Description: occ.latest.Description,
+			Category:    occ.latest.Category,
+			Amount:      occ.latest.Amount,
+			IsPaid:      occ.isPaid,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Description < items[j].Description
+	})
+
+	return items
+}
+
+/**
+* Counts the days of the period that have started, including today.
+ */
+func daysElapsedInPeriod(year, month int, now time.Time) int {
+	if now.Year() == year && int(now.Month()) == month {
+		return now.Day()
+	}
+
+	return DaysInMonth(year, month) - DaysLeftInPeriod(year, month, now)
+}
+
+func recurringKey(description, categoryName string) string {
+	return strings.ToLower(strings.TrimSpace(description)) + "\x00" + category.Key(categoryName)
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func day(year, month, d int) time.Time {
+	return time.Date(year, time.Month(month), d, 12, 0, 0, 0, time.UTC)
+}
+
+func forecastExpenses() []expense.Expense {
+	return []expense.Expense{
+		{Amount: 15, Description: "Netflix", Category: "Fun", Date: day(2025, 7, 3)},
+		{Amount: 15, Description: "Netflix", Category: "Fun", Date: day(2025, 8, 3)},
+		{Amount: 900, Description: "Rent", Category: "Home", Date: day(2025, 7, 1)},
+		{Amount: 950, Description: "rent ", Category: "Home", Date: day(2025, 8, 1)},
+		{Amount: 950, Description: "Rent", Category: "Home", Date: day(2025, 9, 1)},
+		{Amount: 40, Description: "Lidl", Category: "Food", Date: day(2025, 9, 2)},
+		{Amount: 60, Description: "Lidl", Category: "Food", Date: day(2025, 9, 8)},
+		{Amount: 1000, Description: "Lidl", Category: "Food", Date: day(2025, 9, 9), IsDeleted: true},
+	}
+}
+
+func TestRecurringItems(t *testing.T) {
+	items := RecurringItems(forecastExpenses(), 2025, 9)
+	if len(items) != 2 {
+		t.Fatalf("RecurringItems() count = %v, want 2", len(items))
+	}
+
+	if items[0].Description != "Netflix" || items[0].IsPaid || items[0].Amount != 15 {
+		t.Errorf("RecurringItems() netflix = %+v", items[0])
+	}
+
+	if items[1].Category != "Home" || !items[1].IsPaid || items[1].Amount != 950 {
+		t.Errorf("RecurringItems() rent = %+v", items[1])
+	}
+}
+
+func TestForecast(t *testing.T) {
+	budgets := []budget.Budget{
+		{Month: 9, Year: 2025, Category: "Food", Limit: 250},
+		{Month: 9, Year: 2025, Category: "Travel", Limit: 100},
+	}
+	now := day(2025, 9, 10)
+
+	lines := Forecast(forecastExpenses(), budgets, 2025, 9, "", now)
+
+	got := map[string]ForecastLine{}
+	for _, line := range lines {
+		got[line.Category] = line
+	}
+
+	tests := []struct {
+		category   string
+		spent      float64
+		forecast   float64
+		overBudget bool
+	}{
+		// 100 spent in 10 days of a 30-day month
+		{"Food", 100, 300, true},
+		// recurring item not yet paid this month
+		{"Fun", 0, 15, false},
+		// recurring item already paid is not extrapolated
+		{"Home", 950, 950, false},
+		// budget with no spending yet
+		{"Travel", 0, 0, false},
+	}
+
+	if len(got) != len(tests) {
+		t.Fatalf("Forecast() count = %v, want %v", len(got), len(tests))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			line, ok := got[tt.category]
+			if !ok {
+				t.Fatalf("Forecast() missing category %v", tt.category)
+			}
+			if line.Spent != tt.spent {
+				t.Errorf("Forecast() spent = %v, want %v", line.Spent, tt.spent)
+			}
+			if line.Forecast != tt.forecast {
+				t.Errorf("Forecast() forecast = %v, want %v", line.Forecast, tt.forecast)
+			}
+			if line.OverBudget != tt.overBudget {
+				t.Errorf("Forecast() over budget = %v, want %v", line.OverBudget, tt.overBudget)
+			}
+		})
+	}
+}
+
+func TestForecastCategoryFilter(t *testing.T) {
+	lines := Forecast(forecastExpenses(), nil, 2025, 9, "Fun", day(2025, 9, 10))
+	if len(lines) != 1 || lines[0].Category != "Fun" {
+		t.Errorf("Forecast() with category filter = %+v", lines)
+	}
+}
+
+func TestForecastParentBudget(t *testing.T) {
+	expenses := []expense.Expense{
+		{Amount: 30, Description: "Lidl", Category: "Food:Groceries", Date: day(2025, 9, 2)},
+		{Amount: 20, Description: "Pizza", Category: "food:takeaway", Date: day(2025, 9, 3)},
+	}
+	budgets := []budget.Budget{{Month: 9, Year: 2025, Category: "Food", Limit: 120}}
+
+	lines := Forecast(expenses, budgets, 2025, 9, "", day(2025, 9, 10))
+	if len(lines) != 3 {
+		t.Fatalf("Forecast() count = %v, want 3", len(lines))
+	}
+
+	food := lines[0]
+	if food.Category != "Food" || !food.HasBudget {
+		t.Fatalf("Forecast() first line = %+v", food)
+	}
+	if food.Spent != 50 || food.Forecast != 150 || !food.OverBudget {
+		t.Errorf("Forecast() parent roll-up = %+v", food)
+	}
+}
@@ -0,0 +1,347 @@
+package report
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Depth    int     `json:"depth"`
+	Own      float64 `json:"own"`
+	Total    float64 `json:"total"`
+}
+
+type MonthTotal struct {
+	Year   int     `json:"year"`
+	Month  int     `json:"month"`
+	Spent  float64 `json:"spent"`
+	Income float64 `json:"income"`
+	Count  int     `json:"count"`
+}
+
+type BudgetLine struct {
+	Year           int     `json:"year"`
+	Month          int     `json:"month"`
+	Category       string  `json:"category"`
+	Limit          float64 `json:"limit"`
+	Spent          float64 `json:"spent"`
+	Remaining      float64 `json:"remaining"`
+	PercentUsed    float64 `json:"percent_used"`
+	DaysLeft       int     `json:"days_left"`
+	DailyAllowance float64 `json:"daily_allowance"`
+}
+
+/**
+* A budget that has just reached one of the alert thresholds, in percent
+* of its limit.
+ */
+type Crossing struct {
+	BudgetLine
+	Threshold float64 `json:"threshold"`
+}
+
+// The percentages of a budget limit at which spending is worth an alert
+var BUDGET_THRESHOLDS = []float64{80, 100}
+
+type PeriodTotals struct {
+	Expenses float64
+	Income   float64
+	// The spending of the period, e.g. to be totalled per category
+	Spending []expense.Expense
+}
+
+/**
+* Builds a budget-vs-actual report for every budget set in the given period.
+* Deleted expenses are never counted towards spending, and a budget set on a
+* parent category covers all of its subcategories.
+*
+* @param expenses All known expenses.
+* @param budgets All known budgets.
+* @param year The year of the period.
+* @param month The month of the period (1-12).
+* @param categoryFilter Optional category filter; empty string means all categories.
+* @param now The current time, used to compute the days left in the period.
+* @return One line per budget, ordered by category.
+ */
+func BudgetVsActual(
+	expenses []expense.Expense,
+	budgets []budget.Budget,
+	year, month int,
+	categoryFilter string,
+	now time.Time,
+) []BudgetLine {
+	daysLeft := DaysLeftInPeriod(year, month, now)
+	lines := []BudgetLine{}
+
+	for _, b := range budgets {
+		if b.Year != year || b.Month != month {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(b.Category, categoryFilter) {
+			continue
+		}
+
+		spent := SpentInPeriod(expenses, year, month, b.Category)
+
+		line := BudgetLine{
+			Year:      year,
+			Month:     month,
+			Category:  b.Category,
+			Limit:     b.Limit,
+			Spent:     spent,
+			Remaining: b.Limit - spent,
+			DaysLeft:  daysLeft,
+		}
+
+		if b.Limit > 0 {
+			line.PercentUsed = spent / b.Limit * 100
+		}
+
+		if daysLeft > 0 && line.Remaining > 0 {
+			line.DailyAllowance = line.Remaining / float64(daysLeft)
+		}
+
+		lines = append(lines, line)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Category < lines[j].Category
+	})
+
+	return lines
+}
+
+/**
+* Sums spending recorded in the given period.
+*
+* @param categoryFilter Optional category filter including its subcategories; empty string means all categories.
+ */
+func SpentInPeriod(expenses []expense.Expense, year, month int, categoryFilter string) float64 {
+	total := 0.0
+
+	for _, exp := range expenses {
+		if !IsInPeriod(exp, year, month) {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(exp.Category, categoryFilter) {
+			continue
+		}
+
+		total += exp.Amount
+	}
+
+	return total
+}
+
+/**
+* Totals spending and income of a period, as reported by `summary`.
+* Deleted expenses never count.
+*
+* @param year The year of the period, or -1 for all time.
+* @param month The month of the period (1-12), or -1 for the whole year.
+* @param categoryFilter Optional category filter including its subcategories; empty string means all categories.
+ */
+func Totals(expenses []expense.Expense, year, month int, categoryFilter string) PeriodTotals {
+	totals := PeriodTotals{Spending: []expense.Expense{}}
+
+	for _, exp := range expenses {
+		if exp.IsDeleted {
+			continue
+		}
+
+		if year != -1 && exp.Date.Year() != year {
+			continue
+		}
+
+		if month != -1 && int(exp.Date.Month()) != month {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(exp.Category, categoryFilter) {
+			continue
+		}
+
+		if exp.IsIncome {
+			totals.Income += exp.Amount
+			continue
+		}
+
+		totals.Expenses += exp.Amount
+		totals.Spending = append(totals.Spending, exp)
+	}
+
+	return totals
+}
+
+/**
+* Totals spending per category and rolls every total up into its
+* parents. Category spellings are mapped onto the registry first, so "food"
+* and "Food " end up in the same bucket.
+*
+* @param expenses The expenses to total, already filtered by the caller.
+* @param registry The registered categories.
+* @return One line per category with its own and rolled-up totals, parents first.
+ */
+func CategoryTotals(expenses []expense.Expense, registry []category.Category) []CategoryTotal {
+	totals := map[string]*CategoryTotal{}
+	getTotal := func(name string) *CategoryTotal {
+		key := category.Key(name)
+		if _, ok := totals[key]; !ok {
+			totals[key] = &CategoryTotal{Category: name, Depth: category.Depth(name)}
+		}
+		return totals[key]
+	}
+
+	for _, exp := range expenses {
+		if !expense.IsSpending(exp) {
+			continue
+		}
+
+		name := category.Resolve(registry, exp.Category)
+
+		getTotal(name).Own += exp.Amount
+		for _, ancestor := range append(category.Ancestors(name), name) {
+			getTotal(ancestor).Total += exp.Amount
+		}
+	}
+
+	result := []CategoryTotal{}
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return category.Key(result[i].Category) < category.Key(result[j].Category)
+	})
+
+	return result
+}
+
+/**
+* Totals spending and income per calendar month. Deleted expenses are skipped.
+*
+* @param expenses The expenses to total, already filtered by the caller.
+* @return One line per month that has any expense or income, oldest first.
+ */
+func MonthlyTotals(expenses []expense.Expense) []MonthTotal {
+	totals := map[[2]int]*MonthTotal{}
+
+	for _, exp := range expenses {
+		if exp.IsDeleted {
+			continue
+		}
+
+		key := [2]int{exp.Date.Year(), int(exp.Date.Month())}
+		if _, ok := totals[key]; !ok {
+			totals[key] = &MonthTotal{Year: key[0], Month: key[1]}
+		}
+
+		total := totals[key]
+		total.Count++
+		if exp.IsIncome {
+			total.Income += exp.Amount
+		} else {
+			total.Spent += exp.Amount
+		}
+	}
+
+	result := []MonthTotal{}
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Year != result[j].Year {
+			return result[i].Year < result[j].Year
+		}
+		return result[i].Month < result[j].Month
+	})
+
+	return result
+}
+
+/**
+* Reports whether an expense counts towards the given period.
+* Deleted expenses and incoming payments never count. A month of -1 matches the whole year.
+ */
+func IsInPeriod(exp expense.Expense, year, month int) bool {
+	if !expense.IsSpending(exp) {
+		return false
+	}
+
+	if exp.Date.Year() != year {
+		return false
+	}
+
+	return month == -1 || int(exp.Date.Month()) == month
+}
+
+/**
+* Finds the budgets a change has pushed over a threshold. Lines are matched
+* by year, month and category; a budget that passes several thresholds at
+* once is reported for the highest only.
+*
+* @param before The budget lines before the change.
+* @param after The budget lines after the change.
+* @param thresholds The thresholds in percent, in ascending order.
+ */
+func Crossings(before, after []BudgetLine, thresholds []float64) []Crossing {
+	previous := map[string]float64{}
+	for _, line := range before {
+		previous[crossingKey(line)] = line.PercentUsed
+	}
+
+	crossings := []Crossing{}
+	for _, line := range after {
+		was := previous[crossingKey(line)]
+
+		crossed := -1.0
+		for _, threshold := range thresholds {
+			if was < threshold && line.PercentUsed >= threshold {
+				crossed = threshold
+			}
+		}
+
+		if crossed >= 0 {
+			crossings = append(crossings, Crossing{BudgetLine: line, Threshold: crossed})
+		}
+	}
+
+	return crossings
+}
+
+func crossingKey(line BudgetLine) string {
+	return strconv.Itoa(line.Year) + "-" + strconv.Itoa(line.Month) + " " + category.Key(line.Category)
+}
+
+/**
+* Counts the days left in the period, including today.
+* Past periods have no days left; future periods have all of their days left.
+ */
+func DaysLeftInPeriod(year, month int, now time.Time) int {
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if !today.Before(end) {
+		return 0
+	}
+
+	if today.Before(start) {
+		return DaysInMonth(year, month)
+	}
+
+	return int(end.Sub(today).Hours() / 24)
+}
+
+func DaysInMonth(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
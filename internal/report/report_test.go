@@ -0,0 +1,265 @@
+package report
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func testExpenses() []expense.Expense {
+	return []expense.Expense{
+		{ID: 0, Amount: 100, Category: "Food", Date: time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 1, Amount: 50, Category: "Food", Date: time.Date(2025, 9, 5, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 2, Amount: 500, Category: "Food", Date: time.Date(2025, 9, 6, 10, 0, 0, 0, time.UTC), Month: 9, IsDeleted: true},
+		{ID: 3, Amount: 70, Category: "Food", Date: time.Date(2024, 9, 6, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 4, Amount: 30, Category: "Food", Date: time.Date(2025, 8, 30, 10, 0, 0, 0, time.UTC), Month: 8},
+		{ID: 5, Amount: 20, Category: "Transport", Date: time.Date(2025, 9, 7, 10, 0, 0, 0, time.UTC), Month: 9},
+	}
+}
+
+func TestSpentInPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		year     int
+		month    int
+		category string
+		want     float64
+	}{
+		{"Month with category", 2025, 9, "Food", 150},
+		{"Month without category", 2025, 9, "", 170},
+		{"Whole year", 2025, -1, "Food", 180},
+		{"Other year", 2024, 9, "Food", 70},
+		{"Empty period", 2025, 10, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SpentInPeriod(testExpenses(), tt.year, tt.month, tt.category)
+			if got != tt.want {
+				t.Errorf("SpentInPeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetVsActual(t *testing.T) {
+	budgets := []budget.Budget{
+		{Month: 9, Year: 2025, Category: "Transport", Limit: 100},
+		{Month: 9, Year: 2025, Category: "Food", Limit: 200},
+		{Month: 9, Year: 2024, Category: "Food", Limit: 999},
+		{Month: 8, Year: 2025, Category: "Food", Limit: 999},
+	}
+	now := time.Date(2025, 9, 21, 15, 0, 0, 0, time.UTC)
+
+	lines := BudgetVsActual(testExpenses(), budgets, 2025, 9, "", now)
+	if len(lines) != 2 {
+		t.Fatalf("BudgetVsActual() count = %v, want 2", len(lines))
+	}
+
+	food := lines[0]
+	if food.Category != "Food" {
+		t.Fatalf("BudgetVsActual() first category = %v, want Food", food.Category)
+	}
+	if food.Spent != 150 {
+		t.Errorf("BudgetVsActual() spent = %v, want 150", food.Spent)
+	}
+	if food.Remaining != 50 {
+		t.Errorf("BudgetVsActual() remaining = %v, want 50", food.Remaining)
+	}
+	if food.PercentUsed != 75 {
+		t.Errorf("BudgetVsActual() percent used = %v, want 75", food.PercentUsed)
+	}
+	if food.DaysLeft != 10 {
+		t.Errorf("BudgetVsActual() days left = %v, want 10", food.DaysLeft)
+	}
+	if food.DailyAllowance != 5 {
+		t.Errorf("BudgetVsActual() daily allowance = %v, want 5", food.DailyAllowance)
+	}
+
+	filtered := BudgetVsActual(testExpenses(), budgets, 2025, 9, "Transport", now)
+	if len(filtered) != 1 || filtered[0].Spent != 20 {
+		t.Errorf("BudgetVsActual() with category filter = %+v", filtered)
+	}
+}
+
+func TestBudgetVsActualOverspent(t *testing.T) {
+	budgets := []budget.Budget{{Month: 9, Year: 2025, Category: "Food", Limit: 100}}
+	now := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+
+	lines := BudgetVsActual(testExpenses(), budgets, 2025, 9, "", now)
+	if len(lines) != 1 {
+		t.Fatalf("BudgetVsActual() count = %v, want 1", len(lines))
+	}
+	if lines[0].Remaining != -50 {
+		t.Errorf("BudgetVsActual() remaining = %v, want -50", lines[0].Remaining)
+	}
+	if lines[0].DailyAllowance != 0 {
+		t.Errorf("BudgetVsActual() daily allowance = %v, want 0 when overspent", lines[0].DailyAllowance)
+	}
+}
+
+func TestDaysLeftInPeriod(t *testing.T) {
+	now := time.Date(2025, 9, 21, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		year  int
+		month int
+		want  int
+	}{
+		{"Current month", 2025, 9, 10},
+		{"Past month", 2025, 8, 0},
+		{"Future month", 2025, 10, 31},
+		{"Future leap February", 2028, 2, 29},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DaysLeftInPeriod(tt.year, tt.month, now); got != tt.want {
+				t.Errorf("DaysLeftInPeriod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoryTotals(t *testing.T) {
+	expenses := []expense.Expense{
+		{Amount: 10, Category: "Food"},
+		{Amount: 20, Category: "food:groceries"},
+		{Amount: 5, Category: "Food :Groceries "},
+		{Amount: 7, Category: "Food:Takeaway"},
+		{Amount: 100, Category: "Food", IsDeleted: true},
+		{Amount: 3, Category: "Transport"},
+	}
+	registry := []category.Category{{Name: "Food"}, {Name: "Food:Groceries"}}
+
+	totals := CategoryTotals(expenses, registry)
+
+	want := []CategoryTotal{
+		{Category: "Food", Depth: 0, Own: 10, Total: 42},
+		{Category: "Food:Groceries", Depth: 1, Own: 25, Total: 25},
+		{Category: "Food:Takeaway", Depth: 1, Own: 7, Total: 7},
+		{Category: "Transport", Depth: 0, Own: 3, Total: 3},
+	}
+
+	if len(totals) != len(want) {
+		t.Fatalf("CategoryTotals() = %+v", totals)
+	}
+
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Errorf("CategoryTotals()[%d] = %+v, want %+v", i, totals[i], want[i])
+		}
+	}
+}
+
+func TestBudgetVsActualParentBudget(t *testing.T) {
+	expenses := []expense.Expense{
+		{Amount: 10, Category: "Food", Date: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)},
+		{Amount: 20, Category: "food:Groceries", Date: time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)},
+		{Amount: 40, Category: "Foodstuff", Date: time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	budgets := []budget.Budget{{Month: 9, Year: 2025, Category: "Food", Limit: 100}}
+
+	lines := BudgetVsActual(expenses, budgets, 2025, 9, "", time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+	if len(lines) != 1 || lines[0].Spent != 30 {
+		t.Errorf("BudgetVsActual() with parent budget = %+v", lines)
+	}
+}
+
+func TestMonthlyTotals(t *testing.T) {
+	expenses := append(testExpenses(), expense.Expense{
+		Amount: 1000, Date: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), Month: 9, IsIncome: true,
+	})
+
+	totals := MonthlyTotals(expenses)
+
+	want := []MonthTotal{
+		{Year: 2024, Month: 9, Spent: 70, Count: 1},
+		{Year: 2025, Month: 8, Spent: 30, Count: 1},
+		{Year: 2025, Month: 9, Spent: 170, Income: 1000, Count: 4},
+	}
+
+	if len(totals) != len(want) {
+		t.Fatalf("MonthlyTotals() = %+v", totals)
+	}
+
+	for i := range want {
+		if totals[i] != want[i] {
+			t.Errorf("MonthlyTotals()[%d] = %+v, want %+v", i, totals[i], want[i])
+		}
+	}
+}
+
+func TestTotals(t *testing.T) {
+	expenses := append(testExpenses(), expense.Expense{
+		ID: 6, Amount: 1000, Category: "Salary", Date: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC), Month: 9, IsIncome: true,
+	})
+
+	tests := []struct {
+		name       string
+		year       int
+		month      int
+		category   string
+		wantSpent  float64
+		wantIncome float64
+		wantCount  int
+	}{
+		{"All time", -1, -1, "", 270, 1000, 5},
+		{"Whole year", 2025, -1, "", 200, 1000, 4},
+		{"Month with category", 2025, 9, "Food", 150, 0, 2},
+		{"Other year", 2024, -1, "", 70, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Totals(expenses, tt.year, tt.month, tt.category)
+			if got.Expenses != tt.wantSpent || got.Income != tt.wantIncome {
+				t.Errorf("Totals() = %v spent, %v income, want %v, %v", got.Expenses, got.Income, tt.wantSpent, tt.wantIncome)
+			}
+			if len(got.Spending) != tt.wantCount {
+				t.Errorf("Totals() spending count = %v, want %v", len(got.Spending), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestCrossings(t *testing.T) {
+	line := func(categoryName string, percent float64) BudgetLine {
+		return BudgetLine{Year: 2025, Month: 9, Category: categoryName, Limit: 100, Spent: percent, PercentUsed: percent}
+	}
+
+	tests := []struct {
+		name   string
+		before []BudgetLine
+		after  []BudgetLine
+		want   []float64
+	}{
+		{"Below every threshold", []BudgetLine{line("Food", 10)}, []BudgetLine{line("Food", 50)}, []float64{}},
+		{"Reaches a threshold", []BudgetLine{line("Food", 70)}, []BudgetLine{line("Food", 80)}, []float64{80}},
+		{"Already past", []BudgetLine{line("Food", 85)}, []BudgetLine{line("Food", 95)}, []float64{}},
+		{"Several at once", []BudgetLine{line("Food", 10)}, []BudgetLine{line("Food", 120)}, []float64{100}},
+		{"Falls back", []BudgetLine{line("Food", 120)}, []BudgetLine{line("Food", 20)}, []float64{}},
+		{"New budget", []BudgetLine{}, []BudgetLine{line("Food", 90)}, []float64{80}},
+		{"Category spelling", []BudgetLine{line("Food", 90)}, []BudgetLine{line("food", 95)}, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crossings := Crossings(tt.before, tt.after, BUDGET_THRESHOLDS)
+
+			got := []float64{}
+			for _, crossing := range crossings {
+				got = append(got, crossing.Threshold)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Crossings() thresholds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
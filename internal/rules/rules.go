@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+type Rule struct {
+	ID        int      `json:"id"`
+	Contains  string   `json:"contains"`
+	Regex     string   `json:"regex"`
+	MinAmount float64  `json:"min_amount"`
+	MaxAmount float64  `json:"max_amount"`
+	Weekday   string   `json:"weekday"`
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+}
+
+const (
+	DEFAULT_RULES_FILE_PATH = "./data/rules.json"
+)
+
+/**
+* Creates a rule with no conditions set. Amount bounds use -1 for "not set".
+ */
+func NewRule() Rule {
+	return Rule{
+		MinAmount: -1,
+		MaxAmount: -1,
+		Tags:      []string{},
+	}
+}
+
+func GetRules() ([]Rule, error) {
+	data, err := storage.GetFileData(DEFAULT_RULES_FILE_PATH)
+	if err != nil {
+		return []Rule{}, err
+	}
+
+	if len(data) < 1 {
+		return []Rule{}, nil
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return []Rule{}, err
+	}
+
+	return rules, nil
+}
+
+func SaveRules(rules []Rule) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.WriteFileData(DEFAULT_RULES_FILE_PATH, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/**
+* Validates a rule and appends it to the rule list with the next free ID.
+*
+* @return The stored rule, or an error if the rule is invalid or cannot be written.
+ */
+func AddRule(rule Rule) (Rule, error) {
+	if err := ValidateRule(rule); err != nil {
+		return Rule{}, err
+	}
+
+	rules, err := GetRules()
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule.ID = 0
+	for _, r := range rules {
+		rule.ID = max(rule.ID, r.ID+1)
+	}
+	rule.Weekday = strings.ToLower(rule.Weekday)
+
+	rules = append(rules, rule)
+	if err := SaveRules(rules); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func RemoveRule(id int) error {
+	rules, err := GetRules()
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(rules, func(r Rule) bool {
+		return r.ID == id
+	})
+	if idx == -1 {
+		return errors.New("cannot find rule with provided id")
+	}
+
+	rules = append(rules[:idx], rules[idx+1:]...)
+
+	return SaveRules(rules)
+}
+
+func ValidateRule(rule Rule) error {
+	if rule.Category == "" && len(rule.Tags) < 1 {
+		return errors.New("rule must set a category or tags")
+	}
+
+	if rule.Contains == "" && rule.Regex == "" && rule.MinAmount == -1 && rule.MaxAmount == -1 && rule.Weekday == "" {
+		return errors.New("rule must have at least one condition")
+	}
+
+	if rule.Regex != "" {
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return errors.New("invalid regex: " + err.Error())
+		}
+	}
+
+	if rule.MinAmount != -1 && rule.MaxAmount != -1 && rule.MinAmount > rule.MaxAmount {
+		return errors.New("min amount cannot be greater than max amount")
+	}
+
+	if rule.Weekday != "" && !isWeekday(rule.Weekday) {
+		return errors.New("invalid weekday")
+	}
+
+	return nil
+}
+
+/**
+* Reports whether every condition set on the rule holds for the expense.
+* Description matching is case-insensitive.
+ */
+func Matches(rule Rule, exp expense.Expense) bool {
+	description := strings.ToLower(exp.Description)
+
+	if rule.Contains != "" && !strings.Contains(description, strings.ToLower(rule.Contains)) {
+		return false
+	}
+
+	if rule.Regex != "" {
+		re, err := regexp.Compile("(?i)" + rule.Regex)
+		if err != nil || !re.MatchString(exp.Description) {
+			return false
+		}
+	}
+
+	if rule.MinAmount != -1 && exp.Amount < rule.MinAmount {
+		return false
+	}
+
+	if rule.MaxAmount != -1 && exp.Amount > rule.MaxAmount {
+		return false
+	}
+
+	if rule.Weekday != "" && !strings.EqualFold(exp.Date.Weekday().String(), rule.Weekday) {
+		return false
+	}
+
+	return true
+}
+
+/**
+* Finds the first rule, in the order they were added, that matches the expense.
+ */
+func FindMatch(rules []Rule, exp expense.Expense) (Rule, bool) {
+	for _, rule := range rules {
+		if Matches(rule, exp) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+/**
+* Categorises an expense with the first matching rule. The category is only
+* set when the expense has none, and the rule's tags are added to the existing ones.
+*
+* @return The updated expense and whether a rule matched.
+ */
+func Apply(rules []Rule, exp expense.Expense) (expense.Expense, bool) {
+	rule, ok := FindMatch(rules, exp)
+	if !ok {
+		return exp, false
+	}
+
+	if exp.Category == "" {
+		exp.Category = rule.Category
+	}
+
+	tags := slices.Clone(exp.Tags)
+	for _, tag := range rule.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	exp.Tags = tags
+
+	return exp, true
+}
+
+func isWeekday(name string) bool {
+	return slices.Contains([]string{
+		"sunday",
+		"monday",
+		"tuesday",
+		"wednesday",
+		"thursday",
+		"friday",
+		"saturday",
+	}, strings.ToLower(name))
+}
+
+/**
+* Runs the rules over every non-deleted expense that has no category yet;
+* incomes are left alone.
+* Expenses matched only by rules that add tags are left as they are.
+*
+* @param dryRun When true, only reports the matches without writing anything.
+* @return The expenses that were categorised, or an error if expenses cannot be read or written.
+ */
+func ApplyToUncategorized(dryRun bool) ([]expense.Expense, error) {
+	rules, err := GetRules()
+	if err != nil {
+		return []expense.Expense{}, err
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return []expense.Expense{}, err
+	}
+
+	changed := []expense.Expense{}
+	for i, exp := range expenses {
+		if exp.IsDeleted || exp.IsIncome || strings.TrimSpace(exp.Category) != "" {
+			continue
+		}
+
+		updated, ok := Apply(rules, exp)
+		if !ok || strings.TrimSpace(updated.Category) == "" {
+			continue
+		}
+
+		expenses[i] = updated
+		changed = append(changed, updated)
+	}
+
+	if dryRun || len(changed) < 1 {
+		return changed, nil
+	}
+
+	if err := expense.SaveExpenses(expenses); err != nil {
+		return []expense.Expense{}, err
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,204 @@
+package rules
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func newRule(modify func(r *Rule)) Rule {
+	rule := NewRule()
+	modify(&rule)
+	return rule
+}
+
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"Contains with category", newRule(func(r *Rule) { r.Contains = "uber"; r.Category = "Transport" }), false},
+		{"Tags only", newRule(func(r *Rule) { r.Weekday = "Friday"; r.Tags = []string{"weekend"} }), false},
+		{"No action", newRule(func(r *Rule) { r.Contains = "uber" }), true},
+		{"No condition", newRule(func(r *Rule) { r.Category = "Transport" }), true},
+		{"Invalid regex", newRule(func(r *Rule) { r.Regex = "("; r.Category = "Transport" }), true},
+		{"Inverted amounts", newRule(func(r *Rule) { r.MinAmount = 10; r.MaxAmount = 5; r.Category = "Transport" }), true},
+		{"Invalid weekday", newRule(func(r *Rule) { r.Weekday = "someday"; r.Category = "Transport" }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	// 2025-09-12 is a Friday
+	exp := expense.Expense{
+		Description: "UBER trip to airport",
+		Amount:      35,
+		Date:        time.Date(2025, 9, 12, 18, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"Contains is case-insensitive", newRule(func(r *Rule) { r.Contains = "uber" }), true},
+		{"Contains mismatch", newRule(func(r *Rule) { r.Contains = "lidl" }), false},
+		{"Regex", newRule(func(r *Rule) { r.Regex = `^uber\b` }), true},
+		{"Regex mismatch", newRule(func(r *Rule) { r.Regex = `airport$x` }), false},
+		{"Amount in range", newRule(func(r *Rule) { r.MinAmount = 30; r.MaxAmount = 40 }), true},
+		{"Amount below range", newRule(func(r *Rule) { r.MinAmount = 40 }), false},
+		{"Amount above range", newRule(func(r *Rule) { r.MaxAmount = 30 }), false},
+		{"Weekday", newRule(func(r *Rule) { r.Weekday = "friday" }), true},
+		{"Weekday mismatch", newRule(func(r *Rule) { r.Weekday = "monday" }), false},
+		{"All conditions", newRule(func(r *Rule) { r.Contains = "uber"; r.MaxAmount = 50; r.Weekday = "Friday" }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.rule, exp); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	rules := []Rule{
+		newRule(func(r *Rule) { r.Contains = "netflix"; r.Category = "Subscriptions"; r.Tags = []string{"monthly"} }),
+		newRule(func(r *Rule) { r.Contains = "net"; r.Category = "Internet" }),
+	}
+
+	exp, ok := Apply(rules, expense.Expense{Description: "Netflix", Tags: []string{"shared", "monthly"}})
+	if !ok {
+		t.Fatalf("Apply() should match")
+	}
+	if exp.Category != "Subscriptions" {
+		t.Errorf("Apply() category = %v, want the first matching rule", exp.Category)
+	}
+	if len(exp.Tags) != 2 {
+		t.Errorf("Apply() tags = %v, want no duplicates", exp.Tags)
+	}
+
+	exp, _ = Apply(rules, expense.Expense{Description: "Netflix", Category: "Fun"})
+	if exp.Category != "Fun" {
+		t.Errorf("Apply() should keep an existing category, got %v", exp.Category)
+	}
+
+	if _, ok := Apply(rules, expense.Expense{Description: "Lidl"}); ok {
+		t.Errorf("Apply() should not match")
+	}
+}
+
+func TestAddAndRemoveRule(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	first, err := AddRule(newRule(func(r *Rule) { r.Contains = "uber"; r.Category = "Transport"; r.Weekday = "Friday" }))
+	if err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if first.Weekday != "friday" {
+		t.Errorf("AddRule() weekday = %v, want lowercase", first.Weekday)
+	}
+
+	second, err := AddRule(newRule(func(r *Rule) { r.Contains = "lidl"; r.Category = "Food" }))
+	if err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if second.ID != first.ID+1 {
+		t.Errorf("AddRule() id = %v, want %v", second.ID, first.ID+1)
+	}
+
+	if _, err := AddRule(NewRule()); err == nil {
+		t.Errorf("AddRule() should fail for an invalid rule")
+	}
+
+	if err := RemoveRule(first.ID); err != nil {
+		t.Errorf("RemoveRule() error = %v", err)
+	}
+	if err := RemoveRule(first.ID); err == nil {
+		t.Errorf("RemoveRule() should fail for a removed rule")
+	}
+
+	rules, _ := GetRules()
+	if len(rules) != 1 || rules[0].ID != second.ID {
+		t.Errorf("GetRules() = %+v", rules)
+	}
+}
+
+func TestApplyToUncategorized(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	expenses := []expense.Expense{
+		{ID: 0, Description: "Uber", Amount: 10},
+		{ID: 1, Description: "Uber", Amount: 10, Category: "Work"},
+		{ID: 2, Description: "Uber", Amount: 10, IsDeleted: true},
+		{ID: 3, Description: "Lidl", Amount: 10},
+		{ID: 4, Description: "Uber refund", Amount: 10, IsIncome: true},
+	}
+	data, _ := json.Marshal(expenses)
+	os.WriteFile("./data/expenses.json", data, 0755)
+
+	if _, err := AddRule(newRule(func(r *Rule) { r.Contains = "uber"; r.Category = "Transport" })); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if _, err := AddRule(newRule(func(r *Rule) { r.Contains = "lidl"; r.Tags = []string{"shop"} })); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+
+	changed, err := ApplyToUncategorized(true)
+	if err != nil {
+		t.Fatalf("ApplyToUncategorized() error = %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != 0 {
+		t.Errorf("ApplyToUncategorized() changed = %+v", changed)
+	}
+
+	stored, _ := expense.GetExpenses()
+	if stored[0].Category != "" {
+		t.Errorf("ApplyToUncategorized() dry run should not write expenses")
+	}
+
+	if _, err := ApplyToUncategorized(false); err != nil {
+		t.Fatalf("ApplyToUncategorized() error = %v", err)
+	}
+
+	stored, _ = expense.GetExpenses()
+	if stored[0].Category != "Transport" || stored[2].Category != "" || stored[3].Category != "" || len(stored[3].Tags) != 0 || stored[4].Category != "" {
+		t.Errorf("ApplyToUncategorized() stored = %+v", stored)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+	// Create empty rules.json and expenses.json files
+	for _, fileName := range []string{"./data/rules.json", "./data/expenses.json"} {
+		if err := os.WriteFile(fileName, []byte("[]"), 0755); err != nil {
+			t.Fatalf("Failed to create test data file: %v", err)
+		}
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
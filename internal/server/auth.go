@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+)
+
+type userKey struct{}
+
+/**
+* Checks the bearer token of an API request once any token has been created;
+* until then the API is open and changes are not attributed to anyone.
+*
+* @return The request, carrying the user of the token, or a 401 or 403 error.
+ */
+func authorize(r *http.Request) (*http.Request, error) {
+	tokens, err := auth.GetTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.IsEnabled(tokens) {
+		return r, nil
+	}
+
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(secret) == "" {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "missing bearer token"}
+	}
+
+	token, ok := auth.Authenticate(tokens, strings.TrimSpace(secret))
+	if !ok {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "invalid or revoked token"}
+	}
+
+	if !auth.Allows(token, r.Method) {
+		return nil, &Error{Status: http.StatusForbidden, Message: "token is read-only"}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), userKey{}, token.User)), nil
+}
+
+/**
+* Returns the user of the token a request was made with, or an empty string
+* if the API is open.
+ */
+func requestUser(r *http.Request) string {
+	user, _ := r.Context().Value(userKey{}).(string)
+
+	return user
+}
+
+/**
+* Records a change made through the API in the audit log.
+ */
+func record(r *http.Request, action, target, details string) error {
+	return audit.Record(audit.Entry{
+		User:    requestUser(r),
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+}
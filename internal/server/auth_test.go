@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+func doWithToken(t *testing.T, s *Server, method, target, token string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("Failed to encode request body: %v", err)
+		}
+	}
+
+	req := newTestRequest(method, target, &payload)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func createToken(t *testing.T, user, scope string) string {
+	t.Helper()
+
+	_, secret, err := auth.CreateToken(user, scope)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	return secret
+}
+
+func TestAPIIsOpenWithoutTokens(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 5, "description": "Coffee"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/expenses = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+
+	if created := decode[expense.Expense](t, rec); created.CreatedBy != "" {
+		t.Errorf("CreatedBy = %q, want empty without tokens", created.CreatedBy)
+	}
+
+	// Revoking the last token keeps authentication on
+	token, _, err := auth.CreateToken("alice", auth.SCOPE_WRITE)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+	if _, err := auth.RevokeToken(token.ID); err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+
+	if rec := do(t, s, http.MethodGet, "/api/expenses", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/expenses = %v, want 401", rec.Code)
+	}
+	if rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 5, "description": "Coffee"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/expenses = %v, want 401", rec.Code)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	writer := createToken(t, "alice", auth.SCOPE_WRITE)
+	reader := createToken(t, "bob", auth.SCOPE_READ)
+	s := newTestServer()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header string
+		body   any
+		want   int
+	}{
+		{"No token", http.MethodGet, "/api/expenses", "", nil, http.StatusUnauthorized},
+		{"Not a bearer token", http.MethodGet, "/api/expenses", "Basic " + writer, nil, http.StatusUnauthorized},
+		{"Unknown token", http.MethodGet, "/api/expenses", "Bearer et_unknown", nil, http.StatusUnauthorized},
+		{"Read-only token reads", http.MethodGet, "/api/summary", "Bearer " + reader, nil, http.StatusOK},
+		{"Read-only token writes", http.MethodDelete, "/api/expenses/0", "Bearer " + reader, nil, http.StatusForbidden},
+		{"Read-write token writes", http.MethodPut, "/api/budgets", "Bearer " + writer, map[string]any{"month": 9, "category": "Food", "limit": 100}, http.StatusOK},
+		{"Unknown endpoint", http.MethodGet, "/api/unknown", "", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload bytes.Buffer
+			if tt.body != nil {
+				json.NewEncoder(&payload).Encode(tt.body)
+			}
+
+			req := newTestRequest(tt.method, tt.target, &payload)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("%s %s = %v, want %v: %s", tt.method, tt.target, rec.Code, tt.want, rec.Body.String())
+			}
+
+			if rec.Code == http.StatusUnauthorized && !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+				t.Errorf("WWW-Authenticate = %q, want a Bearer challenge", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	// The dashboard itself is served without a token; it asks for one
+	if rec := do(t, s, http.MethodGet, "/", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET / = %v, want 200", rec.Code)
+	}
+}
+
+func TestChangesAreAttributed(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	alice := createToken(t, "alice", auth.SCOPE_WRITE)
+	bob := createToken(t, "bob", auth.SCOPE_WRITE)
+	s := newTestServer()
+
+	rec := doWithToken(t, s, http.MethodPost, "/api/expenses", alice, map[string]any{"amount": 12.5, "description": "Lunch"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/expenses = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+	created := decode[expense.Expense](t, rec)
+	if created.CreatedBy != "alice" || created.UpdatedBy != "" {
+		t.Errorf("created by %q, updated by %q, want alice and nobody", created.CreatedBy, created.UpdatedBy)
+	}
+
+	rec = doWithToken(t, s, http.MethodPatch, "/api/expenses/0", bob, map[string]any{"notes": "weekly shop", "amount": 45})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH /api/expenses/0 = %v, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if updated := decode[expense.Expense](t, rec); updated.UpdatedBy != "bob" {
+		t.Errorf("updated by %q, want bob", updated.UpdatedBy)
+	}
+
+	for range 2 {
+		if rec := doWithToken(t, s, http.MethodDelete, "/api/expenses/1", alice, nil); rec.Code != http.StatusNoContent {
+			t.Fatalf("DELETE /api/expenses/1 = %v, want 204", rec.Code)
+		}
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		t.Fatalf("Failed to read expenses: %v", err)
+	}
+	if expenses[1].UpdatedBy != "alice" || !expenses[1].IsDeleted {
+		t.Errorf("expense 1 = %+v, want deleted by alice", expenses[1])
+	}
+
+	if rec := doWithToken(t, s, http.MethodPost, "/api/categories", bob, map[string]any{"name": "Home"}); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/categories = %v, want 201", rec.Code)
+	}
+
+	// Reads and rejected requests are not recorded
+	doWithToken(t, s, http.MethodGet, "/api/expenses", alice, nil)
+	doWithToken(t, s, http.MethodDelete, "/api/expenses/2", "", nil)
+
+	entries, err := audit.GetEntries()
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+
+	want := []audit.Entry{
+		{User: "alice", Action: audit.ACTION_EXPENSE_CREATE, Target: "expense 5", Details: "12.50 Lunch"},
+		{User: "bob", Action: audit.ACTION_EXPENSE_UPDATE, Target: "expense 0", Details: "amount, notes"},
+		{User: "alice", Action: audit.ACTION_EXPENSE_DELETE, Target: "expense 1"},
+		{User: "bob", Action: audit.ACTION_CATEGORY_ADD, Target: "category Home"},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("audit log = %+v, want %d entries", entries, len(want))
+	}
+
+	for i, entry := range entries {
+		entry.Time = want[i].Time
+		if entry != want[i] {
+			t.Errorf("audit entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+}
+
+func TestAuthResponsesMatchOpenAPI(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	writer := createToken(t, "alice", auth.SCOPE_WRITE)
+	reader := createToken(t, "bob", auth.SCOPE_READ)
+	spec := loadSpec(t)
+	s := newTestServer()
+
+	for _, route := range s.routes() {
+		name := route.Method + " " + route.Path
+		_, operation, ok := spec.operation(route.Method, route.Path)
+		if !ok {
+			t.Errorf("%s is not documented", name)
+			continue
+		}
+
+		target := strings.NewReplacer("{id}", "0", "{name}", "Food").Replace(route.Path)
+
+		rec := doWithToken(t, s, route.Method, target, "", nil)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s without a token = %v, want 401", name, rec.Code)
+		}
+		for _, problem := range spec.checkResponse(operation, rec) {
+			t.Errorf("%s without a token: %s", name, problem)
+		}
+
+		if route.Method == http.MethodGet {
+			continue
+		}
+
+		rec = doWithToken(t, s, route.Method, target, reader, nil)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s with a read-only token = %v, want 403", name, rec.Code)
+		}
+		for _, problem := range spec.checkResponse(operation, rec) {
+			t.Errorf("%s with a read-only token: %s", name, problem)
+		}
+	}
+
+	_, operation, _ := spec.operation(http.MethodPost, "/api/expenses")
+	rec := doWithToken(t, s, http.MethodPost, "/api/expenses", writer, map[string]any{"amount": 3, "description": "Tea"})
+	for _, problem := range spec.checkResponse(operation, rec) {
+		t.Errorf("POST /api/expenses with a token: %s", problem)
+	}
+
+	_, operation, _ = spec.operation(http.MethodPatch, "/api/expenses/{id}")
+	rec = doWithToken(t, s, http.MethodPatch, "/api/expenses/0", writer, map[string]any{"notes": "edited"})
+	for _, problem := range spec.checkResponse(operation, rec) {
+		t.Errorf("PATCH /api/expenses/0 with a token: %s", problem)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+/**
+* The body of PUT /api/budgets. As with `budget set`, the budget is set for
+* the given month of the current year.
+ */
+type BudgetInput struct {
+	Month    int     `json:"month"`
+	Category string  `json:"category"`
+	Limit    float64 `json:"limit"`
+}
+
+/**
+* GET /api/budgets: lists every budget.
+ */
+func (s *Server) listBudgets(w http.ResponseWriter, r *http.Request) error {
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, budgets)
+}
+
+/**
+* PUT /api/budgets: sets or replaces the budget of a month and category.
+ */
+func (s *Server) setBudget(w http.ResponseWriter, r *http.Request) error {
+	var input BudgetInput
+	if err := readJSON(w, r, &input); err != nil {
+		return err
+	}
+
+	categoryName := input.Category
+	if category.Normalize(categoryName) != "" {
+		canonical, err := category.Register(categoryName)
+		if err != nil {
+			return err
+		}
+		categoryName = canonical
+	}
+
+	snapshot, err := webhook.TakeSnapshot(time.Date(s.now().Year(), time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+
+	if err := budget.SetBudget(input.Month, categoryName, input.Limit); err != nil {
+		return badRequest(err.Error())
+	}
+
+	if err := s.notify(snapshot, "", nil); err != nil {
+		return err
+	}
+
+	b, err := budget.GetBudget(input.Month, categoryName)
+	if err != nil {
+		return err
+	}
+
+	details := "limit " + strconv.FormatFloat(b.Limit, 'f', 2, 64)
+	if err := record(r, audit.ACTION_BUDGET_SET, budgetTarget(b.Month, b.Category), details); err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, b)
+}
+
+/**
+* DELETE /api/budgets?month=&category=: removes the budget of a month and
+* category.
+ */
+func (s *Server) removeBudget(w http.ResponseWriter, r *http.Request) error {
+	month, _, err := queryPeriod(r)
+	if err != nil {
+		return err
+	}
+	if month == -1 {
+		return badRequest("query parameter 'month' is required")
+	}
+
+	categoryName, err := category.Canonical(r.URL.Query().Get("category"))
+	if err != nil {
+		return err
+	}
+
+	if _, err := budget.GetBudget(month, categoryName); err != nil {
+		return notFound(err.Error())
+	}
+
+	if err := budget.RemoveBudget(month, categoryName); err != nil {
+		return err
+	}
+
+	if err := record(r, audit.ACTION_BUDGET_REMOVE, budgetTarget(month, categoryName), ""); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func budgetTarget(month int, categoryName string) string {
+	target := "budget " + strconv.Itoa(month)
+	if categoryName != "" {
+		target += " " + categoryName
+	}
+
+	return target
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+)
+
+type CategoryInput struct {
+	Name string `json:"name"`
+}
+
+/**
+* GET /api/categories: lists the registered categories, parents first.
+ */
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request) error {
+	categories, err := category.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, categories)
+}
+
+/**
+* POST /api/categories: registers a category and its missing parents.
+ */
+func (s *Server) addCategory(w http.ResponseWriter, r *http.Request) error {
+	var input CategoryInput
+	if err := readJSON(w, r, &input); err != nil {
+		return err
+	}
+
+	if category.Normalize(input.Name) == "" {
+		return badRequest("category name is required")
+	}
+
+	registered, err := isCategoryRegistered(input.Name)
+	if err != nil {
+		return err
+	}
+	if registered {
+		return conflict("category already exists")
+	}
+
+	canonical, err := category.AddCategory(input.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := record(r, audit.ACTION_CATEGORY_ADD, "category "+canonical, ""); err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusCreated, category.Category{Name: canonical})
+}
+
+/**
+* DELETE /api/categories/{name}: removes a category that has no subcategories.
+* The name is URL-escaped, e.g. /api/categories/Food:Fast%20food.
+ */
+func (s *Server) removeCategory(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+
+	registered, err := isCategoryRegistered(name)
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return notFound("category not found")
+	}
+
+	if err := category.RemoveCategory(name); err != nil {
+		return conflict(err.Error())
+	}
+
+	if err := record(r, audit.ACTION_CATEGORY_REMOVE, "category "+name, ""); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func isCategoryRegistered(name string) (bool, error) {
+	categories, err := category.GetCategories()
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(categories, func(c category.Category) bool {
+		return category.Equal(c.Name, name)
+	}), nil
+}
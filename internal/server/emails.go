@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail"
+)
+
+const (
+	// How often SendEmails looks for a statement that is due
+	EMAIL_POLL_INTERVAL = time.Hour
+)
+
+/**
+* Sends emails until ctx is done: the statement of a month once the month
+* is over, and an alert right after a request pushes budgets over a
+* threshold. Like DeliverWebhooks it runs apart from the requests. A
+* statement that fails is tried again later, while a failed alert is
+* dropped; every failure is passed to onError.
+ */
+func (s *Server) SendEmails(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(EMAIL_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sendDueStatement(); err != nil {
+			onError(err)
+		}
+
+		if err := s.sendAlerts(); err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.emails:
+		}
+	}
+}
+
+func (s *Server) sendDueStatement() error {
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	year, month, ok := mail.DueStatement(config, s.now())
+	if !ok {
+		return nil
+	}
+
+	password, err := mail.Password(config)
+	if err != nil {
+		return err
+	}
+
+	// The data files are read under the lock, the email is sent without it
+	s.mu.Lock()
+	message, err := mail.StatementMessage(year, month, s.now())
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := mail.Send(config, password, message); err != nil {
+		return err
+	}
+
+	return mail.MarkStatementSent(year, month)
+}
+
+func (s *Server) sendAlerts() error {
+	s.alertsMu.Lock()
+	crossings := s.alerts
+	s.alerts = nil
+	s.alertsMu.Unlock()
+
+	if len(crossings) < 1 {
+		return nil
+	}
+
+	config, err := mail.GetConfig()
+	if err != nil {
+		return err
+	}
+
+	if !mail.Wants(config, mail.EVENT_ALERT) {
+		return nil
+	}
+
+	password, err := mail.Password(config)
+	if err != nil {
+		return err
+	}
+
+	message, err := mail.AlertMessage(crossings)
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(config, password, message)
+}
+
+func (s *Server) wakeEmails() {
+	select {
+	case s.emails <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail"
+	"github.com/dmitriy-zverev/expense-tracker/internal/mail/mailtest"
+)
+
+func TestEmailsAreSent(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	smtp := mailtest.NewServer(t)
+
+	// The test server lives in September 2025, so the statement of August
+	// is due as soon as the loop starts
+	config := mail.Config{
+		Host:          smtp.Host,
+		Port:          smtp.Port,
+		From:          "et@example.com",
+		To:            []string{"me@example.com"},
+		LastStatement: "2025-07",
+	}
+	if err := mail.SaveConfig(config); err != nil {
+		t.Fatalf("Failed to save email config: %v", err)
+	}
+
+	// Budgets are checked for the month of the expense in the current year
+	now := time.Now().UTC()
+	data, _ := json.Marshal([]budget.Budget{{Month: int(now.Month()), Year: now.Year(), Category: "Food", Limit: 20}})
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	s := newTestServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	errs := make(chan error, 10)
+	go func() {
+		s.SendEmails(ctx, func(err error) { errs <- err })
+		close(stopped)
+	}()
+	// Stop sending before the data directory is removed
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	waitForEmails := func(count int) []mailtest.Message {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			select {
+			case err := <-errs:
+				t.Fatalf("SendEmails() error = %v", err)
+			default:
+			}
+
+			if messages := smtp.Messages(); len(messages) >= count {
+				return messages
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		t.Fatalf("stand-in got %d emails, want %d", len(smtp.Messages()), count)
+		return nil
+	}
+
+	statement := waitForEmails(1)[0]
+	if !strings.Contains(statement.Data, "Subject: Expense statement for August 2025") || !strings.Contains(statement.Data, "60.00 $ in 1 expenses") {
+		t.Errorf("first email is not the statement of August:\n%s", statement.Data)
+	}
+
+	saved, err := mail.GetConfig()
+	if err != nil || saved.LastStatement != "2025-08" {
+		t.Errorf("LastStatement = %q, want 2025-08", saved.LastStatement)
+	}
+
+	rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 25, "description": "Dinner", "category": "Food", "date": now.Format(DATE_FORMAT)})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/expenses = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+
+	alert := waitForEmails(2)[1]
+	if !strings.Contains(alert.Data, "Subject: Budget alert: Food has reached 100% of its limit") {
+		t.Errorf("second email is not the alert:\n%s", alert.Data)
+	}
+
+	// A change that crosses nothing sends nothing
+	do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 5, "description": "Snack", "category": "Food", "date": now.Format(DATE_FORMAT)})
+	time.Sleep(100 * time.Millisecond)
+	if count := len(smtp.Messages()); count != 2 {
+		t.Errorf("stand-in got %d emails, want 2", count)
+	}
+}
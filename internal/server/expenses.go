@@ -0,0 +1,425 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/audit"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/duplicates"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/rules"
+	"github.com/dmitriy-zverev/expense-tracker/internal/utils"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+/**
+* One page of expenses. Total counts every expense that passes the filters,
+* not only the ones on the page.
+ */
+type ExpensePage struct {
+	Items  []expense.Expense `json:"items"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+/**
+* The body of POST and PATCH /api/expenses/{id}. On PATCH, fields that are
+* left out keep their value; tags are replaced as a whole when given.
+ */
+type ExpenseInput struct {
+	Amount      *float64 `json:"amount"`
+	Description *string  `json:"description"`
+	Category    *string  `json:"category"`
+	Date        *string  `json:"date"`
+	Tags        []string `json:"tags"`
+	Notes       *string  `json:"notes"`
+	IsIncome    *bool    `json:"is_income"`
+}
+
+// Lists the IDs of the expenses a newly added expense may duplicate
+const DUPLICATES_HEADER = "X-Possible-Duplicates"
+
+/**
+* GET /api/expenses: lists expenses, oldest first. Accepts the month, year
+* and category filters of `list`, plus from and to (YYYY-MM-DD, inclusive),
+* q (a case-insensitive description search), with_deleted, limit and offset.
+ */
+func (s *Server) listExpenses(w http.ResponseWriter, r *http.Request) error {
+	month, year, err := queryPeriod(r)
+	if err != nil {
+		return err
+	}
+
+	withDeleted, err := queryBool(r, "with_deleted")
+	if err != nil {
+		return err
+	}
+
+	from, err := queryDate(r, "from")
+	if err != nil {
+		return err
+	}
+
+	to, err := queryDate(r, "to")
+	if err != nil {
+		return err
+	}
+
+	limit, err := queryInt(r, "limit", DEFAULT_PAGE_LIMIT)
+	if err != nil {
+		return err
+	}
+	if limit < 1 || limit > MAX_PAGE_LIMIT {
+		return badRequest("query parameter 'limit' must be between 1 and " + strconv.Itoa(MAX_PAGE_LIMIT))
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		return badRequest("query parameter 'offset' must not be negative")
+	}
+
+	categoryFilter := r.URL.Query().Get("category")
+	search := strings.ToLower(r.URL.Query().Get("q"))
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	selected := []expense.Expense{}
+	for _, exp := range expenses {
+		if !withDeleted && exp.IsDeleted {
+			continue
+		}
+
+		if month != -1 && int(exp.Date.Month()) != month {
+			continue
+		}
+
+		if year != -1 && exp.Date.Year() != year {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(exp.Category, categoryFilter) {
+			continue
+		}
+
+		if !from.IsZero() && exp.Date.Before(from) {
+			continue
+		}
+
+		if !to.IsZero() && !exp.Date.Before(to.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		if search != "" && !strings.Contains(strings.ToLower(exp.Description), search) {
+			continue
+		}
+
+		selected = append(selected, exp)
+	}
+
+	// Clamped before adding, so a huge offset cannot overflow
+	start := min(offset, len(selected))
+	end := start + min(limit, len(selected)-start)
+
+	return writeJSON(w, http.StatusOK, ExpensePage{
+		Items:  selected[start:end],
+		Total:  len(selected),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+/**
+* POST /api/expenses: adds an expense the way `add` does. Without a category
+* the first matching rule sets it, and the category is registered if new.
+* Possible duplicates are listed in the X-Possible-Duplicates header.
+ */
+func (s *Server) createExpense(w http.ResponseWriter, r *http.Request) error {
+	var input ExpenseInput
+	if err := readJSON(w, r, &input); err != nil {
+		return err
+	}
+
+	if input.Amount == nil {
+		return badRequest("amount is required")
+	}
+
+	exp, err := expense.CreateExpenseObj(*input.Amount, "", "")
+	if err != nil {
+		return err
+	}
+	exp.Date = s.now()
+	exp.Month = int(exp.Date.Month())
+
+	if err := applyExpenseInput(&exp, input); err != nil {
+		return err
+	}
+
+	if !utils.IsExpenseValid(exp) {
+		return badRequest("not valid expense")
+	}
+
+	if exp.Category == "" {
+		allRules, err := rules.GetRules()
+		if err != nil {
+			return err
+		}
+
+		exp, _ = rules.Apply(allRules, exp)
+	}
+
+	if err := registerCategory(&exp); err != nil {
+		return err
+	}
+	exp.CreatedBy = requestUser(r)
+
+	existing, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := webhook.TakeSnapshot(exp.Date)
+	if err != nil {
+		return err
+	}
+
+	if err := expense.AddExpense(exp); err != nil {
+		return err
+	}
+
+	if err := s.notify(snapshot, webhook.EVENT_EXPENSE_CREATED, exp); err != nil {
+		return err
+	}
+
+	details := strconv.FormatFloat(exp.Amount, 'f', 2, 64) + " " + exp.Description
+	if err := record(r, audit.ACTION_EXPENSE_CREATE, expenseTarget(exp.ID), strings.TrimSpace(details)); err != nil {
+		return err
+	}
+
+	ids := []string{}
+	for _, pair := range duplicates.FindCandidates(existing, exp, duplicates.DefaultOptions()) {
+		ids = append(ids, strconv.Itoa(pair.Original.ID))
+	}
+	if len(ids) > 0 {
+		w.Header().Set(DUPLICATES_HEADER, strings.Join(ids, ","))
+	}
+
+	w.Header().Set("Location", "/api/expenses/"+strconv.Itoa(exp.ID))
+
+	return writeJSON(w, http.StatusCreated, exp)
+}
+
+/**
+* GET /api/expenses/{id}: returns an expense, deleted or not.
+ */
+func (s *Server) getExpense(w http.ResponseWriter, r *http.Request) error {
+	expenses, id, err := pathExpense(r)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, expenses[id])
+}
+
+/**
+* PATCH /api/expenses/{id}: changes the given fields of an expense.
+* Deleted expenses cannot be changed.
+ */
+func (s *Server) updateExpense(w http.ResponseWriter, r *http.Request) error {
+	expenses, id, err := pathExpense(r)
+	if err != nil {
+		return err
+	}
+
+	if expenses[id].IsDeleted {
+		return conflict("expense with provided id is deleted")
+	}
+
+	var input ExpenseInput
+	if err := readJSON(w, r, &input); err != nil {
+		return err
+	}
+
+	exp := expenses[id]
+	if err := applyExpenseInput(&exp, input); err != nil {
+		return err
+	}
+
+	if !utils.IsExpenseAmountValid(exp) {
+		return badRequest("not valid expense")
+	}
+
+	if err := registerCategory(&exp); err != nil {
+		return err
+	}
+	exp.UpdatedBy = requestUser(r)
+
+	snapshot, err := webhook.TakeSnapshot(expenses[id].Date, exp.Date)
+	if err != nil {
+		return err
+	}
+
+	expenses[id] = exp
+	if err := expense.SaveExpenses(expenses); err != nil {
+		return err
+	}
+
+	if err := s.notify(snapshot, webhook.EVENT_EXPENSE_UPDATED, exp); err != nil {
+		return err
+	}
+
+	if err := record(r, audit.ACTION_EXPENSE_UPDATE, expenseTarget(id), strings.Join(changedFields(input), ", ")); err != nil {
+		return err
+	}
+
+	return writeJSON(w, http.StatusOK, exp)
+}
+
+/**
+* DELETE /api/expenses/{id}: deletes an expense, as `delete` does, so IDs
+* stay stable. Deleting an expense twice is not an error, and not recorded.
+ */
+func (s *Server) deleteExpense(w http.ResponseWriter, r *http.Request) error {
+	expenses, id, err := pathExpense(r)
+	if err != nil {
+		return err
+	}
+
+	if !expenses[id].IsDeleted {
+		expenses[id].IsDeleted = true
+		expenses[id].UpdatedBy = requestUser(r)
+
+		if err := expense.SaveExpenses(expenses); err != nil {
+			return err
+		}
+
+		// Deleting only lowers spending, so no budget can cross a threshold
+		if err := s.notify(webhook.Snapshot{}, webhook.EVENT_EXPENSE_DELETED, expenses[id]); err != nil {
+			return err
+		}
+
+		if err := record(r, audit.ACTION_EXPENSE_DELETE, expenseTarget(id), ""); err != nil {
+			return err
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+/**
+* Reads all expenses and the ID from the request path.
+*
+* @return The expenses and the ID, or a 404 error if there is no expense with that ID.
+ */
+func pathExpense(r *http.Request) ([]expense.Expense, int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, 0, badRequest("expense id must be a number")
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if id < 0 || id >= len(expenses) {
+		return nil, 0, notFound("cannot find expense with provided id")
+	}
+
+	return expenses, id, nil
+}
+
+func expenseTarget(id int) string {
+	return "expense " + strconv.Itoa(id)
+}
+
+/**
+* Names the fields a PATCH request sets, for the audit log.
+ */
+func changedFields(input ExpenseInput) []string {
+	fields := []string{}
+	set := map[string]bool{
+		"amount":      input.Amount != nil,
+		"description": input.Description != nil,
+		"category":    input.Category != nil,
+		"date":        input.Date != nil,
+		"tags":        input.Tags != nil,
+		"notes":       input.Notes != nil,
+		"is_income":   input.IsIncome != nil,
+	}
+
+	for _, field := range []string{"amount", "description", "category", "date", "tags", "notes", "is_income"} {
+		if set[field] {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func applyExpenseInput(exp *expense.Expense, input ExpenseInput) error {
+	if input.Amount != nil {
+		exp.Amount = *input.Amount
+	}
+
+	if input.Description != nil {
+		exp.Description = *input.Description
+	}
+
+	if input.Category != nil {
+		exp.Category = *input.Category
+	}
+
+	if input.Date != nil {
+		date, err := time.Parse(DATE_FORMAT, *input.Date)
+		if err != nil {
+			return badRequest("date must be in YYYY-MM-DD format")
+		}
+		exp.Date = date
+		exp.Month = int(date.Month())
+	}
+
+	if input.Tags != nil {
+		exp.Tags = input.Tags
+	}
+
+	if input.Notes != nil {
+		exp.Notes = *input.Notes
+	}
+
+	if input.IsIncome != nil {
+		exp.IsIncome = *input.IsIncome
+	}
+
+	return nil
+}
+
+/**
+* Maps the category of an expense onto its canonical spelling and registers
+* it if new.
+ */
+func registerCategory(exp *expense.Expense) error {
+	if category.Normalize(exp.Category) == "" {
+		exp.Category = ""
+		return nil
+	}
+
+	canonical, err := category.Register(exp.Category)
+	if err != nil {
+		return err
+	}
+	exp.Category = canonical
+
+	return nil
+}
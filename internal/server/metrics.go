@@ -0,0 +1,217 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/metrics"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* Remembers the status a handler answers with, for the request metrics.
+ */
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+const (
+	METRICS_PATH = "/metrics"
+	// The route label of requests that are not metrics, the document or the API
+	DASHBOARD_ROUTE = "dashboard"
+	// The route label of API requests no route matches
+	UNMATCHED_ROUTE = "unmatched"
+)
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+/**
+* Counts a request and its duration by method, route and status.
+ */
+func (s *Server) observe(r *http.Request, routeName string, status int, started time.Time) {
+	method := r.Method
+	if !slices.Contains(ROUTED_METHODS, method) && method != http.MethodHead {
+		method = "OTHER"
+	}
+
+	s.requests.Inc(method, routeName, strconv.Itoa(status))
+	s.durations.Observe(time.Since(started).Seconds(), routeName)
+}
+
+/**
+* Names the route of a request for the metrics: the path pattern of an API
+* route, so that /api/expenses/1 and /api/expenses/2 are counted together.
+ */
+func (s *Server) routeName(r *http.Request) string {
+	switch {
+	case r.URL.Path == OPENAPI_PATH || r.URL.Path == METRICS_PATH:
+		return r.URL.Path
+	case !strings.HasPrefix(r.URL.Path, API_PREFIX):
+		return DASHBOARD_ROUTE
+	}
+
+	_, pattern := s.mux.Handler(r)
+	if pattern == "" {
+		return UNMATCHED_ROUTE
+	}
+
+	_, path, _ := strings.Cut(pattern, " ")
+
+	return path
+}
+
+/**
+* GET /metrics: the spending and budgets of the current month, the number
+* of expenses and the metrics of the server itself, in the Prometheus text
+* format. Once API tokens are in use, scraping needs one too.
+ */
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method " + r.Method + " is not allowed"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := authorize(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	families, err := s.dataFamilies()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	families = append(families,
+		s.requests.Family("expense_tracker_http_requests_total", "Number of HTTP requests served, by method, route and status."),
+		s.durations.Family("expense_tracker_http_request_duration_seconds", "Time taken to answer HTTP requests, by route."),
+	)
+	families = append(families, metrics.ProcessFamilies(s.started)...)
+
+	w.Header().Set("Content-Type", metrics.CONTENT_TYPE)
+	w.Header().Set("Cache-Control", "no-cache")
+	metrics.Write(w, families)
+}
+
+/**
+* Builds the gauges of the stored data. Spending is counted per category
+* without its subcategories, so the gauges of a month add up to its total;
+* uncategorised spending has an empty category label.
+ */
+func (s *Server) dataFamilies() ([]metrics.Family, error) {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := category.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	year, month := now.Year(), int(now.Month())
+
+	active, deleted := 0, 0
+	for _, exp := range expenses {
+		if exp.IsDeleted {
+			deleted++
+		} else {
+			active++
+		}
+	}
+
+	spent := map[string]float64{}
+	counts := map[string]float64{}
+	for _, exp := range expenses {
+		if !report.IsInPeriod(exp, year, month) {
+			continue
+		}
+
+		name := category.Resolve(registry, exp.Category)
+		spent[name] += exp.Amount
+		counts[name]++
+	}
+
+	limits := metrics.Family{
+		Name: "expense_tracker_budget_limit",
+		Help: "Budget limit of the current month, by category.",
+		Type: metrics.TYPE_GAUGE,
+	}
+	remaining := metrics.Family{
+		Name: "expense_tracker_budget_remaining",
+		Help: "Budget left for the current month, by category; negative once overspent.",
+		Type: metrics.TYPE_GAUGE,
+	}
+	for _, line := range report.BudgetVsActual(expenses, budgets, year, month, "", now) {
+		labels := []metrics.Label{{Name: "category", Value: line.Category}}
+		limits.Samples = append(limits.Samples, metrics.Sample{Labels: labels, Value: line.Limit})
+		remaining.Samples = append(remaining.Samples, metrics.Sample{Labels: labels, Value: line.Remaining})
+	}
+
+	totals := report.Totals(expenses, year, month, "")
+
+	return []metrics.Family{
+		{
+			Name: "expense_tracker_expenses",
+			Help: "Number of stored expenses, by whether they are deleted.",
+			Type: metrics.TYPE_GAUGE,
+			Samples: []metrics.Sample{
+				{Labels: []metrics.Label{{Name: "state", Value: "active"}}, Value: float64(active)},
+				{Labels: []metrics.Label{{Name: "state", Value: "deleted"}}, Value: float64(deleted)},
+			},
+		},
+		categoryFamily("expense_tracker_month_spent", "Spending of the current month, by category.", spent),
+		categoryFamily("expense_tracker_month_expenses", "Number of expenses of the current month, by category.", counts),
+		{
+			Name:    "expense_tracker_month_income",
+			Help:    "Income of the current month.",
+			Type:    metrics.TYPE_GAUGE,
+			Samples: []metrics.Sample{{Value: totals.Income}},
+		},
+		limits,
+		remaining,
+	}, nil
+}
+
+func categoryFamily(name, help string, values map[string]float64) metrics.Family {
+	family := metrics.Family{Name: name, Help: help, Type: metrics.TYPE_GAUGE}
+
+	categories := make([]string, 0, len(values))
+	for categoryName := range values {
+		categories = append(categories, categoryName)
+	}
+	slices.Sort(categories)
+
+	for _, categoryName := range categories {
+		family.Samples = append(family.Samples, metrics.Sample{
+			Labels: []metrics.Label{{Name: "category", Value: categoryName}},
+			Value:  values[categoryName],
+		})
+	}
+
+	return family
+}
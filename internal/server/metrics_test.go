@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/auth"
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	budgets := []budget.Budget{
+		{Month: 9, Year: 2025, Category: "Food", Limit: 100},
+		{Month: 9, Year: 2025, Category: "Transport", Limit: 10},
+		{Month: 8, Year: 2025, Category: "Food", Limit: 500},
+	}
+	data, _ := json.Marshal(budgets)
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	s := newTestServer()
+	do(t, s, http.MethodGet, "/api/expenses/1", nil)
+	do(t, s, http.MethodGet, "/api/expenses/2", nil)
+	do(t, s, http.MethodGet, "/api/expenses/99", nil)
+	do(t, s, http.MethodGet, "/api/nothing", nil)
+
+	rec := do(t, s, http.MethodGet, "/metrics", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %v, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != metrics.CONTENT_TYPE {
+		t.Errorf("Content-Type = %q, want %q", got, metrics.CONTENT_TYPE)
+	}
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		`expense_tracker_expenses{state="active"} 4`,
+		`expense_tracker_expenses{state="deleted"} 1`,
+		`expense_tracker_month_spent{category="Food"} 40`,
+		`expense_tracker_month_spent{category="Transport"} 15`,
+		`expense_tracker_month_expenses{category="Food"} 1`,
+		`expense_tracker_month_income 2000`,
+		`expense_tracker_budget_limit{category="Food"} 100`,
+		`expense_tracker_budget_remaining{category="Food"} 60`,
+		`expense_tracker_budget_remaining{category="Transport"} -5`,
+		`expense_tracker_http_requests_total{method="GET",route="/api/expenses/{id}",status="200"} 2`,
+		`expense_tracker_http_requests_total{method="GET",route="/api/expenses/{id}",status="404"} 1`,
+		`expense_tracker_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`expense_tracker_http_request_duration_seconds_count{route="/api/expenses/{id}"} 3`,
+		"# TYPE expense_tracker_http_request_duration_seconds histogram",
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("metrics lack %q", line)
+		}
+	}
+
+	// Only the budgets of the current month are reported
+	if got := strings.Count(body, "\nexpense_tracker_budget_limit{"); got != 2 {
+		t.Errorf("metrics report %d budget limits, want 2:\n%s", got, body)
+	}
+
+	if rec := do(t, s, http.MethodPost, "/metrics", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /metrics = %v, want 405", rec.Code)
+	}
+}
+
+func TestMetricsNeedTokenOnceTokensExist(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	reader := createToken(t, "prometheus", auth.SCOPE_READ)
+	s := newTestServer()
+
+	if rec := do(t, s, http.MethodGet, "/metrics", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /metrics without a token = %v, want 401", rec.Code)
+	}
+
+	if rec := doWithToken(t, s, http.MethodGet, "/metrics", reader, nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /metrics with a read-only token = %v, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+/**
+* The OpenAPI 3 document of the API. It is written by hand; the contract
+* tests check it against the routes and the answers of the server.
+ */
+//go:embed openapi.json
+var openAPIDocument []byte
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method " + r.Method + " is not allowed"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(openAPIDocument)
+}
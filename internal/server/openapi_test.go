@@ -0,0 +1,394 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Operation keys of an OpenAPI path item
+var specMethods = map[string]string{
+	"get":    http.MethodGet,
+	"post":   http.MethodPost,
+	"put":    http.MethodPut,
+	"patch":  http.MethodPatch,
+	"delete": http.MethodDelete,
+}
+
+type spec struct {
+	document map[string]any
+}
+
+func loadSpec(t *testing.T) spec {
+	t.Helper()
+
+	var document map[string]any
+	if err := json.Unmarshal(openAPIDocument, &document); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	return spec{document: document}
+}
+
+/**
+* Follows a local "$ref" such as "#/components/schemas/Expense".
+ */
+func (s spec) resolve(node map[string]any) (map[string]any, error) {
+	for {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node, nil
+		}
+
+		var current any = s.document
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve %s", ref)
+			}
+			current = object[part]
+		}
+
+		resolved, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot resolve %s", ref)
+		}
+		node = resolved
+	}
+}
+
+func (s spec) operation(method, path string) (map[string]any, map[string]any, bool) {
+	paths, _ := s.document["paths"].(map[string]any)
+	item, ok := paths[path].(map[string]any)
+	if !ok {
+		return nil, nil, false
+	}
+
+	operation, ok := item[strings.ToLower(method)].(map[string]any)
+
+	return item, operation, ok
+}
+
+/**
+* Lists the names of the query parameters an operation documents, including
+* the ones declared on its path.
+ */
+func (s spec) queryParameters(item, operation map[string]any) ([]string, error) {
+	names := []string{}
+
+	for _, node := range []map[string]any{item, operation} {
+		parameters, _ := node["parameters"].([]any)
+		for _, p := range parameters {
+			parameter, err := s.resolve(p.(map[string]any))
+			if err != nil {
+				return nil, err
+			}
+			if parameter["in"] == "query" {
+				names = append(names, parameter["name"].(string))
+			}
+		}
+	}
+
+	return names, nil
+}
+
+/**
+* Validates a decoded JSON value against a schema. Objects that set
+* additionalProperties to false must not have undocumented fields.
+ */
+func (s spec) validate(value any, node map[string]any, at string) []string {
+	schema, err := s.resolve(node)
+	if err != nil {
+		return []string{at + ": " + err.Error()}
+	}
+
+	problems := []string{}
+	fail := func(format string, args ...any) []string {
+		return append(problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fail("want an object, got %T", value)
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = fail("required field %q missing", name)
+			}
+		}
+
+		for name, fieldValue := range object {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = fail("field %q is not documented", name)
+				}
+				continue
+			}
+			problems = append(problems, s.validate(fieldValue, property, at+"."+name)...)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fail("want an array, got %T", value)
+		}
+		for i, item := range items {
+			problems = append(problems, s.validate(item, schema["items"].(map[string]any), at+"["+strconv.Itoa(i)+"]")...)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fail("want a string, got %T", value)
+		}
+		switch schema["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				return fail("%q is not a date-time", text)
+			}
+		case "date":
+			if _, err := time.Parse(DATE_FORMAT, text); err != nil {
+				return fail("%q is not a date", text)
+			}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fail("want an integer, got %v", value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fail("want a number, got %T", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("want a boolean, got %T", value)
+		}
+	default:
+		return fail("schema has no supported type")
+	}
+
+	return problems
+}
+
+/**
+* Checks that the status of a response is documented for an operation and
+* that its body matches the documented schema.
+ */
+func (s spec) checkResponse(operation map[string]any, rec *httptest.ResponseRecorder) []string {
+	responses := operation["responses"].(map[string]any)
+	responseNode, ok := responses[strconv.Itoa(rec.Code)].(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("status %v is not documented: %s", rec.Code, rec.Body.String())}
+	}
+
+	response, err := s.resolve(responseNode)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	content, ok := response["content"].(map[string]any)
+	if !ok {
+		if rec.Body.Len() > 0 {
+			return []string{fmt.Sprintf("status %v has a body, but none is documented", rec.Code)}
+		}
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		return []string{"response is not JSON: " + err.Error()}
+	}
+
+	schema := content["application/json"].(map[string]any)["schema"].(map[string]any)
+
+	return s.validate(body, schema, "response")
+}
+
+/**
+* Walks the document and collects every $ref that cannot be resolved.
+ */
+func (s spec) brokenRefs(node any) []string {
+	broken := []string{}
+
+	switch value := node.(type) {
+	case map[string]any:
+		if ref, ok := value["$ref"].(string); ok {
+			if _, err := s.resolve(value); err != nil {
+				broken = append(broken, ref)
+			}
+		}
+		for _, child := range value {
+			broken = append(broken, s.brokenRefs(child)...)
+		}
+	case []any:
+		for _, child := range value {
+			broken = append(broken, s.brokenRefs(child)...)
+		}
+	}
+
+	return broken
+}
+
+func TestOpenAPIDocumentIsServed(t *testing.T) {
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodGet, OPENAPI_PATH, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, want 200", rec.Code)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	if document := decode[map[string]any](t, rec); document["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v, want 3.0.3", document["openapi"])
+	}
+
+	if rec := do(t, s, http.MethodPost, OPENAPI_PATH, nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: status = %v, want 405", rec.Code)
+	}
+}
+
+func TestOpenAPIDescribesEveryRoute(t *testing.T) {
+	spec := loadSpec(t)
+
+	documented := []string{}
+	operationIDs := map[string]bool{}
+	for path, item := range spec.document["paths"].(map[string]any) {
+		for key, operation := range item.(map[string]any) {
+			method, ok := specMethods[key]
+			if !ok {
+				continue
+			}
+			documented = append(documented, method+" "+path)
+
+			id, _ := operation.(map[string]any)["operationId"].(string)
+			if id == "" || operationIDs[id] {
+				t.Errorf("%s %s: operationId %q is missing or not unique", method, path, id)
+			}
+			operationIDs[id] = true
+		}
+	}
+
+	served := []string{}
+	for _, route := range New().routes() {
+		served = append(served, route.Method+" "+route.Path)
+	}
+
+	sort.Strings(documented)
+	sort.Strings(served)
+	if !slices.Equal(documented, served) {
+		t.Errorf("documented operations differ from the routes:\ndocumented %v\nserved     %v", documented, served)
+	}
+
+	if broken := spec.brokenRefs(spec.document); len(broken) > 0 {
+		t.Errorf("unresolvable $refs: %v", broken)
+	}
+}
+
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	spec := loadSpec(t)
+	s := newTestServer()
+
+	// Path is the documented path of the request target
+	requests := []struct {
+		method string
+		path   string
+		target string
+		body   any
+	}{
+		{"GET", "/api/expenses", "/api/expenses?with_deleted=true&limit=10", nil},
+		{"GET", "/api/expenses", "/api/expenses?month=9&year=2025&category=Food&from=2025-09-01&to=2025-09-30&q=gro&offset=1", nil},
+		{"GET", "/api/expenses", "/api/expenses?month=13", nil},
+		{"POST", "/api/expenses", "/api/expenses", map[string]any{"amount": 5.5, "description": "Coffee", "category": "Food:Coffee", "date": "2025-09-20", "tags": []string{"work"}, "notes": "oat milk", "is_income": false}},
+		{"POST", "/api/expenses", "/api/expenses", map[string]any{"amount": 40, "description": "Groceries", "date": "2025-09-02"}},
+		{"POST", "/api/expenses", "/api/expenses", map[string]any{"amount": -1}},
+		{"GET", "/api/expenses/{id}", "/api/expenses/4", nil},
+		{"GET", "/api/expenses/{id}", "/api/expenses/x", nil},
+		{"GET", "/api/expenses/{id}", "/api/expenses/99", nil},
+		{"PATCH", "/api/expenses/{id}", "/api/expenses/0", map[string]any{"notes": "weekly shop"}},
+		{"PATCH", "/api/expenses/{id}", "/api/expenses/3", map[string]any{"amount": 1}},
+		{"DELETE", "/api/expenses/{id}", "/api/expenses/1", nil},
+		{"PUT", "/api/budgets", "/api/budgets", map[string]any{"month": 9, "category": "Food", "limit": 100}},
+		{"PUT", "/api/budgets", "/api/budgets", map[string]any{"month": 0, "limit": 100}},
+		{"GET", "/api/budgets", "/api/budgets", nil},
+		{"GET", "/api/summary", "/api/summary?month=9&year=2025&category=Food", nil},
+		{"GET", "/api/summary", "/api/summary", nil},
+		{"GET", "/api/reports/monthly", "/api/reports/monthly?year=2025&category=Food", nil},
+		{"GET", "/api/reports/budget", "/api/reports/budget?month=9&year=2025&category=Food", nil},
+		{"GET", "/api/reports/forecast", "/api/reports/forecast?category=Food", nil},
+		{"DELETE", "/api/budgets", "/api/budgets?month=9&category=Food", nil},
+		{"DELETE", "/api/budgets", "/api/budgets?month=9&category=Food", nil},
+		{"GET", "/api/categories", "/api/categories", nil},
+		{"POST", "/api/categories", "/api/categories", map[string]any{"name": "Home:Rent"}},
+		{"POST", "/api/categories", "/api/categories", map[string]any{"name": "Home:Rent"}},
+		{"DELETE", "/api/categories/{name}", "/api/categories/Home", nil},
+		{"DELETE", "/api/categories/{name}", "/api/categories/Home:Rent", nil},
+		{"DELETE", "/api/categories/{name}", "/api/categories/Travel", nil},
+	}
+
+	for _, req := range requests {
+		name := req.method + " " + req.target
+		item, operation, ok := spec.operation(req.method, req.path)
+		if !ok {
+			t.Errorf("%s: %s %s is not documented", name, req.method, req.path)
+			continue
+		}
+
+		target, err := url.Parse(req.target)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		documented, err := spec.queryParameters(item, operation)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for parameter := range target.Query() {
+			if !slices.Contains(documented, parameter) {
+				t.Errorf("%s: query parameter %q is not documented", name, parameter)
+			}
+		}
+
+		if req.body != nil {
+			requestBody, err := spec.resolve(operation["requestBody"].(map[string]any))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			schema := requestBody["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+
+			var body any
+			encoded, _ := json.Marshal(req.body)
+			json.Unmarshal(encoded, &body)
+			// Invalid bodies are sent on purpose; only the field names must be documented
+			for _, problem := range spec.validate(body, schema, "request") {
+				if strings.Contains(problem, "not documented") {
+					t.Errorf("%s: %s", name, problem)
+				}
+			}
+		}
+
+		rec := do(t, s, req.method, req.target, req.body)
+		for _, problem := range spec.checkResponse(operation, rec) {
+			t.Errorf("%s: %s", name, problem)
+		}
+	}
+}
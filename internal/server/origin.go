@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Values of Sec-Fetch-Site sent by the server's own pages, or by no page at all
+var SAME_ORIGIN_FETCH_SITES = []string{"", "same-origin", "none"}
+
+/**
+* Sets the address the server listens on, host:port. Requests are only
+* answered for that host and for loopback names, so a web page cannot reach
+* the server through a host name of its own, e.g. by DNS rebinding.
+ */
+func (s *Server) SetAddr(addr string) {
+	s.addr = addr
+}
+
+/**
+* Rejects requests for a Host the server does not listen on, and requests
+* from other sites that would change data. Browsers send any page's forms
+* and scripts to localhost, so without these checks every page the user
+* visits could write to the ledger while the API is open.
+ */
+func (s *Server) checkRequest(r *http.Request) error {
+	if !s.isAllowedHost(r.Host) {
+		return &Error{Status: http.StatusMisdirectedRequest, Message: "host " + r.Host + " is not served; use the address the server listens on or localhost"}
+	}
+
+	if isSafeMethod(r.Method) {
+		return nil
+	}
+
+	if isCrossSite(r) {
+		return &Error{Status: http.StatusForbidden, Message: "requests from other sites cannot change data"}
+	}
+
+	return nil
+}
+
+func (s *Server) isAllowedHost(hostport string) bool {
+	host := hostport
+	if name, _, err := net.SplitHostPort(hostport); err == nil {
+		host = name
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+
+	if isLoopbackHost(host) {
+		return true
+	}
+
+	listenHost, _, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(host, listenHost) {
+		return true
+	}
+
+	// Listening on every interface: any address of the machine is served, host names are not
+	ip := net.ParseIP(listenHost)
+	return (listenHost == "" || (ip != nil && ip.IsUnspecified())) && net.ParseIP(host) != nil
+}
+
+func isLoopbackHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+/**
+* Whether the browser says a request comes from a page of another origin.
+* Requests without Sec-Fetch-Site and Origin, e.g. from curl or the Go
+* client, are not cross-site.
+ */
+func isCrossSite(r *http.Request) bool {
+	if !slices.Contains(SAME_ORIGIN_FETCH_SITES, r.Header.Get("Sec-Fetch-Site")) {
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		// Including "null", sent by sandboxed pages and local files
+		return true
+	}
+
+	return !strings.EqualFold(parsed.Host, r.Host)
+}
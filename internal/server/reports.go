@@ -0,0 +1,169 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* The answer of GET /api/summary, the same figures `summary` prints.
+* Year and Month are the period of the budget report; the totals cover
+* that period only if a month or year was asked for, and all time otherwise.
+ */
+type Summary struct {
+	Year          int                    `json:"year"`
+	Month         int                    `json:"month"`
+	TotalExpenses float64                `json:"total_expenses"`
+	TotalIncome   float64                `json:"total_income"`
+	Categories    []report.CategoryTotal `json:"categories"`
+	Budgets       []report.BudgetLine    `json:"budgets"`
+	Forecast      []report.ForecastLine  `json:"forecast,omitempty"`
+}
+
+/**
+* GET /api/summary: totals, category totals and the budget report. Accepts
+* month, year and category. The forecast is included for the current month.
+ */
+func (s *Server) summary(w http.ResponseWriter, r *http.Request) error {
+	month, year, err := queryPeriod(r)
+	if err != nil {
+		return err
+	}
+	categoryFilter := r.URL.Query().Get("category")
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	registry, err := category.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	periodYear, periodMonth := now.Year(), int(now.Month())
+	if year != -1 {
+		periodYear = year
+	}
+	if month != -1 {
+		periodMonth = month
+	}
+
+	// The totals cover all time unless a period is given
+	totalsYear := -1
+	if month != -1 || year != -1 {
+		totalsYear = periodYear
+	}
+	totals := report.Totals(expenses, totalsYear, month, categoryFilter)
+
+	result := Summary{
+		Year:          periodYear,
+		Month:         periodMonth,
+		TotalExpenses: totals.Expenses,
+		TotalIncome:   totals.Income,
+		Categories:    report.CategoryTotals(totals.Spending, registry),
+		Budgets:       report.BudgetVsActual(expenses, budgets, periodYear, periodMonth, categoryFilter, now),
+	}
+
+	if periodYear == now.Year() && periodMonth == int(now.Month()) {
+		result.Forecast = report.Forecast(expenses, budgets, periodYear, periodMonth, categoryFilter, now)
+	}
+
+	return writeJSON(w, http.StatusOK, result)
+}
+
+/**
+* GET /api/reports/monthly: spending and income per month, oldest first.
+* Accepts year and category.
+ */
+func (s *Server) monthlyReport(w http.ResponseWriter, r *http.Request) error {
+	_, year, err := queryPeriod(r)
+	if err != nil {
+		return err
+	}
+	categoryFilter := r.URL.Query().Get("category")
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	selected := []expense.Expense{}
+	for _, exp := range expenses {
+		if year != -1 && exp.Date.Year() != year {
+			continue
+		}
+
+		if categoryFilter != "" && !category.IsWithin(exp.Category, categoryFilter) {
+			continue
+		}
+
+		selected = append(selected, exp)
+	}
+
+	return writeJSON(w, http.StatusOK, report.MonthlyTotals(selected))
+}
+
+/**
+* GET /api/reports/budget: the budget-vs-actual report of a month, the
+* current one by default. Accepts month, year and category.
+ */
+func (s *Server) budgetReport(w http.ResponseWriter, r *http.Request) error {
+	month, year, err := queryPeriod(r)
+	if err != nil {
+		return err
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if year == -1 {
+		year = now.Year()
+	}
+	if month == -1 {
+		month = int(now.Month())
+	}
+
+	lines := report.BudgetVsActual(expenses, budgets, year, month, r.URL.Query().Get("category"), now)
+
+	return writeJSON(w, http.StatusOK, lines)
+}
+
+/**
+* GET /api/reports/forecast: the end-of-month forecast of the current month.
+* Accepts category.
+ */
+func (s *Server) forecastReport(w http.ResponseWriter, r *http.Request) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	lines := report.Forecast(expenses, budgets, now.Year(), int(now.Month()), r.URL.Query().Get("category"), now)
+
+	return writeJSON(w, http.StatusOK, lines)
+}
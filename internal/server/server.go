@@ -0,0 +1,341 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/metrics"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* A JSON REST API over the same data files the CLI uses. Handlers run one at
+* a time, as every change rewrites a whole file.
+ */
+type Server struct {
+	mux       *http.ServeMux
+	dashboard http.Handler
+	// The address listened on, host:port; see SetAddr
+	addr      string
+	mu        sync.Mutex
+	now       func() time.Time
+	started   time.Time
+	requests  *metrics.CounterVec
+	durations *metrics.HistogramVec
+	// Wakes up DeliverWebhooks once an event has been queued
+	webhooks chan struct{}
+	// Wakes up SendEmails once budgets have crossed a threshold
+	emails   chan struct{}
+	alertsMu sync.Mutex
+	// The crossings SendEmails has yet to send an alert for
+	alerts []report.Crossing
+}
+
+/**
+* An error with the HTTP status it is answered with. Any other error a
+* handler returns is answered with 500.
+ */
+type Error struct {
+	Status  int
+	Message string
+}
+
+const (
+	DEFAULT_ADDR       = "127.0.0.1:8080"
+	DEFAULT_PAGE_LIMIT = 50
+	MAX_PAGE_LIMIT     = 500
+	MAX_BODY_BYTES     = 1 << 20
+	DATE_FORMAT        = "2006-01-02"
+	API_PREFIX         = "/api/"
+	OPENAPI_PATH       = "/openapi.json"
+	JSON_CONTENT_TYPE  = "application/json"
+)
+
+var ROUTED_METHODS = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+type route struct {
+	Method  string
+	Path    string
+	Handler handlerFunc
+}
+
+func New() *Server {
+	s := &Server{
+		mux:       http.NewServeMux(),
+		dashboard: newDashboard(),
+		addr:      DEFAULT_ADDR,
+		now:       func() time.Time { return time.Now().UTC() },
+		started:   time.Now(),
+		requests:  metrics.NewCounterVec("method", "route", "status"),
+		durations: metrics.NewHistogramVec(metrics.DEFAULT_BUCKETS, "route"),
+		webhooks:  make(chan struct{}, 1),
+		emails:    make(chan struct{}, 1),
+	}
+
+	for _, route := range s.routes() {
+		s.handle(route.Method+" "+route.Path, route.Handler)
+	}
+
+	return s
+}
+
+/**
+* Every API endpoint. The OpenAPI document describes exactly these.
+ */
+func (s *Server) routes() []route {
+	return []route{
+		{http.MethodGet, "/api/expenses", s.listExpenses},
+		{http.MethodPost, "/api/expenses", s.createExpense},
+		{http.MethodGet, "/api/expenses/{id}", s.getExpense},
+		{http.MethodPatch, "/api/expenses/{id}", s.updateExpense},
+		{http.MethodDelete, "/api/expenses/{id}", s.deleteExpense},
+
+		{http.MethodGet, "/api/budgets", s.listBudgets},
+		{http.MethodPut, "/api/budgets", s.setBudget},
+		{http.MethodDelete, "/api/budgets", s.removeBudget},
+
+		{http.MethodGet, "/api/categories", s.listCategories},
+		{http.MethodPost, "/api/categories", s.addCategory},
+		{http.MethodDelete, "/api/categories/{name}", s.removeCategory},
+
+		{http.MethodGet, "/api/summary", s.summary},
+		{http.MethodGet, "/api/reports/monthly", s.monthlyReport},
+		{http.MethodGet, "/api/reports/budget", s.budgetReport},
+		{http.MethodGet, "/api/reports/forecast", s.forecastReport},
+	}
+}
+
+/**
+* Serves the API under /api/, its OpenAPI document at /openapi.json, the
+* metrics at /metrics and the dashboard everywhere else.
+ */
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	started := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	routeName := s.routeName(r)
+
+	s.serve(recorder, r)
+	s.observe(r, routeName, recorder.status, started)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if err := s.checkRequest(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if r.URL.Path == OPENAPI_PATH {
+		serveOpenAPI(w, r)
+		return
+	}
+
+	if r.URL.Path == METRICS_PATH {
+		s.serveMetrics(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, API_PREFIX) {
+		s.dashboard.ServeHTTP(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := authorize(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, pattern := s.mux.Handler(r); pattern == "" {
+		s.unrouted(w, r)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+/**
+* Answers a request no route matches with a JSON error: 405 and the allowed
+* methods if the path is known, 404 otherwise.
+ */
+func (s *Server) unrouted(w http.ResponseWriter, r *http.Request) {
+	allowed := []string{}
+	for _, method := range ROUTED_METHODS {
+		other := r.Clone(r.Context())
+		other.Method = method
+		if _, pattern := s.mux.Handler(other); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) < 1 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such endpoint"})
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method " + r.Method + " is not allowed"})
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (s *Server) handle(pattern string, handler handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if err := handler(w, r); err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+/**
+* Answers with an error as {"error": "..."}: with its status if it is an
+* *Error and with 500 otherwise.
+ */
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = &Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+
+	if apiErr.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	writeJSON(w, apiErr.Status, map[string]string{"error": apiErr.Message})
+}
+
+func badRequest(message string) error {
+	return &Error{Status: http.StatusBadRequest, Message: message}
+}
+
+func notFound(message string) error {
+	return &Error{Status: http.StatusNotFound, Message: message}
+}
+
+func conflict(message string) error {
+	return &Error{Status: http.StatusConflict, Message: message}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(value)
+}
+
+/**
+* Decodes a JSON request body. Unknown fields are rejected, so that a
+* misspelled field is not silently ignored. Bodies of other content types
+* are answered with 415, as browsers send those from any site without asking.
+ */
+func readJSON(w http.ResponseWriter, r *http.Request, value any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != JSON_CONTENT_TYPE {
+		return &Error{Status: http.StatusUnsupportedMediaType, Message: "request body must be " + JSON_CONTENT_TYPE}
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_BODY_BYTES))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		if err == io.EOF {
+			return badRequest("request body is empty")
+		}
+		return badRequest("invalid request body: " + err.Error())
+	}
+
+	if decoder.More() {
+		return badRequest("invalid request body: unexpected data after the JSON value")
+	}
+
+	return nil
+}
+
+/**
+* Reads an optional integer query parameter.
+*
+* @param fallback The value used if the parameter is missing.
+ */
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badRequest("query parameter '" + name + "' must be a number")
+	}
+
+	return n, nil
+}
+
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, badRequest("query parameter '" + name + "' must be true or false")
+	}
+
+	return b, nil
+}
+
+func queryDate(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(DATE_FORMAT, value)
+	if err != nil {
+		return time.Time{}, badRequest("query parameter '" + name + "' must be in YYYY-MM-DD format")
+	}
+
+	return date, nil
+}
+
+/**
+* Reads the optional month and year query parameters.
+*
+* @return The month and year, -1 for each one that is missing.
+ */
+func queryPeriod(r *http.Request) (int, int, error) {
+	month, err := queryInt(r, "month", -1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if month != -1 && (month < 1 || month > 12) {
+		return 0, 0, badRequest("query parameter 'month' must be between 1 and 12")
+	}
+
+	year, err := queryInt(r, "year", -1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return month, year, nil
+}
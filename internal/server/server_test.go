@@ -0,0 +1,592 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+func newTestServer() *Server {
+	s := New()
+	s.now = func() time.Time { return time.Date(2025, 9, 21, 12, 0, 0, 0, time.UTC) }
+
+	return s
+}
+
+func do(t *testing.T, s *Server, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if text, ok := body.(string); ok {
+			payload.WriteString(text)
+		} else if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("Failed to encode request body: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, newTestRequest(method, target, &payload))
+
+	return rec
+}
+
+/**
+* A request as the dashboard or the CLI client sends it: to the default
+* address, with a JSON body.
+ */
+func newTestRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Host = DEFAULT_ADDR
+	req.Header.Set("Content-Type", JSON_CONTENT_TYPE)
+
+	return req
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var value T
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+	}
+
+	return value
+}
+
+func seedExpenses(t *testing.T) {
+	t.Helper()
+
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 40, Description: "Groceries", Category: "Food", Date: time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 1, Amount: 15, Description: "Bus ticket", Category: "Transport", Date: time.Date(2025, 9, 5, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 2, Amount: 60, Description: "Groceries", Category: "Food:Groceries", Date: time.Date(2025, 8, 20, 10, 0, 0, 0, time.UTC), Month: 8},
+		{ID: 3, Amount: 99, Description: "Old lunch", Category: "Food", Date: time.Date(2025, 9, 6, 10, 0, 0, 0, time.UTC), Month: 9, IsDeleted: true},
+		{ID: 4, Amount: 2000, Description: "Salary", Date: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), Month: 9, IsIncome: true},
+	}
+	if err := expense.SaveExpenses(expenses); err != nil {
+		t.Fatalf("Failed to save expenses: %v", err)
+	}
+
+	if err := category.SaveCategories([]category.Category{{Name: "Food"}, {Name: "Food:Groceries"}, {Name: "Transport"}}); err != nil {
+		t.Fatalf("Failed to save categories: %v", err)
+	}
+}
+
+func TestListExpenses(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []int
+		total   int
+	}{
+		{"All", "", []int{0, 1, 2, 4}, 4},
+		{"With deleted", "?with_deleted=true", []int{0, 1, 2, 3, 4}, 5},
+		{"Month", "?month=8&year=2025", []int{2}, 1},
+		{"Category with subcategories", "?category=food", []int{0, 2}, 2},
+		{"Date range", "?from=2025-09-02&to=2025-09-05", []int{0, 1}, 2},
+		{"Search", "?q=GROCER", []int{0, 2}, 2},
+		{"Page", "?limit=2&offset=1", []int{1, 2}, 4},
+		{"Offset past the end", "?offset=10", []int{}, 4},
+		{"Enormous offset", "?limit=500&offset=9223372036854775807", []int{}, 4},
+		{"Page reaching the end", "?limit=500&offset=3", []int{4}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, s, http.MethodGet, "/api/expenses"+tt.query, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %v, want 200: %s", rec.Code, rec.Body.String())
+			}
+
+			page := decode[ExpensePage](t, rec)
+			if page.Total != tt.total {
+				t.Errorf("total = %v, want %v", page.Total, tt.total)
+			}
+
+			ids := []int{}
+			for _, exp := range page.Items {
+				ids = append(ids, exp.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestListExpensesRejectsBadQuery(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	s := newTestServer()
+
+	for _, query := range []string{"?month=13", "?year=abc", "?limit=0", "?limit=501", "?limit=-5", "?offset=-1", "?offset=-9223372036854775808", "?from=2025/09/01", "?with_deleted=maybe"} {
+		rec := do(t, s, http.MethodGet, "/api/expenses"+query, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %v, want 400", query, rec.Code)
+		}
+
+		if body := decode[map[string]string](t, rec); body["error"] == "" {
+			t.Errorf("%s: error message missing", query)
+		}
+	}
+}
+
+func TestCreateExpense(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{
+		"amount":      12.5,
+		"description": "Coffee",
+		"category":    " food : coffee ",
+		"date":        "2025-09-20",
+		"tags":        []string{"work"},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+
+	exp := decode[expense.Expense](t, rec)
+	if exp.ID != 5 || exp.Amount != 12.5 || exp.Category != "Food:coffee" || exp.Month != 9 {
+		t.Errorf("created = %+v", exp)
+	}
+	if rec.Header().Get("Location") != "/api/expenses/5" {
+		t.Errorf("Location = %q", rec.Header().Get("Location"))
+	}
+
+	stored, err := expense.GetExpenses()
+	if err != nil || len(stored) != 6 || stored[5].Description != "Coffee" {
+		t.Fatalf("stored = %v, %v", stored, err)
+	}
+
+	registered, err := isCategoryRegistered("Food:coffee")
+	if err != nil || !registered {
+		t.Errorf("category not registered: %v", err)
+	}
+
+	// The date defaults to today
+	rec = do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 3, "description": "Tea"})
+	if exp := decode[expense.Expense](t, rec); !exp.Date.Equal(s.now()) {
+		t.Errorf("date = %v, want %v", exp.Date, s.now())
+	}
+}
+
+func TestCreateExpenseFlagsDuplicates(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{
+		"amount":      40,
+		"description": "GROCERIES",
+		"date":        "2025-09-03",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+
+	if got := rec.Header().Get(DUPLICATES_HEADER); got != "0" {
+		t.Errorf("%s = %q, want \"0\"", DUPLICATES_HEADER, got)
+	}
+}
+
+func TestCreateExpenseRejectsInvalidInput(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	s := newTestServer()
+
+	bodies := []any{
+		"",
+		"{",
+		map[string]any{"description": "No amount"},
+		map[string]any{"amount": -5, "description": "Negative"},
+		map[string]any{"amount": 5, "date": "20.09.2025"},
+		map[string]any{"amount": 5, "amout": 6},
+	}
+
+	for _, body := range bodies {
+		rec := do(t, s, http.MethodPost, "/api/expenses", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: status = %v, want 400", body, rec.Code)
+		}
+	}
+
+	stored, err := expense.GetExpenses()
+	if err != nil || len(stored) != 0 {
+		t.Errorf("stored = %v, %v, want none", stored, err)
+	}
+}
+
+func TestGetUpdateAndDeleteExpense(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodGet, "/api/expenses/1", nil)
+	if rec.Code != http.StatusOK || decode[expense.Expense](t, rec).Description != "Bus ticket" {
+		t.Fatalf("get: %v %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(t, s, http.MethodPatch, "/api/expenses/1", map[string]any{"amount": 17, "date": "2025-08-31"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status = %v: %s", rec.Code, rec.Body.String())
+	}
+	updated := decode[expense.Expense](t, rec)
+	if updated.Amount != 17 || updated.Description != "Bus ticket" || updated.Month != 8 {
+		t.Errorf("patched = %+v", updated)
+	}
+
+	if rec := do(t, s, http.MethodPatch, "/api/expenses/3", map[string]any{"amount": 1}); rec.Code != http.StatusConflict {
+		t.Errorf("patch deleted: status = %v, want 409", rec.Code)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/expenses/1", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status = %v, want 204", rec.Code)
+	}
+
+	stored, err := expense.GetExpenses()
+	if err != nil || !stored[1].IsDeleted || stored[1].Amount != 17 {
+		t.Errorf("stored = %+v, %v", stored[1], err)
+	}
+
+	for _, target := range []string{"/api/expenses/5", "/api/expenses/-1"} {
+		if rec := do(t, s, http.MethodGet, target, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %v, want 404", target, rec.Code)
+		}
+	}
+
+	if rec := do(t, s, http.MethodGet, "/api/expenses/abc", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("non-numeric id: status = %v, want 400", rec.Code)
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodPut, "/api/budgets", BudgetInput{Month: 9, Category: "food", Limit: 300})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: status = %v: %s", rec.Code, rec.Body.String())
+	}
+	if b := decode[budget.Budget](t, rec); b.Category != "Food" || b.Limit != 300 {
+		t.Errorf("budget = %+v", b)
+	}
+
+	if rec := do(t, s, http.MethodPut, "/api/budgets", BudgetInput{Month: 13, Category: "Food", Limit: 1}); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid month: status = %v, want 400", rec.Code)
+	}
+
+	rec = do(t, s, http.MethodGet, "/api/budgets", nil)
+	if budgets := decode[[]budget.Budget](t, rec); len(budgets) != 1 {
+		t.Errorf("budgets = %v, want one", budgets)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/budgets?month=9&category=Transport", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("delete unknown: status = %v, want 404", rec.Code)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/budgets?month=9&category=FOOD", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %v, want 204", rec.Code)
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil || len(budgets) != 0 {
+		t.Errorf("budgets = %v, %v, want none", budgets, err)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodPost, "/api/categories", CategoryInput{Name: "Home:Rent"})
+	if rec.Code != http.StatusCreated || decode[category.Category](t, rec).Name != "Home:Rent" {
+		t.Fatalf("post: %v %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(t, s, http.MethodPost, "/api/categories", CategoryInput{Name: "home:rent"}); rec.Code != http.StatusConflict {
+		t.Errorf("post existing: status = %v, want 409", rec.Code)
+	}
+
+	rec = do(t, s, http.MethodGet, "/api/categories", nil)
+	if categories := decode[[]category.Category](t, rec); len(categories) != 5 {
+		t.Errorf("categories = %v, want 5", categories)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/categories/Home", nil); rec.Code != http.StatusConflict {
+		t.Errorf("delete parent: status = %v, want 409", rec.Code)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/categories/home:rent", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %v, want 204", rec.Code)
+	}
+
+	if rec := do(t, s, http.MethodDelete, "/api/categories/Travel", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("delete unknown: status = %v, want 404", rec.Code)
+	}
+}
+
+func TestSummaryAndReports(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	if err := budget.SetBudget(9, "Food", 100); err != nil {
+		t.Fatalf("Failed to set budget: %v", err)
+	}
+	// Budgets are set for the current year; pin it to the test clock
+	budgets, _ := budget.GetBudgets()
+	budgets[0].Year = 2025
+	data, _ := json.Marshal(budgets)
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodGet, "/api/summary?month=9", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("summary: status = %v: %s", rec.Code, rec.Body.String())
+	}
+
+	summary := decode[Summary](t, rec)
+	if summary.TotalExpenses != 55 || summary.TotalIncome != 2000 {
+		t.Errorf("totals = %v, %v, want 55, 2000", summary.TotalExpenses, summary.TotalIncome)
+	}
+	if len(summary.Budgets) != 1 || summary.Budgets[0].Spent != 40 {
+		t.Errorf("budgets = %+v", summary.Budgets)
+	}
+	if len(summary.Categories) != 2 {
+		t.Errorf("categories = %+v", summary.Categories)
+	}
+	if summary.Forecast == nil {
+		t.Errorf("forecast missing for the current month")
+	}
+
+	// Without a period the totals cover all time
+	rec = do(t, s, http.MethodGet, "/api/summary?category=Food", nil)
+	if summary := decode[Summary](t, rec); summary.TotalExpenses != 100 || summary.Month != 9 {
+		t.Errorf("all-time summary = %+v", summary)
+	}
+
+	rec = do(t, s, http.MethodGet, "/api/reports/monthly?year=2025", nil)
+	months := decode[[]report.MonthTotal](t, rec)
+	if len(months) != 2 || months[0].Month != 8 || months[1].Spent != 55 {
+		t.Errorf("monthly = %+v", months)
+	}
+
+	rec = do(t, s, http.MethodGet, "/api/reports/budget?year=2025&month=9", nil)
+	if lines := decode[[]report.BudgetLine](t, rec); len(lines) != 1 || lines[0].DaysLeft != 10 {
+		t.Errorf("budget report = %+v", lines)
+	}
+
+	rec = do(t, s, http.MethodGet, "/api/reports/forecast", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("forecast: status = %v", rec.Code)
+	}
+}
+
+func TestUnknownEndpoint(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	s := newTestServer()
+
+	rec := do(t, s, http.MethodGet, "/api/nothing", nil)
+	if rec.Code != http.StatusNotFound || decode[map[string]string](t, rec)["error"] == "" {
+		t.Errorf("status = %v, body = %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(t, s, http.MethodPut, "/api/expenses", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong method: status = %v, want 405", rec.Code)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	s := newTestServer()
+
+	tests := []struct {
+		target      string
+		contentType string
+		contains    string
+	}{
+		{"/", "text/html", "<script src=\"app.js\"></script>"},
+		{"/app.js", "javascript", "/api/expenses"},
+		{"/style.css", "text/css", ".bar"},
+	}
+
+	for _, tt := range tests {
+		rec := do(t, s, http.MethodGet, tt.target, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %v, want 200", tt.target, rec.Code)
+			continue
+		}
+
+		if got := rec.Header().Get("Content-Type"); !strings.Contains(got, tt.contentType) {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.target, got, tt.contentType)
+		}
+
+		if !strings.Contains(rec.Body.String(), tt.contains) {
+			t.Errorf("%s: body does not contain %q", tt.target, tt.contains)
+		}
+	}
+
+	if rec := do(t, s, http.MethodGet, "/missing.js", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing file: status = %v, want 404", rec.Code)
+	}
+
+	if rec := do(t, s, http.MethodPost, "/", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /: status = %v, want 405", rec.Code)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
+
+func TestRejectsRequestsFromOtherSites(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	s := newTestServer()
+	body := `{"amount": 5, "description": "Planted"}`
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		headers     map[string]string
+		want        int
+	}{
+		{"JSON from the dashboard", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Origin": "http://127.0.0.1:8080", "Sec-Fetch-Site": "same-origin"}, http.StatusCreated},
+		{"JSON with a charset", http.MethodPost, JSON_CONTENT_TYPE + "; charset=utf-8", nil, http.StatusCreated},
+		{"Plain text body", http.MethodPost, "text/plain", nil, http.StatusUnsupportedMediaType},
+		{"Form body", http.MethodPost, "application/x-www-form-urlencoded", nil, http.StatusUnsupportedMediaType},
+		{"No content type", http.MethodPost, "", nil, http.StatusUnsupportedMediaType},
+		{"Cross-site origin", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+		{"Other port of localhost", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Origin": "http://127.0.0.1:3000"}, http.StatusForbidden},
+		{"Null origin", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Origin": "null"}, http.StatusForbidden},
+		{"Cross-site fetch", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"Same-site fetch", http.MethodPost, JSON_CONTENT_TYPE, map[string]string{"Sec-Fetch-Site": "same-site"}, http.StatusForbidden},
+		{"Cross-site delete", http.MethodDelete, "", map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+		{"Cross-site read", http.MethodGet, "", map[string]string{"Origin": "http://evil.example"}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := "/api/expenses"
+			if tt.method == http.MethodDelete {
+				target = "/api/expenses/0"
+			}
+
+			req := newTestRequest(tt.method, target, strings.NewReader(body))
+			req.Header.Set("Content-Type", tt.contentType)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %v, want %v: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	expenses, _ := expense.GetExpenses()
+	if len(expenses) != 2 {
+		t.Errorf("%d expenses were added, want only the 2 same-origin JSON ones", len(expenses))
+	}
+}
+
+func TestRejectsUnknownHosts(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	tests := []struct {
+		name string
+		addr string
+		host string
+		want int
+	}{
+		{"Listen address", DEFAULT_ADDR, "127.0.0.1:8080", http.StatusOK},
+		{"Localhost", DEFAULT_ADDR, "localhost:8080", http.StatusOK},
+		{"IPv6 loopback", DEFAULT_ADDR, "[::1]:8080", http.StatusOK},
+		{"Rebound host name", DEFAULT_ADDR, "evil.example", http.StatusMisdirectedRequest},
+		{"Rebound host name with port", DEFAULT_ADDR, "evil.example:8080", http.StatusMisdirectedRequest},
+		{"Named listen address", "budget.lan:8080", "budget.lan:8080", http.StatusOK},
+		{"Other name than the listen address", "budget.lan:8080", "evil.example:8080", http.StatusMisdirectedRequest},
+		{"Address of the machine on every interface", "0.0.0.0:8080", "192.168.1.5:8080", http.StatusOK},
+		{"Host name on every interface", "0.0.0.0:8080", "evil.example:8080", http.StatusMisdirectedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			s.SetAddr(tt.addr)
+
+			for _, target := range []string{"/api/expenses", "/"} {
+				req := newTestRequest(http.MethodGet, target, nil)
+				req.Host = tt.host
+
+				rec := httptest.NewRecorder()
+				s.ServeHTTP(rec, req)
+
+				if rec.Code != tt.want {
+					t.Errorf("GET %s for %s = %v, want %v", target, tt.host, rec.Code, tt.want)
+				}
+			}
+		})
+	}
+}
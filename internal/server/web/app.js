@@ -0,0 +1,401 @@
+"use strict";
+
+const PAGE_SIZE = 25;
+const TOKEN_KEY = "et-token";
+const MONTHS = ["Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"];
+
+const state = {
+  offset: 0,
+  total: 0,
+  editing: null,
+};
+
+const $ = (selector) => document.querySelector(selector);
+
+const money = new Intl.NumberFormat(undefined, { minimumFractionDigits: 2, maximumFractionDigits: 2 });
+
+/**
+ * Calls the API and returns the decoded JSON body. Errors carry the
+ * message the server answered with. Once the server requires API tokens,
+ * the user is asked for one, which is kept in local storage.
+ */
+async function api(method, path, body) {
+  const options = { method, headers: {} };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+
+  let response;
+  for (;;) {
+    const token = localStorage.getItem(TOKEN_KEY);
+    if (token) {
+      options.headers["Authorization"] = `Bearer ${token}`;
+    }
+
+    response = await fetch(path, options);
+    if (response.status !== 401) {
+      break;
+    }
+
+    // Another request may have asked for a token in the meantime
+    if (localStorage.getItem(TOKEN_KEY) !== token) {
+      continue;
+    }
+
+    const entered = window.prompt("This server requires an API token (create one with 'et token create'):");
+    if (!entered) {
+      localStorage.removeItem(TOKEN_KEY);
+      break;
+    }
+    localStorage.setItem(TOKEN_KEY, entered.trim());
+  }
+
+  if (response.status === 204) {
+    return null;
+  }
+
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || response.statusText);
+  }
+
+  return data;
+}
+
+function showError(error) {
+  const element = $("#error");
+  element.textContent = error ? error.message : "";
+  if (error) {
+    setTimeout(() => showError(null), 5000);
+  }
+}
+
+function element(tag, attributes = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attributes)) {
+    if (name === "className") {
+      node.className = value;
+    } else {
+      node.setAttribute(name, value);
+    }
+  }
+  node.append(...children);
+
+  return node;
+}
+
+function period() {
+  const form = $("#period");
+
+  return {
+    month: form.month.value,
+    year: form.year.value,
+  };
+}
+
+function query(values) {
+  const params = new URLSearchParams();
+  for (const [name, value] of Object.entries(values)) {
+    if (value !== "" && value !== false && value !== undefined) {
+      params.set(name, value);
+    }
+  }
+
+  const text = params.toString();
+  return text ? "?" + text : "";
+}
+
+function today() {
+  return new Date().toISOString().slice(0, 10);
+}
+
+async function loadCategories() {
+  const categories = await api("GET", "/api/categories");
+  const select = $("#filters").category;
+  const selected = select.value;
+
+  select.replaceChildren(element("option", { value: "" }, "All"));
+  $("#category-names").replaceChildren();
+
+  for (const category of categories) {
+    const depth = category.name.split(":").length - 1;
+    select.append(element("option", { value: category.name }, "  ".repeat(depth) + category.name.split(":").pop()));
+    $("#category-names").append(element("option", { value: category.name }));
+  }
+
+  select.value = selected;
+}
+
+async function loadSummary() {
+  const { month, year } = period();
+  const summary = await api("GET", "/api/summary" + query({ month, year }));
+
+  let label = "all time";
+  if (month) {
+    label = `${MONTHS[month - 1]} ${summary.year}`;
+  } else if (year) {
+    label = year;
+  }
+
+  const cards = [
+    ["Spent · " + label, money.format(summary.total_expenses)],
+    ["Income · " + label, money.format(summary.total_income)],
+    ["Balance", money.format(summary.total_income - summary.total_expenses)],
+  ];
+
+  $("#summary").replaceChildren(...cards.map(([title, value]) =>
+    element("div", { className: "card" },
+      element("p", { className: "label" }, title),
+      element("div", { className: "value" }, value))));
+
+  renderBudgets(summary);
+}
+
+function renderBudgets(summary) {
+  $("#budget-period").textContent = `${MONTHS[summary.month - 1]} ${summary.year}`;
+
+  if (summary.budgets.length === 0) {
+    $("#budgets").replaceChildren(element("p", { className: "empty" }, "No budgets set for this month."));
+    return;
+  }
+
+  $("#budgets").replaceChildren(...summary.budgets.map((line) => {
+    const used = Math.min(line.percent_used, 100);
+    let level = "";
+    if (line.percent_used >= 100) {
+      level = " over";
+    } else if (line.percent_used >= 80) {
+      level = " warning";
+    }
+
+    const bar = element("div", { className: "bar" + level }, element("span"));
+    bar.firstChild.style.width = used + "%";
+
+    return element("div", { className: "budget" },
+      element("div", { className: "budget-line" },
+        element("span", {}, line.category || "(all)"),
+        element("span", {}, `${money.format(line.spent)} of ${money.format(line.limit)}`)),
+      bar);
+  }));
+}
+
+async function loadChart() {
+  const { year } = period();
+  const months = await api("GET", "/api/reports/monthly" + query({ year }));
+
+  const byMonth = new Map(months.map((total) => [total.month, total]));
+  const largest = Math.max(1, ...months.map((total) => Math.max(total.spent, total.income)));
+
+  const width = 600;
+  const height = 200;
+  const bottom = 20;
+  const slot = width / 12;
+  const barWidth = slot / 3;
+  const scale = (value) => (value / largest) * (height - bottom - 10);
+
+  const svgNamespace = "http://www.w3.org/2000/svg";
+  const svg = document.createElementNS(svgNamespace, "svg");
+  svg.setAttribute("viewBox", `0 0 ${width} ${height}`);
+  svg.setAttribute("role", "img");
+  svg.setAttribute("aria-label", `Spending and income per month of ${year}`);
+
+  const shape = (tag, attributes, text) => {
+    const node = document.createElementNS(svgNamespace, tag);
+    for (const [name, value] of Object.entries(attributes)) {
+      node.setAttribute(name, value);
+    }
+    if (text) {
+      node.textContent = text;
+    }
+    svg.append(node);
+
+    return node;
+  };
+
+  for (let month = 1; month <= 12; month++) {
+    const total = byMonth.get(month) || { spent: 0, income: 0 };
+    const x = (month - 1) * slot + barWidth / 2;
+
+    for (const [kind, value, offset] of [["spent", total.spent, 0], ["income", total.income, barWidth]]) {
+      const barHeight = scale(value);
+      const bar = shape("rect", {
+        class: kind,
+        x: x + offset,
+        y: height - bottom - barHeight,
+        width: barWidth,
+        height: barHeight,
+      });
+      bar.append(Object.assign(document.createElementNS(svgNamespace, "title"), {
+        textContent: `${MONTHS[month - 1]}: ${kind} ${money.format(value)}`,
+      }));
+    }
+
+    shape("text", { x: x + barWidth, y: height - 5, "text-anchor": "middle" }, MONTHS[month - 1]);
+  }
+
+  $("#chart").replaceChildren(svg);
+}
+
+async function loadExpenses() {
+  const { month, year } = period();
+  const filters = $("#filters");
+
+  const page = await api("GET", "/api/expenses" + query({
+    month,
+    year,
+    category: filters.category.value,
+    q: filters.q.value,
+    from: filters.from.value,
+    to: filters.to.value,
+    with_deleted: filters.with_deleted.checked,
+    limit: PAGE_SIZE,
+    offset: state.offset,
+  }));
+
+  state.total = page.total;
+
+  if (page.items.length === 0) {
+    $("#expenses").replaceChildren(element("tr", {}, element("td", { colspan: 7, className: "empty" }, "No expenses found.")));
+  } else {
+    $("#expenses").replaceChildren(...page.items.map(expenseRow));
+  }
+
+  const last = Math.min(page.offset + page.items.length, page.total);
+  $("#page").textContent = page.total ? `${page.offset + 1}–${last} of ${page.total}` : "";
+  $("#previous").disabled = page.offset === 0;
+  $("#next").disabled = last >= page.total;
+}
+
+function expenseRow(expense) {
+  const actions = element("td", { className: "actions" });
+  if (!expense.is_deleted) {
+    const edit = element("button", { type: "button" }, "Edit");
+    edit.addEventListener("click", () => openEditor(expense));
+
+    const remove = element("button", { type: "button" }, "Delete");
+    remove.addEventListener("click", () => deleteExpense(expense));
+
+    actions.append(edit, " ", remove);
+  }
+
+  const amount = (expense.is_income ? "+" : "") + money.format(expense.amount);
+
+  return element("tr", { className: expense.is_deleted ? "deleted" : "" },
+    element("td", {}, String(expense.id)),
+    element("td", {}, expense.date.slice(0, 10)),
+    element("td", { title: expense.notes || "" }, expense.description),
+    element("td", {}, expense.category),
+    element("td", {}, ...(expense.tags || []).map((tag) => element("span", { className: "tag" }, tag))),
+    element("td", { className: "number" + (expense.is_income ? " income" : "") }, amount),
+    actions);
+}
+
+function openEditor(expense) {
+  const form = $("#expense-form");
+  state.editing = expense;
+
+  $("#editor-title").textContent = expense ? `Edit expense #${expense.id}` : "Add expense";
+  $("#editor-error").textContent = "";
+
+  form.amount.value = expense ? expense.amount : "";
+  form.description.value = expense ? expense.description : "";
+  form.category.value = expense ? expense.category : "";
+  form.date.value = expense ? expense.date.slice(0, 10) : today();
+  form.tags.value = expense && expense.tags ? expense.tags.join(", ") : "";
+  form.notes.value = expense ? expense.notes || "" : "";
+  form.is_income.checked = expense ? Boolean(expense.is_income) : false;
+
+  $("#editor").showModal();
+  form.amount.focus();
+}
+
+async function saveExpense(event) {
+  event.preventDefault();
+
+  const form = $("#expense-form");
+  const body = {
+    amount: Number(form.amount.value),
+    description: form.description.value,
+    category: form.category.value,
+    date: form.date.value,
+    tags: form.tags.value.split(",").map((tag) => tag.trim()).filter(Boolean),
+    notes: form.notes.value,
+    is_income: form.is_income.checked,
+  };
+
+  try {
+    if (state.editing) {
+      await api("PATCH", `/api/expenses/${state.editing.id}`, body);
+    } else {
+      await api("POST", "/api/expenses", body);
+    }
+  } catch (error) {
+    $("#editor-error").textContent = error.message;
+    return;
+  }
+
+  $("#editor").close();
+  await refresh();
+}
+
+async function deleteExpense(expense) {
+  if (!confirm(`Delete expense #${expense.id} "${expense.description}"?`)) {
+    return;
+  }
+
+  try {
+    await api("DELETE", `/api/expenses/${expense.id}`);
+    await refresh();
+  } catch (error) {
+    showError(error);
+  }
+}
+
+async function refresh() {
+  try {
+    await loadCategories();
+    await Promise.all([loadSummary(), loadChart(), loadExpenses()]);
+  } catch (error) {
+    showError(error);
+  }
+}
+
+function init() {
+  const now = new Date();
+  const periodForm = $("#period");
+
+  MONTHS.forEach((name, index) => {
+    periodForm.month.append(element("option", { value: index + 1 }, name));
+  });
+  periodForm.month.value = now.getMonth() + 1;
+  periodForm.year.value = now.getFullYear();
+
+  periodForm.addEventListener("change", () => {
+    state.offset = 0;
+    refresh();
+  });
+
+  $("#filters").addEventListener("input", () => {
+    state.offset = 0;
+    loadExpenses().catch(showError);
+  });
+  $("#filters").addEventListener("submit", (event) => event.preventDefault());
+
+  $("#previous").addEventListener("click", () => {
+    state.offset = Math.max(0, state.offset - PAGE_SIZE);
+    loadExpenses().catch(showError);
+  });
+  $("#next").addEventListener("click", () => {
+    state.offset += PAGE_SIZE;
+    loadExpenses().catch(showError);
+  });
+
+  $("#new-expense").addEventListener("click", () => openEditor(null));
+  $("#cancel").addEventListener("click", () => $("#editor").close());
+  $("#expense-form").addEventListener("submit", saveExpense);
+
+  refresh();
+}
+
+init();
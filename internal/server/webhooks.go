@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+const (
+	// How often DeliverWebhooks looks for deliveries that are due for a retry
+	WEBHOOK_POLL_INTERVAL = 15 * time.Second
+)
+
+/**
+* Delivers queued webhook events until ctx is done: right after a request
+* queues one, and regularly for the retries. Runs apart from the requests,
+* so a slow receiver never holds up the API.
+ */
+func (s *Server) DeliverWebhooks(ctx context.Context) {
+	client := &http.Client{Timeout: webhook.DELIVERY_TIMEOUT}
+	ticker := time.NewTicker(WEBHOOK_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.webhooks:
+		}
+
+		// Errors are kept on the deliveries and shown by `webhook queue`
+		webhook.Deliver(ctx, client, time.Now(), false)
+	}
+}
+
+/**
+* Queues a webhook event, and an event for every budget the change pushed
+* over a threshold, which SendEmails also emails an alert for; an empty
+* event only checks the budgets.
+ */
+func (s *Server) notify(snapshot webhook.Snapshot, event string, data any) error {
+	var crossings []report.Crossing
+	var err error
+	if event == "" {
+		crossings, err = snapshot.NotifyCrossings()
+	} else {
+		crossings, err = snapshot.Notify(event, data)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.wakeWebhooks()
+
+	if len(crossings) > 0 {
+		s.alertsMu.Lock()
+		s.alerts = append(s.alerts, crossings...)
+		s.alertsMu.Unlock()
+
+		s.wakeEmails()
+	}
+
+	return nil
+}
+
+func (s *Server) wakeWebhooks() {
+	select {
+	case s.webhooks <- struct{}{}:
+	default:
+	}
+}
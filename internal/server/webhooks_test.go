@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+func TestWebhooksAreDelivered(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	events := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		events <- r.Header.Get(webhook.EVENT_HEADER)
+	}))
+	defer receiver.Close()
+
+	if _, err := webhook.AddHook(receiver.URL, nil); err != nil {
+		t.Fatalf("Failed to add webhook: %v", err)
+	}
+
+	// Budgets are checked for the month of the expense in the current year
+	now := time.Now().UTC()
+	data, _ := json.Marshal([]budget.Budget{{Month: int(now.Month()), Year: now.Year(), Category: "Food", Limit: 20}})
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	s := newTestServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		s.DeliverWebhooks(ctx)
+		close(stopped)
+	}()
+	// Stop delivering before the data directory is removed
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	rec := do(t, s, http.MethodPost, "/api/expenses", map[string]any{"amount": 25, "description": "Dinner", "category": "Food", "date": now.Format(DATE_FORMAT)})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/expenses = %v, want 201: %s", rec.Code, rec.Body.String())
+	}
+	do(t, s, http.MethodDelete, "/api/expenses/0", nil)
+	do(t, s, http.MethodDelete, "/api/expenses/0", nil)
+
+	want := []string{webhook.EVENT_EXPENSE_CREATED, webhook.EVENT_BUDGET_THRESHOLD, webhook.EVENT_EXPENSE_DELETED}
+	for i, event := range want {
+		select {
+		case got := <-events:
+			if got != event {
+				t.Errorf("event %d = %q, want %q", i, got, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d (%s) was not delivered", i, event)
+		}
+	}
+
+	select {
+	case got := <-events:
+		t.Errorf("unexpected event %q; deleting twice must notify once", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,74 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+type Settings struct {
+	FundingAccount string `json:"funding_account"`
+}
+
+const (
+	DEFAULT_SETTINGS_FILE_PATH = "./data/settings.json"
+	DEFAULT_FUNDING_ACCOUNT    = "Assets:Checking"
+)
+
+func DefaultSettings() Settings {
+	return Settings{
+		FundingAccount: DEFAULT_FUNDING_ACCOUNT,
+	}
+}
+
+/**
+* Reads the settings. Settings that were never set keep their defaults.
+ */
+func GetSettings() (Settings, error) {
+	data, err := storage.GetFileData(DEFAULT_SETTINGS_FILE_PATH)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	settings := DefaultSettings()
+	if len(data) < 1 {
+		return settings, nil
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, err
+	}
+
+	if settings.FundingAccount == "" {
+		settings.FundingAccount = DEFAULT_FUNDING_ACCOUNT
+	}
+
+	return settings, nil
+}
+
+func ValidateSettings(settings Settings) error {
+	if strings.TrimSpace(settings.FundingAccount) == "" {
+		return errors.New("funding account not set")
+	}
+
+	return nil
+}
+
+func SaveSettings(settings Settings) error {
+	if err := ValidateSettings(settings); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	if err := storage.WriteFileData(DEFAULT_SETTINGS_FILE_PATH, data); err != nil {
+		return err
+	}
+
+	return nil
+}
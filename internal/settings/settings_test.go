@@ -0,0 +1,67 @@
+package settings
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGetSettingsDefaults(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	settings, err := GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if settings.FundingAccount != DEFAULT_FUNDING_ACCOUNT {
+		t.Errorf("GetSettings() funding account = %v, want %v", settings.FundingAccount, DEFAULT_FUNDING_ACCOUNT)
+	}
+
+	if err := os.WriteFile(DEFAULT_SETTINGS_FILE_PATH, []byte("{}"), 0755); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	settings, err = GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if settings.FundingAccount != DEFAULT_FUNDING_ACCOUNT {
+		t.Errorf("GetSettings() should keep the default for an unset field, got %v", settings.FundingAccount)
+	}
+}
+
+func TestSaveSettings(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if err := SaveSettings(Settings{FundingAccount: "Liabilities:Visa"}); err != nil {
+		t.Fatalf("SaveSettings() error = %v", err)
+	}
+
+	settings, err := GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	if settings.FundingAccount != "Liabilities:Visa" {
+		t.Errorf("GetSettings() funding account = %v, want Liabilities:Visa", settings.FundingAccount)
+	}
+
+	if err := SaveSettings(Settings{FundingAccount: "  "}); err == nil {
+		t.Errorf("SaveSettings() should fail without a funding account")
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
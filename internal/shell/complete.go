@@ -0,0 +1,100 @@
+package shell
+
+import (
+	"slices"
+	"strings"
+)
+
+/**
+* What Tab does to the word before the cursor: the word from Start on is
+* replaced with Replacement, and when several candidates are left they are
+* listed.
+ */
+type Completion struct {
+	Start       int
+	Replacement string
+	Candidates  []string
+}
+
+const CATEGORY_FLAG = "--category"
+
+// Flags naming a category only in some commands, e.g. --to is an address in email
+var CATEGORY_COMMAND_FLAGS = map[string][]string{
+	"category": {"--from", "--to"},
+}
+
+/**
+* Completes the command name as the first word of the line, and a category
+* as the value of a flag taking one. Categories match case-insensitively
+* and are quoted when they contain spaces.
+*
+* @param before The line up to the cursor.
+* @param commands The names of the commands.
+* @param categories The registered categories.
+* @return The completion; without candidates when nothing matches.
+ */
+func Complete(before []rune, commands, categories []string) Completion {
+	state := scan(before)
+
+	current := word{start: len(before)}
+	previous := state.words
+	if state.inWord {
+		current = state.words[len(state.words)-1]
+		previous = state.words[:len(state.words)-1]
+	}
+
+	completion := Completion{Start: current.start}
+	switch {
+	case len(previous) < 1:
+		for _, name := range commands {
+			if strings.HasPrefix(name, current.text) {
+				completion.Candidates = append(completion.Candidates, name)
+			}
+		}
+	case isCategoryFlag(previous[0].text, previous[len(previous)-1].text):
+		for _, name := range categories {
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(current.text)) {
+				completion.Candidates = append(completion.Candidates, name)
+			}
+		}
+	}
+
+	switch len(completion.Candidates) {
+	case 0:
+		return completion
+	case 1:
+		completion.Replacement = Quote(completion.Candidates[0]) + " "
+	default:
+		// Every candidate starts with the typed word, so the prefix is never shorter
+		completion.Replacement = string(commonPrefix(completion.Candidates))
+		if state.quote != 0 || strings.ContainsFunc(completion.Replacement, needsQuoting) {
+			// The quote is left open, as the word is not complete yet
+			completion.Replacement = `"` + escape(completion.Replacement)
+		}
+	}
+
+	return completion
+}
+
+func isCategoryFlag(command, flag string) bool {
+	return flag == CATEGORY_FLAG || slices.Contains(CATEGORY_COMMAND_FLAGS[command], flag)
+}
+
+/**
+* The longest prefix the candidates share, compared case-insensitively and
+* spelled as in the first candidate.
+ */
+func commonPrefix(candidates []string) []rune {
+	prefix := []rune(candidates[0])
+
+	for _, candidate := range candidates[1:] {
+		runes := []rune(candidate)
+		length := 0
+		for length < len(prefix) && length < len(runes) && strings.EqualFold(string(prefix[length]), string(runes[length])) {
+			length++
+		}
+		prefix = prefix[:length]
+	}
+
+	return prefix
+}
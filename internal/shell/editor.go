@@ -0,0 +1,239 @@
+package shell
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/tui"
+)
+
+/**
+* Reads lines at the prompt of an interactive terminal, with the keys of a
+* Unix shell: arrows move and recall the history, Tab completes, Ctrl+U
+* clears the line, Ctrl+C drops it and Ctrl+D on an empty line ends input.
+ */
+type Editor struct {
+	// The lines entered, oldest first; ReadLine adds to it
+	History  []string
+	complete func(before []rune) Completion
+	in       io.Reader
+	out      io.Writer
+	line     []rune
+	cursor   int
+	// The first rune shown, when the line is wider than the terminal
+	offset int
+	// The history line shown; len(History) while a new line is typed
+	historyIndex int
+	// The new line, kept while older lines are recalled
+	draft []rune
+	// Keys read along with an Enter, e.g. of pasted lines
+	pending []tui.Key
+	// Candidates to list above the prompt at the next render
+	listed []string
+	width  int
+}
+
+type action int
+
+const (
+	ACTION_NONE action = iota
+	ACTION_SUBMIT
+	ACTION_CANCEL
+	ACTION_END
+)
+
+const (
+	// Used when the size of the terminal cannot be read
+	DEFAULT_WIDTH = 80
+	CLEAR_LINE    = "\x1b[K"
+)
+
+/**
+* Creates an editor reading keys from in, which must be a terminal.
+*
+* @param history The lines of earlier sessions, oldest first.
+* @param complete Completes the word before the cursor; may be nil.
+ */
+func NewEditor(in io.Reader, out io.Writer, history []string, complete func(before []rune) Completion) *Editor {
+	return &Editor{
+		History:  history,
+		complete: complete,
+		in:       in,
+		out:      out,
+		width:    DEFAULT_WIDTH,
+	}
+}
+
+/**
+* Shows the prompt and reads one line, with the terminal in raw mode until
+* it is entered.
+*
+* @return The line; empty if Ctrl+C dropped it, and io.EOF once input ends.
+ */
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	saved, err := tui.MakeRaw()
+	if err != nil {
+		return "", err
+	}
+	defer tui.Restore(saved)
+
+	if width, _, err := tui.Size(); err == nil && width > 0 {
+		e.width = width
+	}
+
+	e.line, e.cursor, e.offset = []rune{}, 0, 0
+	e.historyIndex, e.draft = len(e.History), nil
+
+	buffer := make([]byte, 256)
+	for {
+		for len(e.pending) > 0 {
+			key := e.pending[0]
+			e.pending = e.pending[1:]
+
+			switch e.Update(key) {
+			case ACTION_SUBMIT:
+				e.render(prompt)
+				fmt.Fprint(e.out, "\r\n")
+				line := string(e.line)
+				e.History = AddToHistory(e.History, strings.TrimSpace(line))
+				return line, nil
+			case ACTION_CANCEL:
+				fmt.Fprint(e.out, "^C\r\n")
+				return "", nil
+			case ACTION_END:
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		}
+
+		e.render(prompt)
+
+		n, err := e.in.Read(buffer)
+		if err != nil {
+			return "", err
+		}
+		e.pending = tui.DecodeKeys(buffer[:n])
+	}
+}
+
+/**
+* Applies a key press to the line being typed.
+ */
+func (e *Editor) Update(key tui.Key) action {
+	switch key.Code {
+	case tui.KEY_RUNE:
+		e.line = append(e.line[:e.cursor], append([]rune{key.Rune}, e.line[e.cursor:]...)...)
+		e.cursor++
+	case tui.KEY_LEFT:
+		e.cursor = max(0, e.cursor-1)
+	case tui.KEY_RIGHT:
+		e.cursor = min(len(e.line), e.cursor+1)
+	case tui.KEY_HOME:
+		e.cursor = 0
+	case tui.KEY_END:
+		e.cursor = len(e.line)
+	case tui.KEY_BACKSPACE:
+		if e.cursor > 0 {
+			e.line = append(e.line[:e.cursor-1], e.line[e.cursor:]...)
+			e.cursor--
+		}
+	case tui.KEY_DELETE:
+		if e.cursor < len(e.line) {
+			e.line = append(e.line[:e.cursor], e.line[e.cursor+1:]...)
+		}
+	case tui.KEY_CTRL_U:
+		e.line = append([]rune{}, e.line[e.cursor:]...)
+		e.cursor = 0
+	case tui.KEY_UP:
+		e.recall(e.historyIndex - 1)
+	case tui.KEY_DOWN:
+		e.recall(e.historyIndex + 1)
+	case tui.KEY_TAB:
+		e.completeWord()
+	case tui.KEY_ENTER:
+		return ACTION_SUBMIT
+	case tui.KEY_CTRL_C:
+		return ACTION_CANCEL
+	case tui.KEY_CTRL_D:
+		if len(e.line) < 1 {
+			return ACTION_END
+		}
+		if e.cursor < len(e.line) {
+			e.line = append(e.line[:e.cursor], e.line[e.cursor+1:]...)
+		}
+	}
+
+	return ACTION_NONE
+}
+
+/**
+* Shows the history line at index, or the new line once past the last one.
+ */
+func (e *Editor) recall(index int) {
+	if index < 0 || index > len(e.History) || index == e.historyIndex {
+		return
+	}
+
+	if e.historyIndex == len(e.History) {
+		e.draft = e.line
+	}
+
+	e.historyIndex = index
+	if index == len(e.History) {
+		e.line = e.draft
+	} else {
+		e.line = []rune(e.History[index])
+	}
+	e.cursor = len(e.line)
+}
+
+func (e *Editor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+
+	completion := e.complete(e.line[:e.cursor])
+	if len(completion.Candidates) < 1 {
+		return
+	}
+
+	replacement := []rune(completion.Replacement)
+	rest := e.line[e.cursor:]
+	e.line = append(append(append([]rune{}, e.line[:completion.Start]...), replacement...), rest...)
+	e.cursor = completion.Start + len(replacement)
+
+	if len(completion.Candidates) > 1 {
+		e.listed = completion.Candidates
+	}
+}
+
+/**
+* Redraws the prompt line, scrolled sideways when it is wider than the
+* terminal, after listing any completion candidates above it.
+ */
+func (e *Editor) render(prompt string) {
+	var frame strings.Builder
+
+	if len(e.listed) > 0 {
+		frame.WriteString("\r" + CLEAR_LINE + strings.Join(e.listed, "  ") + "\r\n")
+		e.listed = nil
+	}
+
+	available := max(10, e.width-utf8.RuneCountInString(prompt)-1)
+	if e.cursor < e.offset {
+		e.offset = e.cursor
+	}
+	if e.cursor > e.offset+available {
+		e.offset = e.cursor - available
+	}
+	end := min(len(e.line), e.offset+available)
+
+	frame.WriteString("\r" + prompt + string(e.line[e.offset:end]) + CLEAR_LINE)
+	if back := end - e.cursor; back > 0 {
+		frame.WriteString(fmt.Sprintf("\x1b[%dD", back))
+	}
+
+	io.WriteString(e.out, frame.String())
+}
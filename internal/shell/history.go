@@ -0,0 +1,61 @@
+package shell
+
+import (
+	"encoding/json"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+const (
+	DEFAULT_HISTORY_FILE_PATH = "./data/shell_history.json"
+	// The most lines kept; older ones are dropped
+	HISTORY_LIMIT = 1000
+)
+
+/**
+* Reads the lines entered in earlier shells, oldest first.
+ */
+func GetHistory() ([]string, error) {
+	data, err := storage.GetFileData(DEFAULT_HISTORY_FILE_PATH)
+	if err != nil {
+		return []string{}, err
+	}
+
+	history := []string{}
+	if len(data) < 1 {
+		return history, nil
+	}
+
+	if err := json.Unmarshal(data, &history); err != nil {
+		return []string{}, err
+	}
+
+	return history, nil
+}
+
+/**
+* Saves the history, keeping the last HISTORY_LIMIT lines.
+ */
+func SaveHistory(history []string) error {
+	if len(history) > HISTORY_LIMIT {
+		history = history[len(history)-HISTORY_LIMIT:]
+	}
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_HISTORY_FILE_PATH, data)
+}
+
+/**
+* Adds a line to the history unless it is empty or repeats the last one.
+ */
+func AddToHistory(history []string, line string) []string {
+	if line == "" || (len(history) > 0 && history[len(history)-1] == line) {
+		return history
+	}
+
+	return append(history, line)
+}
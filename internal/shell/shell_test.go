@@ -0,0 +1,253 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/tui"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{"words", "add --amount 12", []string{"add", "--amount", "12"}, false},
+		{"extra spaces", "  list   --month 9 ", []string{"list", "--month", "9"}, false},
+		{"double quotes", `add --description "Lunch at work"`, []string{"add", "--description", "Lunch at work"}, false},
+		{"single quotes keep backslashes", `add --description 'C:\temp "x"'`, []string{"add", "--description", `C:\temp "x"`}, false},
+		{"escapes", `add --description Fish\ \&\ chips "say \"hi\""`, []string{"add", "--description", "Fish & chips", `say "hi"`}, false},
+		{"quotes inside a word", `--category=Food:"Eating out"`, []string{"--category=Food:Eating out"}, false},
+		{"empty quotes", `add --tags ""`, []string{"add", "--tags", ""}, false},
+		{"empty line", "   ", []string{}, false},
+		{"unclosed quote", `add --description "Lunch`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Split(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Split(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Split(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, word := range []string{"Food", "Eating out", `say "hi"`, `back\slash`, "", "it's"} {
+		words, err := Split(Quote(word))
+		if err != nil || len(words) != 1 || words[0] != word {
+			t.Errorf("Split(Quote(%q)) = %q, %v", word, words, err)
+		}
+	}
+
+	if got := Quote("Food:Groceries"); got != "Food:Groceries" {
+		t.Errorf("Quote() = %q, a plain word must be left as it is", got)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	commands := []string{"add", "budget", "category", "delete", "email", "list"}
+	categories := []string{"Food", "Food:Eating out", "Food:Groceries", "Transport"}
+
+	tests := []struct {
+		name        string
+		before      string
+		replacement string
+		candidates  []string
+	}{
+		{"command", "bu", "budget ", []string{"budget"}},
+		{"ambiguous command", "", "", commands},
+		{"category", "add --category tr", "Transport ", []string{"Transport"}},
+		{"common prefix", "add --category fo", "Food", []string{"Food", "Food:Eating out", "Food:Groceries"}},
+		{"quoted when it has spaces", "list --category Food:E", `"Food:Eating out" `, []string{"Food:Eating out"}},
+		{"open quote", `add --category "Food:`, `"Food:`, []string{"Food:Eating out", "Food:Groceries"}},
+		{"rename", "category rename --from Food --to Tr", "Transport ", []string{"Transport"}},
+		{"no categories for other flags", "add --description Fo", "", nil},
+		{"no categories for email addresses", "email setup --to Fo", "", nil},
+		{"no match", "add --category Rent", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := []rune(tt.before)
+			got := Complete(before, commands, categories)
+
+			if !slices.Equal(got.Candidates, tt.candidates) {
+				t.Errorf("Complete(%q) candidates = %q, want %q", tt.before, got.Candidates, tt.candidates)
+			}
+			if got.Replacement != tt.replacement {
+				t.Errorf("Complete(%q) replacement = %q, want %q", tt.before, got.Replacement, tt.replacement)
+			}
+		})
+	}
+}
+
+func TestEditor(t *testing.T) {
+	complete := func(before []rune) Completion {
+		return Complete(before, []string{"add", "list"}, []string{"Food", "Food:Groceries", "Transport"})
+	}
+
+	t.Run("Edits the line", func(t *testing.T) {
+		editor := NewEditor(nil, &bytes.Buffer{}, nil, complete)
+		typeText(editor, "lsitx")
+		press(editor, tui.KEY_LEFT, tui.KEY_DELETE, tui.KEY_LEFT, tui.KEY_LEFT, tui.KEY_BACKSPACE, tui.KEY_RIGHT)
+		typeText(editor, "s")
+
+		if got := string(editor.line); got != "list" {
+			t.Errorf("line = %q, want list", got)
+		}
+
+		press(editor, tui.KEY_LEFT, tui.KEY_CTRL_U)
+		if got := string(editor.line); got != "st" || editor.cursor != 0 {
+			t.Errorf("line after Ctrl+U = %q at %d, want st at 0", got, editor.cursor)
+		}
+	})
+
+	t.Run("Completes the word before the cursor", func(t *testing.T) {
+		editor := NewEditor(nil, &bytes.Buffer{}, nil, complete)
+		typeText(editor, "a")
+		press(editor, tui.KEY_TAB)
+		typeText(editor, "--category f")
+		press(editor, tui.KEY_TAB)
+
+		if got := string(editor.line); got != "add --category Food" {
+			t.Errorf("line = %q, want add --category Food", got)
+		}
+		if !slices.Equal(editor.listed, []string{"Food", "Food:Groceries"}) {
+			t.Errorf("listed = %q", editor.listed)
+		}
+
+		typeText(editor, ":g")
+		press(editor, tui.KEY_TAB)
+		if got := string(editor.line); got != "add --category Food:Groceries " {
+			t.Errorf("line = %q, want add --category Food:Groceries", got)
+		}
+	})
+
+	t.Run("Recalls the history", func(t *testing.T) {
+		editor := NewEditor(nil, &bytes.Buffer{}, []string{"list", "add --amount 5"}, complete)
+		editor.historyIndex = len(editor.History)
+		typeText(editor, "draft")
+
+		press(editor, tui.KEY_UP)
+		if got := string(editor.line); got != "add --amount 5" {
+			t.Errorf("line = %q, want the last line", got)
+		}
+
+		press(editor, tui.KEY_UP, tui.KEY_UP)
+		if got := string(editor.line); got != "list" {
+			t.Errorf("line = %q, want the first line", got)
+		}
+
+		press(editor, tui.KEY_DOWN, tui.KEY_DOWN)
+		if got := string(editor.line); got != "draft" {
+			t.Errorf("line = %q, want the draft back", got)
+		}
+	})
+
+	t.Run("Ends lines and input", func(t *testing.T) {
+		editor := NewEditor(nil, &bytes.Buffer{}, nil, complete)
+		if got := editor.Update(tui.Key{Code: tui.KEY_CTRL_D}); got != ACTION_END {
+			t.Errorf("Ctrl+D on an empty line = %v, want ACTION_END", got)
+		}
+
+		typeText(editor, "ab")
+		press(editor, tui.KEY_HOME)
+		if got := editor.Update(tui.Key{Code: tui.KEY_CTRL_D}); got != ACTION_NONE || string(editor.line) != "b" {
+			t.Errorf("Ctrl+D must delete the rune at the cursor, line = %q", string(editor.line))
+		}
+		if got := editor.Update(tui.Key{Code: tui.KEY_CTRL_C}); got != ACTION_CANCEL {
+			t.Errorf("Ctrl+C = %v, want ACTION_CANCEL", got)
+		}
+		if got := editor.Update(tui.Key{Code: tui.KEY_ENTER}); got != ACTION_SUBMIT {
+			t.Errorf("Enter = %v, want ACTION_SUBMIT", got)
+		}
+	})
+
+	t.Run("Scrolls a long line", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		editor := NewEditor(nil, out, nil, complete)
+		editor.width = 30
+		typeText(editor, "add --description \"A very long description of lunch\"")
+		editor.render("et> ")
+
+		if editor.offset < 1 {
+			t.Fatalf("offset = %d, the line must scroll", editor.offset)
+		}
+
+		out.Reset()
+		press(editor, tui.KEY_HOME)
+		editor.render("et> ")
+		if want := "\ret> add --description \"A very" + CLEAR_LINE; !bytes.HasPrefix(out.Bytes(), []byte(want)) {
+			t.Errorf("render() = %q, want the start of the line", out.String())
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	history, err := GetHistory()
+	if err != nil || len(history) != 0 {
+		t.Fatalf("GetHistory() = %q, %v, want an empty history", history, err)
+	}
+
+	history = AddToHistory(history, "list")
+	history = AddToHistory(history, "list")
+	history = AddToHistory(history, "")
+	if !slices.Equal(history, []string{"list"}) {
+		t.Errorf("AddToHistory() = %q, want one line", history)
+	}
+
+	for i := range HISTORY_LIMIT + 5 {
+		history = AddToHistory(history, fmt.Sprintf("add --amount %d", i))
+	}
+	if err := SaveHistory(history); err != nil {
+		t.Fatalf("SaveHistory() error = %v", err)
+	}
+
+	saved, err := GetHistory()
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(saved) != HISTORY_LIMIT || saved[len(saved)-1] != fmt.Sprintf("add --amount %d", HISTORY_LIMIT+4) {
+		t.Errorf("GetHistory() kept %d lines, want the last %d", len(saved), HISTORY_LIMIT)
+	}
+}
+
+func typeText(editor *Editor, text string) {
+	for _, r := range text {
+		editor.Update(tui.Key{Code: tui.KEY_RUNE, Rune: r})
+	}
+}
+
+func press(editor *Editor, codes ...tui.KeyCode) {
+	for _, code := range codes {
+		editor.Update(tui.Key{Code: code})
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
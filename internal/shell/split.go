@@ -0,0 +1,110 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+/**
+* Splits a line into words like a Unix shell does: words are separated by
+* spaces, and quotes keep spaces in a word. Within double quotes and outside
+* quotes, a backslash keeps the next character as it is.
+*
+* @param line The line typed at the prompt.
+* @return The words, or an error if a quote is not closed.
+ */
+func Split(line string) ([]string, error) {
+	words := []string{}
+	state := scan([]rune(line))
+
+	if state.quote != 0 {
+		return nil, errors.New("quote " + string(state.quote) + " is not closed")
+	}
+
+	for _, word := range state.words {
+		words = append(words, word.text)
+	}
+
+	return words, nil
+}
+
+/**
+* Quotes a word so Split reads it back as it is. Words without spaces or
+* special characters are left as they are.
+ */
+func Quote(word string) string {
+	if word != "" && !strings.ContainsFunc(word, needsQuoting) {
+		return word
+	}
+
+	return `"` + escape(word) + `"`
+}
+
+type word struct {
+	// Where the word starts in the line, in runes
+	start int
+	// The word with its quotes and escapes removed
+	text string
+}
+
+type scanState struct {
+	words []word
+	// The quote still open at the end of the line, or 0
+	quote rune
+	// Whether the line ends in a word rather than a space
+	inWord bool
+}
+
+func scan(line []rune) scanState {
+	state := scanState{}
+	var current strings.Builder
+	inWord, escaped := false, false
+	start := 0
+
+	for i, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && state.quote != '\'':
+			escaped = true
+		case state.quote != 0:
+			if r == state.quote {
+				state.quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			state.quote = r
+		case unicode.IsSpace(r):
+			if inWord {
+				state.words = append(state.words, word{start: start, text: current.String()})
+				current.Reset()
+				inWord = false
+			}
+			continue
+		default:
+			current.WriteRune(r)
+		}
+
+		if !inWord {
+			inWord, start = true, i
+		}
+	}
+
+	if inWord {
+		state.words = append(state.words, word{start: start, text: current.String()})
+	}
+	state.inWord = inWord
+
+	return state
+}
+
+func needsQuoting(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`"'\`, r)
+}
+
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+}
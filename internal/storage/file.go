@@ -2,22 +2,26 @@ package storage
 
 import (
 	"os"
+	"sort"
 )
 
-func GetFileData(fileName string) ([]byte, error) {
-	if err := createIfNotCreated(fileName); err != nil {
-		return []byte{}, err
-	}
+const (
+	STAGED_FILE_SUFFIX = ".tmp"
+)
 
-	fileData, err := os.ReadFile(fileName)
-	if err != nil {
-		return []byte{}, err
+func GetFileData(fileName string) ([]byte, error) {
+	if data, ok, err := readSession(fileName); ok {
+		return data, err
 	}
 
-	return fileData, nil
+	return readFileData(fileName)
 }
 
 func WriteFileData(fileName string, data []byte) error {
+	if writeSession(map[string][]byte{fileName: data}) {
+		return nil
+	}
+
 	if err := createIfNotCreated(fileName); err != nil {
 		return err
 	}
@@ -30,6 +34,10 @@ func WriteFileData(fileName string, data []byte) error {
 }
 
 func AppendFileData(fileName string, data []byte) error {
+	if ok, err := appendSession(fileName, data); ok {
+		return err
+	}
+
 	if err := createIfNotCreated(fileName); err != nil {
 		return err
 	}
@@ -48,6 +56,80 @@ func AppendFileData(fileName string, data []byte) error {
 	return nil
 }
 
+/**
+* Writes several files as one unit: every file is staged next to its target
+* first, and the staged files only replace the targets once all of them have
+* been written. If replacing one of them fails, the already replaced files are
+* restored to their previous content.
+*
+* @param files The data to write, keyed by file name.
+* @return An error if any file could not be written; in that case no file is changed.
+ */
+func WriteFilesData(files map[string][]byte) error {
+	if writeSession(files) {
+		return nil
+	}
+
+	return writeFilesData(files)
+}
+
+func writeFilesData(files map[string][]byte) error {
+	fileNames := []string{}
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	originals := map[string][]byte{}
+	staged := []string{}
+	removeStaged := func() {
+		for _, stagedName := range staged {
+			os.Remove(stagedName)
+		}
+	}
+
+	for _, fileName := range fileNames {
+		original, err := readFileData(fileName)
+		if err != nil {
+			removeStaged()
+			return err
+		}
+		originals[fileName] = original
+
+		stagedName := fileName + STAGED_FILE_SUFFIX
+		if err := os.WriteFile(stagedName, files[fileName], 0755); err != nil {
+			removeStaged()
+			return err
+		}
+		staged = append(staged, stagedName)
+	}
+
+	for i, fileName := range fileNames {
+		if err := os.Rename(staged[i], fileName); err != nil {
+			for _, replaced := range fileNames[:i] {
+				os.WriteFile(replaced, originals[replaced], 0755)
+			}
+			removeStaged()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readFileData(fileName string) ([]byte, error) {
+	if err := createIfNotCreated(fileName); err != nil {
+		return []byte{}, err
+	}
+
+	fileData, err := os.ReadFile(fileName)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return fileData, nil
+}
+
 func createIfNotCreated(fileName string) error {
 	if _, err := os.Stat(fileName); os.IsNotExist(err) {
 		file, err := os.Create(fileName)
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+)
+
+/**
+* The files read and written while a session is open. A file is read from
+* the disk once and then served from memory, and writes stay in memory
+* until the session is flushed, e.g. once per command of the shell.
+ */
+type session struct {
+	files   map[string][]byte
+	changed map[string]bool
+}
+
+var (
+	sessionMu sync.Mutex
+	current   *session
+)
+
+/**
+* Starts keeping the data files in memory. Until EndSession, GetFileData,
+* WriteFileData, AppendFileData and WriteFilesData work on the copies in
+* memory, so changes made by other processes meanwhile are not seen.
+ */
+func BeginSession() {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	current = &session{files: map[string][]byte{}, changed: map[string]bool{}}
+}
+
+/**
+* Writes the files changed since the last flush to the disk as one unit,
+* like WriteFilesData. If that fails, they are kept as changed, so the next
+* flush tries again.
+*
+* @return An error if the files could not be written; otherwise, nil.
+ */
+func FlushSession() error {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if current == nil || len(current.changed) < 1 {
+		return nil
+	}
+
+	files := map[string][]byte{}
+	for fileName := range current.changed {
+		files[fileName] = current.files[fileName]
+	}
+
+	if err := writeFilesData(files); err != nil {
+		return err
+	}
+
+	clear(current.changed)
+
+	return nil
+}
+
+/**
+* Flushes the session and goes back to reading and writing the disk
+* directly.
+ */
+func EndSession() error {
+	if err := FlushSession(); err != nil {
+		return err
+	}
+
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	current = nil
+
+	return nil
+}
+
+func readSession(fileName string) ([]byte, bool, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if current == nil {
+		return nil, false, nil
+	}
+
+	data, err := current.load(fileName)
+
+	return slices.Clone(data), true, err
+}
+
+func writeSession(files map[string][]byte) bool {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if current == nil {
+		return false
+	}
+
+	for fileName, data := range files {
+		current.files[fileName] = slices.Clone(data)
+		current.changed[fileName] = true
+	}
+
+	return true
+}
+
+func appendSession(fileName string, data []byte) (bool, error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+
+	if current == nil {
+		return false, nil
+	}
+
+	fileData, err := current.load(fileName)
+	if err != nil {
+		return true, err
+	}
+
+	current.files[fileName] = append(slices.Clone(fileData), data...)
+	current.changed[fileName] = true
+
+	return true, nil
+}
+
+func (s *session) load(fileName string) ([]byte, error) {
+	if data, ok := s.files[fileName]; ok {
+		return data, nil
+	}
+
+	data, err := readFileData(fileName)
+	if err != nil {
+		return []byte{}, err
+	}
+	s.files[fileName] = data
+
+	return data, nil
+}
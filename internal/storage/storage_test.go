@@ -493,3 +493,139 @@ func cleanupTestData(t *testing.T) {
 		t.Logf("Failed to cleanup test data: %v", err)
 	}
 }
+
+func TestWriteFilesData(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	t.Run("Writes all files", func(t *testing.T) {
+		files := map[string][]byte{
+			"./test_data/first.json":  []byte("first"),
+			"./test_data/second.json": []byte("second"),
+		}
+
+		if err := WriteFilesData(files); err != nil {
+			t.Fatalf("WriteFilesData() error = %v", err)
+		}
+
+		for fileName, want := range files {
+			data, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatalf("Failed to read %v: %v", fileName, err)
+			}
+			if string(data) != string(want) {
+				t.Errorf("WriteFilesData() %v = %v, want %v", fileName, string(data), string(want))
+			}
+			if _, err := os.Stat(fileName + STAGED_FILE_SUFFIX); !os.IsNotExist(err) {
+				t.Errorf("Staged file should not be left behind: %v", fileName)
+			}
+		}
+	})
+
+	t.Run("Changes nothing when a file cannot be written", func(t *testing.T) {
+		if err := os.WriteFile("./test_data/first.json", []byte("original"), 0755); err != nil {
+			t.Fatalf("Failed to create initial file: %v", err)
+		}
+
+		files := map[string][]byte{
+			"./test_data/first.json":          []byte("changed"),
+			"./test_data/missing/second.json": []byte("changed"),
+		}
+
+		if err := WriteFilesData(files); err == nil {
+			t.Errorf("WriteFilesData() should fail for a missing directory")
+		}
+
+		data, _ := os.ReadFile("./test_data/first.json")
+		if string(data) != "original" {
+			t.Errorf("WriteFilesData() first.json = %v, want original", string(data))
+		}
+		if _, err := os.Stat("./test_data/first.json" + STAGED_FILE_SUFFIX); !os.IsNotExist(err) {
+			t.Errorf("Staged file should be removed after a failure")
+		}
+	})
+}
+
+func TestSession(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	if err := os.WriteFile("./test_data/ledger.json", []byte("before"), 0755); err != nil {
+		t.Fatalf("Failed to create initial file: %v", err)
+	}
+
+	BeginSession()
+	defer EndSession()
+
+	t.Run("Reads the disk once", func(t *testing.T) {
+		if data, _ := GetFileData("./test_data/ledger.json"); string(data) != "before" {
+			t.Fatalf("GetFileData() = %v, want before", string(data))
+		}
+
+		os.WriteFile("./test_data/ledger.json", []byte("elsewhere"), 0755)
+		if data, _ := GetFileData("./test_data/ledger.json"); string(data) != "before" {
+			t.Errorf("GetFileData() = %v, want the copy in memory", string(data))
+		}
+		os.WriteFile("./test_data/ledger.json", []byte("before"), 0755)
+	})
+
+	t.Run("Keeps writes in memory until flushed", func(t *testing.T) {
+		if err := WriteFileData("./test_data/ledger.json", []byte("after")); err != nil {
+			t.Fatalf("WriteFileData() error = %v", err)
+		}
+		if err := AppendFileData("./test_data/log.txt", []byte("one\n")); err != nil {
+			t.Fatalf("AppendFileData() error = %v", err)
+		}
+		if err := AppendFileData("./test_data/log.txt", []byte("two\n")); err != nil {
+			t.Fatalf("AppendFileData() error = %v", err)
+		}
+
+		if data, _ := GetFileData("./test_data/ledger.json"); string(data) != "after" {
+			t.Errorf("GetFileData() = %v, want after", string(data))
+		}
+		if data, _ := os.ReadFile("./test_data/ledger.json"); string(data) != "before" {
+			t.Errorf("ledger.json = %v before the flush, want before", string(data))
+		}
+
+		if err := FlushSession(); err != nil {
+			t.Fatalf("FlushSession() error = %v", err)
+		}
+
+		if data, _ := os.ReadFile("./test_data/ledger.json"); string(data) != "after" {
+			t.Errorf("ledger.json = %v after the flush, want after", string(data))
+		}
+		if data, _ := os.ReadFile("./test_data/log.txt"); string(data) != "one\ntwo\n" {
+			t.Errorf("log.txt = %q after the flush", string(data))
+		}
+	})
+
+	t.Run("Keeps failed writes to retry them", func(t *testing.T) {
+		WriteFilesData(map[string][]byte{
+			"./test_data/ledger.json":        []byte("again"),
+			"./test_data/missing/other.json": []byte("again"),
+		})
+
+		if err := FlushSession(); err == nil {
+			t.Fatalf("FlushSession() should fail for a missing directory")
+		}
+		if data, _ := os.ReadFile("./test_data/ledger.json"); string(data) != "after" {
+			t.Errorf("ledger.json = %v after a failed flush, want after", string(data))
+		}
+
+		os.MkdirAll("./test_data/missing", 0755)
+		if err := EndSession(); err != nil {
+			t.Fatalf("EndSession() error = %v", err)
+		}
+		if data, _ := os.ReadFile("./test_data/missing/other.json"); string(data) != "again" {
+			t.Errorf("other.json = %v after the retry, want again", string(data))
+		}
+	})
+
+	// Without a session, writes reach the disk at once
+	if err := WriteFileData("./test_data/ledger.json", []byte("direct")); err != nil {
+		t.Fatalf("WriteFileData() error = %v", err)
+	}
+	if data, _ := os.ReadFile("./test_data/ledger.json"); string(data) != "direct" {
+		t.Errorf("ledger.json = %v, want direct", string(data))
+	}
+}
@@ -0,0 +1,594 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/utils"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+/**
+* Notifies webhooks and email of a change, as the other commands do after
+* one, and returns what went wrong for the status line.
+ */
+type ChangeFunc func(snapshot webhook.Snapshot, event string, data any) []error
+
+/**
+* The state of the interface: the expenses shown, the selection, the
+* filters and what is being typed. Update changes it for a key press and
+* View draws it, so it runs without a terminal too.
+ */
+type App struct {
+	expenses []expense.Expense
+	registry []category.Category
+	budgets  []budget.Budget
+	// Indexes into expenses of the rows shown, newest first
+	rows   []int
+	cursor int
+	offset int
+	// The number of table rows View last had room for
+	pageSize int
+	// The selected column, one of EDITABLE_COLUMNS
+	column int
+	// The month shown; a zero month shows every month
+	year     int
+	month    int
+	category string
+
+	mode        mode
+	input       []rune
+	inputCursor int
+	// The category completions Tab cycles through, and the next one
+	completions []string
+	completion  int
+
+	status      string
+	statusError bool
+	quit        bool
+
+	now      func() time.Time
+	onChange ChangeFunc
+}
+
+type mode int
+
+const (
+	MODE_BROWSE mode = iota
+	MODE_EDIT
+	MODE_FILTER
+	MODE_CONFIRM_DELETE
+	MODE_HELP
+)
+
+const (
+	COLUMN_ID = iota
+	COLUMN_DATE
+	COLUMN_AMOUNT
+	COLUMN_CATEGORY
+	COLUMN_DESCRIPTION
+	COLUMN_TAGS
+)
+
+const (
+	DATE_FORMAT = "2006-01-02"
+	// How many rows PageUp and PageDown move when the screen size is unknown
+	DEFAULT_PAGE_SIZE = 10
+)
+
+var EDITABLE_COLUMNS = []int{
+	COLUMN_DATE,
+	COLUMN_AMOUNT,
+	COLUMN_CATEGORY,
+	COLUMN_DESCRIPTION,
+	COLUMN_TAGS,
+}
+
+/**
+* Loads the stored expenses, categories and budgets. The interface starts
+* on the current month.
+*
+* @param now The clock, e.g. fixed in tests.
+* @param onChange Called after every change that was saved; may be nil.
+ */
+func NewApp(now func() time.Time, onChange ChangeFunc) (*App, error) {
+	today := now()
+	a := &App{
+		year:     today.Year(),
+		month:    int(today.Month()),
+		column:   COLUMN_AMOUNT,
+		pageSize: DEFAULT_PAGE_SIZE,
+		now:      now,
+		onChange: onChange,
+	}
+
+	if err := a.reload(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *App) Quit() bool {
+	return a.quit
+}
+
+/**
+* Handles a key press. Ctrl+C quits from anywhere; everything else depends
+* on the mode.
+ */
+func (a *App) Update(key Key) {
+	if key.Code == KEY_CTRL_C {
+		a.quit = true
+		return
+	}
+
+	switch a.mode {
+	case MODE_EDIT, MODE_FILTER:
+		a.updateInput(key)
+	case MODE_CONFIRM_DELETE:
+		if key.Code == KEY_RUNE && (key.Rune == 'y' || key.Rune == 'Y') {
+			a.deleteSelected()
+		} else {
+			a.setStatus("Nothing deleted", false)
+		}
+		a.mode = MODE_BROWSE
+	case MODE_HELP:
+		a.mode = MODE_BROWSE
+	default:
+		a.updateBrowse(key)
+	}
+}
+
+func (a *App) updateBrowse(key Key) {
+	a.status = ""
+
+	switch key.Code {
+	case KEY_UP:
+		a.moveCursor(-1)
+	case KEY_DOWN:
+		a.moveCursor(1)
+	case KEY_PAGE_UP:
+		a.moveCursor(-a.pageSize)
+	case KEY_PAGE_DOWN:
+		a.moveCursor(a.pageSize)
+	case KEY_HOME:
+		a.moveCursor(-len(a.rows))
+	case KEY_END:
+		a.moveCursor(len(a.rows))
+	case KEY_TAB, KEY_RIGHT:
+		a.moveColumn(1)
+	case KEY_BACKTAB, KEY_LEFT:
+		a.moveColumn(-1)
+	case KEY_ENTER:
+		a.startEdit()
+	case KEY_DELETE:
+		a.confirmDelete()
+	case KEY_ESCAPE:
+		a.category = ""
+		a.refreshRows()
+	case KEY_RUNE:
+		switch key.Rune {
+		case 'k':
+			a.moveCursor(-1)
+		case 'j':
+			a.moveCursor(1)
+		case 'g':
+			a.moveCursor(-len(a.rows))
+		case 'G':
+			a.moveCursor(len(a.rows))
+		case 'h':
+			a.moveColumn(-1)
+		case 'l':
+			a.moveColumn(1)
+		case 'e':
+			a.startEdit()
+		case 'd':
+			a.confirmDelete()
+		case 'c', '/':
+			a.mode = MODE_FILTER
+			a.setInput(a.category)
+		case '[', '<':
+			a.stepMonth(-1)
+		case ']', '>':
+			a.stepMonth(1)
+		case 'm':
+			a.toggleMonth()
+		case 'r':
+			if err := a.reload(); err != nil {
+				a.setStatus(err.Error(), true)
+			} else {
+				a.setStatus("Reloaded", false)
+			}
+		case '?':
+			a.mode = MODE_HELP
+		case 'q':
+			a.quit = true
+		}
+	}
+}
+
+func (a *App) updateInput(key Key) {
+	if key.Code != KEY_TAB {
+		a.completions = nil
+	}
+
+	switch key.Code {
+	case KEY_ESCAPE:
+		a.mode = MODE_BROWSE
+		a.setStatus("", false)
+	case KEY_ENTER:
+		if a.mode == MODE_FILTER {
+			a.applyFilter()
+		} else {
+			a.commitEdit()
+		}
+	case KEY_TAB:
+		a.complete()
+	case KEY_LEFT:
+		a.inputCursor = max(0, a.inputCursor-1)
+	case KEY_RIGHT:
+		a.inputCursor = min(len(a.input), a.inputCursor+1)
+	case KEY_HOME:
+		a.inputCursor = 0
+	case KEY_END:
+		a.inputCursor = len(a.input)
+	case KEY_BACKSPACE:
+		if a.inputCursor > 0 {
+			a.input = slices.Delete(a.input, a.inputCursor-1, a.inputCursor)
+			a.inputCursor--
+		}
+	case KEY_DELETE:
+		if a.inputCursor < len(a.input) {
+			a.input = slices.Delete(a.input, a.inputCursor, a.inputCursor+1)
+		}
+	case KEY_CTRL_U:
+		a.setInput("")
+	case KEY_RUNE:
+		if unicode.IsPrint(key.Rune) {
+			a.input = slices.Insert(a.input, a.inputCursor, key.Rune)
+			a.inputCursor++
+		}
+	}
+}
+
+func (a *App) moveCursor(delta int) {
+	a.cursor = max(0, min(len(a.rows)-1, a.cursor+delta))
+}
+
+func (a *App) moveColumn(delta int) {
+	i := slices.Index(EDITABLE_COLUMNS, a.column) + delta
+	a.column = EDITABLE_COLUMNS[(i+len(EDITABLE_COLUMNS))%len(EDITABLE_COLUMNS)]
+}
+
+/**
+* Moves the month shown by delta months; with every month shown, starts
+* from the current one.
+ */
+func (a *App) stepMonth(delta int) {
+	if a.month == 0 {
+		a.toggleMonth()
+		return
+	}
+
+	date := time.Date(a.year, time.Month(a.month)+time.Month(delta), 1, 0, 0, 0, 0, time.UTC)
+	a.year, a.month = date.Year(), int(date.Month())
+	a.refreshRows()
+}
+
+func (a *App) toggleMonth() {
+	if a.month != 0 {
+		a.month = 0
+	} else {
+		today := a.now()
+		a.year, a.month = today.Year(), int(today.Month())
+	}
+	a.refreshRows()
+}
+
+func (a *App) applyFilter() {
+	name := strings.TrimSpace(string(a.input))
+	if name != "" {
+		name = category.Resolve(a.registry, name)
+	}
+
+	a.category = name
+	a.mode = MODE_BROWSE
+	a.refreshRows()
+}
+
+/**
+* Completes the category being typed from the registry. Pressing Tab again
+* cycles through the other categories that start the same way.
+ */
+func (a *App) complete() {
+	if a.mode == MODE_EDIT && a.column != COLUMN_CATEGORY {
+		return
+	}
+
+	if a.completions == nil {
+		prefix := strings.ToLower(string(a.input))
+		for _, c := range a.registry {
+			if strings.HasPrefix(strings.ToLower(c.Name), prefix) {
+				a.completions = append(a.completions, c.Name)
+			}
+		}
+		a.completion = 0
+	}
+
+	if len(a.completions) < 1 {
+		return
+	}
+
+	completions := a.completions
+	a.setInput(completions[a.completion%len(completions)])
+	a.completions = completions
+	a.completion++
+}
+
+func (a *App) startEdit() {
+	exp, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	a.mode = MODE_EDIT
+	a.setInput(cellText(exp, a.column))
+}
+
+func (a *App) confirmDelete() {
+	exp, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	a.mode = MODE_CONFIRM_DELETE
+	a.setStatus(fmt.Sprintf("Delete expense %d, %.2f %s? (y/n)", exp.ID, exp.Amount, exp.Description), false)
+}
+
+/**
+* Applies the value typed to the selected cell and saves the expense. An
+* invalid value keeps the cell in editing, with the reason in the status
+* line.
+ */
+func (a *App) commitEdit() {
+	exp, ok := a.selected()
+	if !ok {
+		a.mode = MODE_BROWSE
+		return
+	}
+
+	updated, err := applyCell(exp, a.column, string(a.input))
+	if err != nil {
+		a.setStatus(err.Error(), true)
+		return
+	}
+
+	if updated.Category != exp.Category && updated.Category != "" {
+		canonical, err := category.Register(updated.Category)
+		if err != nil {
+			a.setStatus(err.Error(), true)
+			return
+		}
+		updated.Category = canonical
+	}
+
+	snapshot, err := webhook.TakeSnapshot(exp.Date, updated.Date)
+	if err != nil {
+		a.setStatus(err.Error(), true)
+		return
+	}
+
+	if err := a.save(updated); err != nil {
+		a.setStatus(err.Error(), true)
+		return
+	}
+
+	a.mode = MODE_BROWSE
+	a.setStatus(fmt.Sprintf("Expense %d has been updated", updated.ID), false)
+	a.notify(snapshot, webhook.EVENT_EXPENSE_UPDATED, updated)
+}
+
+func (a *App) deleteSelected() {
+	exp, ok := a.selected()
+	if !ok {
+		return
+	}
+
+	exp.IsDeleted = true
+	if err := a.save(exp); err != nil {
+		a.setStatus(err.Error(), true)
+		return
+	}
+
+	a.setStatus(fmt.Sprintf("Expense %d has been deleted", exp.ID), false)
+	// Deleting only lowers spending, so no budget can cross a threshold
+	a.notify(webhook.Snapshot{}, webhook.EVENT_EXPENSE_DELETED, exp)
+}
+
+/**
+* Writes one expense back. The file is read again first, so expenses added
+* elsewhere since the interface was opened are kept.
+ */
+func (a *App) save(exp expense.Expense) error {
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	if exp.ID < 0 || exp.ID >= len(expenses) {
+		return errors.New("cannot find expense with provided id")
+	}
+
+	expenses[exp.ID] = exp
+	if err := expense.SaveExpenses(expenses); err != nil {
+		return err
+	}
+
+	return a.reload()
+}
+
+func (a *App) notify(snapshot webhook.Snapshot, event string, data any) {
+	if a.onChange == nil {
+		return
+	}
+
+	if errs := a.onChange(snapshot, event, data); len(errs) > 0 {
+		a.setStatus(a.status+"; "+errs[0].Error(), true)
+	}
+}
+
+/**
+* Reads the data files again and keeps the selected expense selected.
+ */
+func (a *App) reload() error {
+	selectedID := -1
+	if exp, ok := a.selected(); ok {
+		selectedID = exp.ID
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return err
+	}
+
+	registry, err := category.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	a.expenses, a.registry, a.budgets = expenses, registry, budgets
+	a.refreshRows()
+
+	for i, index := range a.rows {
+		if a.expenses[index].ID == selectedID {
+			a.cursor = i
+		}
+	}
+
+	return nil
+}
+
+/**
+* Lists the expenses that pass the filters, newest first. Deleted expenses
+* are never shown.
+ */
+func (a *App) refreshRows() {
+	a.rows = a.rows[:0]
+	for i, exp := range a.expenses {
+		if exp.IsDeleted {
+			continue
+		}
+
+		if a.month != 0 && (exp.Date.Year() != a.year || int(exp.Date.Month()) != a.month) {
+			continue
+		}
+
+		if a.category != "" && !category.IsWithin(exp.Category, a.category) {
+			continue
+		}
+
+		a.rows = append(a.rows, i)
+	}
+
+	slices.SortStableFunc(a.rows, func(i, j int) int {
+		if c := a.expenses[j].Date.Compare(a.expenses[i].Date); c != 0 {
+			return c
+		}
+		return a.expenses[j].ID - a.expenses[i].ID
+	})
+
+	a.moveCursor(0)
+}
+
+func (a *App) selected() (expense.Expense, bool) {
+	if a.cursor < 0 || a.cursor >= len(a.rows) {
+		return expense.Expense{}, false
+	}
+
+	return a.expenses[a.rows[a.cursor]], true
+}
+
+func (a *App) setInput(text string) {
+	a.input = []rune(text)
+	a.inputCursor = len(a.input)
+}
+
+func (a *App) setStatus(status string, isError bool) {
+	a.status = status
+	a.statusError = isError
+}
+
+/**
+* The text of a cell, as it is shown and edited.
+ */
+func cellText(exp expense.Expense, column int) string {
+	switch column {
+	case COLUMN_ID:
+		return strconv.Itoa(exp.ID)
+	case COLUMN_DATE:
+		return exp.Date.Format(DATE_FORMAT)
+	case COLUMN_AMOUNT:
+		return strconv.FormatFloat(exp.Amount, 'f', 2, 64)
+	case COLUMN_CATEGORY:
+		return exp.Category
+	case COLUMN_DESCRIPTION:
+		return exp.Description
+	case COLUMN_TAGS:
+		return strings.Join(exp.Tags, ", ")
+	}
+
+	return ""
+}
+
+/**
+* Validates the value typed into a cell and returns the expense with it.
+ */
+func applyCell(exp expense.Expense, column int, value string) (expense.Expense, error) {
+	value = strings.TrimSpace(value)
+
+	switch column {
+	case COLUMN_DATE:
+		date, err := time.Parse(DATE_FORMAT, value)
+		if err != nil {
+			return exp, errors.New("date must be in YYYY-MM-DD format")
+		}
+		exp.Date = date
+		exp.Month = int(date.Month())
+	case COLUMN_AMOUNT:
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return exp, errors.New("amount must be a number")
+		}
+		exp.Amount = amount
+		if !utils.IsExpenseAmountValid(exp) {
+			return exp, errors.New("amount cannot be negative")
+		}
+	case COLUMN_CATEGORY:
+		exp.Category = category.Normalize(value)
+	case COLUMN_DESCRIPTION:
+		exp.Description = value
+	case COLUMN_TAGS:
+		exp.Tags = nil
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				exp.Tags = append(exp.Tags, tag)
+			}
+		}
+	}
+
+	return exp, nil
+}
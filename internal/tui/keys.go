@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+* A key press. Printable keys have KEY_RUNE and the rune typed; every other
+* key is told apart by its code alone.
+ */
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+type KeyCode int
+
+const (
+	KEY_RUNE KeyCode = iota
+	KEY_UP
+	KEY_DOWN
+	KEY_LEFT
+	KEY_RIGHT
+	KEY_HOME
+	KEY_END
+	KEY_PAGE_UP
+	KEY_PAGE_DOWN
+	KEY_DELETE
+	KEY_BACKSPACE
+	KEY_ENTER
+	KEY_TAB
+	KEY_BACKTAB
+	KEY_ESCAPE
+	KEY_CTRL_C
+	KEY_CTRL_U
+	KEY_CTRL_D
+	KEY_UNKNOWN
+)
+
+// The escape sequences terminals send for special keys, in raw mode
+var ESCAPE_SEQUENCES = map[string]KeyCode{
+	"\x1b[A":  KEY_UP,
+	"\x1b[B":  KEY_DOWN,
+	"\x1b[C":  KEY_RIGHT,
+	"\x1b[D":  KEY_LEFT,
+	"\x1bOA":  KEY_UP,
+	"\x1bOB":  KEY_DOWN,
+	"\x1bOC":  KEY_RIGHT,
+	"\x1bOD":  KEY_LEFT,
+	"\x1b[H":  KEY_HOME,
+	"\x1b[F":  KEY_END,
+	"\x1bOH":  KEY_HOME,
+	"\x1bOF":  KEY_END,
+	"\x1b[1~": KEY_HOME,
+	"\x1b[4~": KEY_END,
+	"\x1b[7~": KEY_HOME,
+	"\x1b[8~": KEY_END,
+	"\x1b[3~": KEY_DELETE,
+	"\x1b[5~": KEY_PAGE_UP,
+	"\x1b[6~": KEY_PAGE_DOWN,
+	"\x1b[Z":  KEY_BACKTAB,
+}
+
+/**
+* Splits what one read from a raw terminal returned into key presses. A
+* terminal writes an escape sequence at once, so an escape byte that
+* starts no known sequence is the escape key itself.
+ */
+func DecodeKeys(data []byte) []Key {
+	keys := []Key{}
+
+	for len(data) > 0 {
+		if data[0] == 0x1b {
+			key, size := decodeEscape(data)
+			keys = append(keys, key)
+			data = data[size:]
+			continue
+		}
+
+		switch data[0] {
+		case '\r', '\n':
+			keys = append(keys, Key{Code: KEY_ENTER})
+		case '\t':
+			keys = append(keys, Key{Code: KEY_TAB})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Code: KEY_BACKSPACE})
+		case 0x03:
+			keys = append(keys, Key{Code: KEY_CTRL_C})
+		case 0x15:
+			keys = append(keys, Key{Code: KEY_CTRL_U})
+		case 0x04:
+			keys = append(keys, Key{Code: KEY_CTRL_D})
+		default:
+			if data[0] < 0x20 {
+				keys = append(keys, Key{Code: KEY_UNKNOWN})
+				break
+			}
+
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, Key{Code: KEY_RUNE, Rune: r})
+			data = data[size:]
+			continue
+		}
+
+		data = data[1:]
+	}
+
+	return keys
+}
+
+func decodeEscape(data []byte) (Key, int) {
+	text := string(data)
+	for sequence, code := range ESCAPE_SEQUENCES {
+		if strings.HasPrefix(text, sequence) {
+			return Key{Code: code}, len(sequence)
+		}
+	}
+
+	// An unknown CSI sequence ends with a byte in 0x40–0x7e; skip it whole
+	if len(data) > 1 && data[1] == '[' {
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return Key{Code: KEY_UNKNOWN}, i + 1
+			}
+		}
+	}
+
+	return Key{Code: KEY_ESCAPE}, 1
+}
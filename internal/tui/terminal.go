@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+/**
+* The terminal the interface runs in, switched to raw mode and the
+* alternate screen while it is open. Raw mode is set with stty, so the
+* interface needs a Unix-like terminal.
+ */
+type Terminal struct {
+	in    *os.File
+	out   io.Writer
+	saved string
+}
+
+const (
+	ENTER_ALT_SCREEN = "\x1b[?1049h"
+	EXIT_ALT_SCREEN  = "\x1b[?1049l"
+	HIDE_CURSOR      = "\x1b[?25l"
+	SHOW_CURSOR      = "\x1b[?25h"
+	CURSOR_HOME      = "\x1b[H"
+	CLEAR_LINE       = "\x1b[K"
+	CLEAR_BELOW      = "\x1b[J"
+)
+
+/**
+* Switches the terminal of os.Stdin to raw mode without echo, and output to
+* the alternate screen, so the shell scrollback is left as it was. Close
+* restores both.
+ */
+func OpenTerminal() (*Terminal, error) {
+	if !IsTerminal() {
+		return nil, errors.New("tui needs an interactive terminal")
+	}
+
+	saved, err := MakeRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{in: os.Stdin, out: os.Stdout, saved: saved}
+	fmt.Fprint(t.out, ENTER_ALT_SCREEN+HIDE_CURSOR)
+
+	return t, nil
+}
+
+/**
+* Switches the terminal of os.Stdin to raw mode without echo.
+*
+* @return The previous settings, to be given to Restore.
+ */
+func MakeRaw() (string, error) {
+	if !IsTerminal() {
+		return "", errors.New("stdin is not an interactive terminal")
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		return "", errors.New("cannot read the terminal settings, a terminal with stty is needed: " + err.Error())
+	}
+
+	if _, err := stty("raw", "-echo"); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(saved), nil
+}
+
+/**
+* Restores the terminal settings MakeRaw returned.
+ */
+func Restore(saved string) error {
+	_, err := stty(saved)
+
+	return err
+}
+
+/**
+* Whether os.Stdin is a terminal rather than a file or a pipe.
+ */
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+/**
+* Restores the screen and the terminal settings OpenTerminal changed.
+ */
+func (t *Terminal) Close() error {
+	fmt.Fprint(t.out, SHOW_CURSOR+EXIT_ALT_SCREEN)
+
+	return Restore(t.saved)
+}
+
+/**
+* The width and height of the terminal in cells.
+ */
+func (t *Terminal) Size() (int, int, error) {
+	return Size()
+}
+
+/**
+* The width and height of the terminal of os.Stdin in cells.
+ */
+func Size() (int, int, error) {
+	output, err := stty("size")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return 0, 0, errors.New("cannot read the terminal size")
+	}
+
+	height, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	width, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return width, height, nil
+}
+
+/**
+* Redraws the whole screen. Every line is cleared to its end, so nothing
+* of the previous frame is left over.
+ */
+func (t *Terminal) Draw(lines []string) {
+	var frame strings.Builder
+	frame.WriteString(CURSOR_HOME)
+
+	for i, line := range lines {
+		if i > 0 {
+			// Raw mode turns off the translation of \n into \r\n
+			frame.WriteString("\r\n")
+		}
+		frame.WriteString(line + CLEAR_LINE)
+	}
+	frame.WriteString(CLEAR_BELOW)
+
+	io.WriteString(t.out, frame.String())
+}
+
+/**
+* Reads key presses until reading fails, e.g. once stdin is closed.
+ */
+func (t *Terminal) ReadKeys(keys chan<- Key, errs chan<- error) {
+	buffer := make([]byte, 256)
+
+	for {
+		n, err := t.in.Read(buffer)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		for _, key := range DecodeKeys(buffer[:n]) {
+			keys <- key
+		}
+	}
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+
+	output, err := cmd.Output()
+
+	return string(output), err
+}
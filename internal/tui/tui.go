@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"time"
+)
+
+const (
+	// How often the size of the terminal is checked, so a resize is drawn without a key press
+	RESIZE_POLL_INTERVAL = 250 * time.Millisecond
+)
+
+/**
+* Runs the interface in the terminal until it is quit.
+*
+* @param onChange Called after every change, e.g. to notify webhooks; may be nil.
+* @return An error if the terminal or the data files cannot be used; otherwise, nil.
+ */
+func Run(onChange ChangeFunc) error {
+	app, err := NewApp(time.Now, onChange)
+	if err != nil {
+		return err
+	}
+
+	terminal, err := OpenTerminal()
+	if err != nil {
+		return err
+	}
+	defer terminal.Close()
+
+	width, height, err := terminal.Size()
+	if err != nil {
+		return err
+	}
+
+	keys := make(chan Key, 64)
+	errs := make(chan error, 1)
+	go terminal.ReadKeys(keys, errs)
+
+	ticker := time.NewTicker(RESIZE_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	terminal.Draw(app.View(width, height))
+
+	for !app.Quit() {
+		select {
+		case key := <-keys:
+			app.Update(key)
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+			newWidth, newHeight, err := terminal.Size()
+			if err != nil || (newWidth == width && newHeight == height) {
+				continue
+			}
+			width, height = newWidth, newHeight
+		}
+
+		terminal.Draw(app.View(width, height))
+	}
+
+	return nil
+}
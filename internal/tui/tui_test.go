@@ -0,0 +1,347 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/category"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/webhook"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{"runes", "aé", []Key{{Code: KEY_RUNE, Rune: 'a'}, {Code: KEY_RUNE, Rune: 'é'}}},
+		{"arrows", "\x1b[A\x1b[B\x1bOC", []Key{{Code: KEY_UP}, {Code: KEY_DOWN}, {Code: KEY_RIGHT}}},
+		{"paging", "\x1b[5~\x1b[6~\x1b[H\x1b[4~", []Key{{Code: KEY_PAGE_UP}, {Code: KEY_PAGE_DOWN}, {Code: KEY_HOME}, {Code: KEY_END}}},
+		{"controls", "\r\t\x7f\x03\x15", []Key{{Code: KEY_ENTER}, {Code: KEY_TAB}, {Code: KEY_BACKSPACE}, {Code: KEY_CTRL_C}, {Code: KEY_CTRL_U}}},
+		{"escape alone", "\x1b", []Key{{Code: KEY_ESCAPE}}},
+		{"escape then a key", "\x1bq", []Key{{Code: KEY_ESCAPE}, {Code: KEY_RUNE, Rune: 'q'}}},
+		{"unknown sequence", "\x1b[15~x", []Key{{Code: KEY_UNKNOWN}, {Code: KEY_RUNE, Rune: 'x'}}},
+		{"back tab", "\x1b[Z", []Key{{Code: KEY_BACKTAB}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeKeys([]byte(tt.input)); !slices.Equal(got, tt.want) {
+				t.Errorf("DecodeKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrowseAndFilter(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	app := newTestApp(t, nil)
+
+	// Newest first, deleted expenses hidden, only September
+	if got := shownIDs(app); !slices.Equal(got, []int{3, 1, 0}) {
+		t.Fatalf("rows = %v, want [3 1 0]", got)
+	}
+
+	press(app, "j")
+	if exp, _ := app.selected(); exp.ID != 1 {
+		t.Errorf("selected %d after moving down, want 1", exp.ID)
+	}
+
+	press(app, "[")
+	if got := shownIDs(app); !slices.Equal(got, []int{4}) {
+		t.Errorf("rows of August = %v, want [4]", got)
+	}
+
+	press(app, "m")
+	if got := shownIDs(app); !slices.Equal(got, []int{3, 1, 0, 4}) {
+		t.Errorf("rows of every month = %v, want [3 1 0 4]", got)
+	}
+
+	// Tab completes the category from the registry; subcategories are included
+	press(app, "c", "f", "o")
+	app.Update(Key{Code: KEY_TAB})
+	if string(app.input) != "Food" {
+		t.Errorf("completion = %q, want Food", string(app.input))
+	}
+	app.Update(Key{Code: KEY_ENTER})
+	if got := shownIDs(app); !slices.Equal(got, []int{3, 0, 4}) {
+		t.Errorf("rows of Food = %v, want [3 0 4]", got)
+	}
+
+	app.Update(Key{Code: KEY_ESCAPE})
+	if app.category != "" || len(app.rows) != 4 {
+		t.Errorf("Esc must clear the category filter, rows = %v", shownIDs(app))
+	}
+
+	press(app, "q")
+	if !app.Quit() {
+		t.Errorf("q must quit")
+	}
+}
+
+func TestInlineEditing(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	events := []string{}
+	app := newTestApp(t, func(snapshot webhook.Snapshot, event string, data any) []error {
+		events = append(events, event)
+		return nil
+	})
+
+	// The amount of the newest expense, 12.50, becomes 20
+	app.Update(Key{Code: KEY_ENTER})
+	app.Update(Key{Code: KEY_CTRL_U})
+	press(app, "2", "0")
+	app.Update(Key{Code: KEY_ENTER})
+
+	saved, err := expense.GetExpense(3)
+	if err != nil || saved.Amount != 20 {
+		t.Fatalf("amount = %v (%v), want 20", saved.Amount, err)
+	}
+
+	// An invalid value keeps the cell in editing and changes nothing
+	app.Update(Key{Code: KEY_ENTER})
+	app.Update(Key{Code: KEY_CTRL_U})
+	press(app, "-", "5")
+	app.Update(Key{Code: KEY_ENTER})
+	if app.mode != MODE_EDIT || !app.statusError {
+		t.Errorf("a negative amount must be refused")
+	}
+	app.Update(Key{Code: KEY_ESCAPE})
+
+	// A known category is saved in its registered spelling
+	app.Update(Key{Code: KEY_TAB})
+	app.Update(Key{Code: KEY_ENTER})
+	app.Update(Key{Code: KEY_CTRL_U})
+	press(app, "transport")
+	app.Update(Key{Code: KEY_ENTER})
+
+	// Moving the expense to August takes it out of the September rows
+	app.Update(Key{Code: KEY_BACKTAB})
+	app.Update(Key{Code: KEY_BACKTAB})
+	app.Update(Key{Code: KEY_ENTER})
+	app.Update(Key{Code: KEY_CTRL_U})
+	press(app, "2025-08-30")
+	app.Update(Key{Code: KEY_ENTER})
+
+	saved, _ = expense.GetExpense(3)
+	if saved.Category != "Transport" || saved.Date.Format(DATE_FORMAT) != "2025-08-30" || saved.Month != 8 {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	if registry, _ := category.GetCategories(); len(registry) != 3 {
+		t.Errorf("transport was registered again: %v", registry)
+	}
+
+	if got := shownIDs(app); !slices.Equal(got, []int{1, 0}) {
+		t.Errorf("rows = %v, want [1 0]", got)
+	}
+
+	// Tags are split on commas
+	app.Update(Key{Code: KEY_TAB})
+	app.Update(Key{Code: KEY_TAB})
+	app.Update(Key{Code: KEY_TAB})
+	app.Update(Key{Code: KEY_TAB})
+	app.Update(Key{Code: KEY_ENTER})
+	press(app, "work, ", "trip")
+	app.Update(Key{Code: KEY_ENTER})
+	if saved, _ := expense.GetExpense(1); !slices.Equal(saved.Tags, []string{"work", "trip"}) {
+		t.Errorf("tags = %q", saved.Tags)
+	}
+
+	want := []string{webhook.EVENT_EXPENSE_UPDATED, webhook.EVENT_EXPENSE_UPDATED, webhook.EVENT_EXPENSE_UPDATED, webhook.EVENT_EXPENSE_UPDATED}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	events := []string{}
+	app := newTestApp(t, func(snapshot webhook.Snapshot, event string, data any) []error {
+		events = append(events, event)
+		return nil
+	})
+
+	press(app, "d", "n")
+	if saved, _ := expense.GetExpense(3); saved.IsDeleted {
+		t.Fatalf("answering n must not delete")
+	}
+
+	press(app, "d", "y")
+	if saved, _ := expense.GetExpense(3); !saved.IsDeleted {
+		t.Fatalf("answering y must delete")
+	}
+
+	if got := shownIDs(app); !slices.Equal(got, []int{1, 0}) {
+		t.Errorf("rows = %v, want [1 0]", got)
+	}
+
+	if !slices.Equal(events, []string{webhook.EVENT_EXPENSE_DELETED}) {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestSaveKeepsExpensesAddedElsewhere(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	app := newTestApp(t, nil)
+
+	exp, err := expense.CreateExpenseObj(7, "Added meanwhile", "")
+	if err != nil {
+		t.Fatalf("CreateExpenseObj() error = %v", err)
+	}
+	if err := expense.AddExpense(exp); err != nil {
+		t.Fatalf("AddExpense() error = %v", err)
+	}
+
+	press(app, "d", "y")
+
+	expenses, _ := expense.GetExpenses()
+	if len(expenses) != 6 || expenses[5].Description != "Added meanwhile" {
+		t.Errorf("the expense added meanwhile was lost: %+v", expenses)
+	}
+}
+
+func TestView(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+	seedExpenses(t)
+
+	data, _ := json.Marshal([]budget.Budget{{Month: 9, Year: 2025, Category: "Food", Limit: 40}})
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	app := newTestApp(t, nil)
+	lines := app.View(100, 20)
+
+	if len(lines) != 20 {
+		t.Fatalf("View() drew %d lines, want 20", len(lines))
+	}
+
+	for i, line := range lines {
+		if width := utf8.RuneCountInString(ansiPattern.ReplaceAllString(line, "")); width != 100 {
+			t.Errorf("line %d is %d cells wide, want 100: %q", i, width, line)
+		}
+	}
+
+	screen := ansiPattern.ReplaceAllString(strings.Join(lines, "\n"), "")
+	for _, want := range []string{
+		"September 2025 · 3 expenses, 57.50 $ spent",
+		"Coffee beans",
+		"Budgets for September 2025",
+		"Food",
+		"131.2%",
+		"12.50 over",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen does not show %q:\n%s", want, screen)
+		}
+	}
+
+	// A long list scrolls with the selection
+	for range 30 {
+		exp, _ := expense.CreateExpenseObj(1, "Filler", "")
+		exp.Date = time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC)
+		expense.AddExpense(exp)
+	}
+	press(app, "r", "G")
+	lines = app.View(100, 20)
+	screen = ansiPattern.ReplaceAllString(strings.Join(lines, "\n"), "")
+	if last := ansiPattern.ReplaceAllString(lines[15], ""); !strings.HasPrefix(last, "5 ") || strings.Contains(screen, "Coffee beans") {
+		t.Errorf("the last expense is not in view:\n%s", screen)
+	}
+
+	if lines := app.View(40, 10); len(lines) != 1 || !strings.Contains(lines[0], "too small") {
+		t.Errorf("View() of a small terminal = %q", lines)
+	}
+}
+
+func newTestApp(t *testing.T, onChange ChangeFunc) *App {
+	t.Helper()
+
+	app, err := NewApp(func() time.Time { return time.Date(2025, 9, 21, 12, 0, 0, 0, time.UTC) }, onChange)
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+
+	return app
+}
+
+func press(app *App, texts ...string) {
+	for _, text := range texts {
+		for _, key := range DecodeKeys([]byte(text)) {
+			app.Update(key)
+		}
+	}
+}
+
+func shownIDs(app *App) []int {
+	ids := []int{}
+	for _, index := range app.rows {
+		ids = append(ids, app.expenses[index].ID)
+	}
+
+	return ids
+}
+
+func seedExpenses(t *testing.T) {
+	t.Helper()
+
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 40, Description: "Groceries", Category: "Food:Groceries", Date: time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 1, Amount: 5, Description: "Bus ticket", Category: "Transport", Date: time.Date(2025, 9, 5, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 2, Amount: 99, Description: "Old lunch", Category: "Food", Date: time.Date(2025, 9, 6, 10, 0, 0, 0, time.UTC), Month: 9, IsDeleted: true},
+		{ID: 3, Amount: 12.5, Description: "Coffee beans", Category: "Food", Date: time.Date(2025, 9, 20, 10, 0, 0, 0, time.UTC), Month: 9},
+		{ID: 4, Amount: 60, Description: "Dinner", Category: "Food", Date: time.Date(2025, 8, 20, 10, 0, 0, 0, time.UTC), Month: 8},
+	}
+	if err := expense.SaveExpenses(expenses); err != nil {
+		t.Fatalf("Failed to save expenses: %v", err)
+	}
+
+	if err := category.SaveCategories([]category.Category{{Name: "Food"}, {Name: "Food:Groceries"}, {Name: "Transport"}}); err != nil {
+		t.Fatalf("Failed to save categories: %v", err)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+	// Create empty expenses.json and budgets.json files
+	for _, fileName := range []string{"./data/expenses.json", "./data/budgets.json"} {
+		if err := os.WriteFile(fileName, []byte("[]"), 0755); err != nil {
+			t.Fatalf("Failed to create test data file: %v", err)
+		}
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
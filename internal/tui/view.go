@@ -0,0 +1,341 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+const (
+	STYLE_RESET   = "\x1b[0m"
+	STYLE_BOLD    = "\x1b[1m"
+	STYLE_DIM     = "\x1b[2m"
+	STYLE_REVERSE = "\x1b[7m"
+	STYLE_CELL    = "\x1b[1;4m"
+	STYLE_EDIT    = "\x1b[0;30;47m"
+	STYLE_RED     = "\x1b[31m"
+	STYLE_GREEN   = "\x1b[32m"
+	STYLE_YELLOW  = "\x1b[33m"
+)
+
+const (
+	MIN_WIDTH  = 60
+	MIN_HEIGHT = 12
+	// The fewest table rows kept when budgets compete for the height
+	MIN_TABLE_ROWS = 5
+	GAUGE_WIDTH    = 20
+	COLUMN_GAP     = "  "
+	// Cells the category filter is typed into
+	FILTER_INPUT_WIDTH = 30
+)
+
+var COLUMN_TITLES = []string{"ID", "Date", "Amount", "Category", "Description", "Tags"}
+
+var HELP_LINES = []string{
+	"Moving",
+	"  ↑ ↓  j k          previous and next expense",
+	"  PgUp PgDn         a screen up or down",
+	"  Home End  g G     first and last expense",
+	"  ← →  h l  Tab     previous and next column",
+	"",
+	"Changing",
+	"  Enter  e          edit the selected cell; Enter saves, Esc cancels",
+	"  Tab               while editing a category: complete it",
+	"  d  Delete         delete the selected expense",
+	"",
+	"Filtering",
+	"  c  /              filter by category, with its subcategories",
+	"  Esc               show every category again",
+	"  [ ]  < >          previous and next month",
+	"  m                 every month, or back to the current one",
+	"  r                 read the data files again",
+	"",
+	"  ?                 this help      q  Ctrl+C   quit",
+}
+
+const HINTS = "↑↓ move  ←→ column  Enter edit  d delete  c category  [ ] month  m all months  r reload  ? help  q quit"
+
+/**
+* Draws the interface into lines of at most width cells: a header with the
+* filters and totals, the expense table, the budget gauges of the month,
+* a status line and the key hints.
+ */
+func (a *App) View(width, height int) []string {
+	if width < MIN_WIDTH || height < MIN_HEIGHT {
+		return []string{fit(fmt.Sprintf("The terminal is too small, make it at least %dx%d", MIN_WIDTH, MIN_HEIGHT), width)}
+	}
+
+	gauges := a.gaugeLines(width)
+	if extra := len(gauges) - (height - 4 - MIN_TABLE_ROWS); extra > 0 {
+		gauges = append(gauges[:len(gauges)-extra-1], fit(fmt.Sprintf("  … and %d more budgets", extra+1), width))
+	}
+
+	tableRows := height - 4 - len(gauges)
+	a.pageSize = tableRows
+
+	lines := []string{STYLE_REVERSE + fit(a.headerText(), width) + STYLE_RESET}
+	if a.mode == MODE_HELP {
+		for i := 0; i < tableRows+1; i++ {
+			text := ""
+			if i < len(HELP_LINES) {
+				text = HELP_LINES[i]
+			}
+			lines = append(lines, fit(text, width))
+		}
+	} else {
+		lines = append(lines, a.tableLines(width, tableRows)...)
+	}
+
+	lines = append(lines, gauges...)
+	lines = append(lines, a.statusLine(width), STYLE_DIM+fit(HINTS, width)+STYLE_RESET)
+
+	return lines
+}
+
+func (a *App) headerText() string {
+	parts := []string{" Expenses", a.periodName()}
+	if a.category != "" {
+		parts = append(parts, a.category)
+	}
+
+	spent, income := 0.0, 0.0
+	for _, index := range a.rows {
+		if exp := a.expenses[index]; exp.IsIncome {
+			income += exp.Amount
+		} else {
+			spent += exp.Amount
+		}
+	}
+
+	totals := fmt.Sprintf("%d expenses, %.2f $ spent", len(a.rows), spent)
+	if income > 0 {
+		totals += fmt.Sprintf(", %.2f $ income", income)
+	}
+
+	return strings.Join(append(parts, totals), " · ")
+}
+
+func (a *App) periodName() string {
+	if a.month == 0 {
+		return "all months"
+	}
+
+	return fmt.Sprintf("%s %d", time.Month(a.month).String(), a.year)
+}
+
+/**
+* The column titles and the rows of the table that fit, scrolled so the
+* selected row is always shown.
+ */
+func (a *App) tableLines(width, rows int) []string {
+	widths := columnWidths(width)
+
+	titles := make([]string, len(COLUMN_TITLES))
+	for i, title := range COLUMN_TITLES {
+		if i == COLUMN_AMOUNT {
+			titles[i] = fitRight(title, widths[i])
+		} else {
+			titles[i] = fit(title, widths[i])
+		}
+	}
+	lines := []string{STYLE_BOLD + fit(strings.Join(titles, COLUMN_GAP), width) + STYLE_RESET}
+
+	if len(a.rows) < 1 {
+		lines = append(lines, fit(fmt.Sprintf("No expenses for %s; [ and ] change the month, m shows every month", a.periodName()), width))
+		for len(lines) < rows+1 {
+			lines = append(lines, fit("", width))
+		}
+		return lines
+	}
+
+	// Scroll just enough to keep the selected row in view
+	a.offset = max(0, min(a.offset, a.cursor, len(a.rows)-rows))
+	if a.cursor >= a.offset+rows {
+		a.offset = a.cursor - rows + 1
+	}
+
+	for i := a.offset; i < a.offset+rows; i++ {
+		if i >= len(a.rows) {
+			lines = append(lines, fit("", width))
+			continue
+		}
+		lines = append(lines, a.rowLine(i, widths))
+	}
+
+	return lines
+}
+
+func (a *App) rowLine(row int, widths []int) string {
+	exp := a.expenses[a.rows[row]]
+	isSelected := row == a.cursor
+
+	rowStyle := ""
+	if isSelected {
+		rowStyle = STYLE_REVERSE
+	}
+
+	cells := make([]string, len(COLUMN_TITLES))
+	for column := range COLUMN_TITLES {
+		text := cellText(exp, column)
+		if column == COLUMN_AMOUNT && exp.IsIncome {
+			text = "+" + text
+		}
+
+		switch {
+		case isSelected && a.mode == MODE_EDIT && column == a.column:
+			cells[column] = STYLE_EDIT + a.inputText(widths[column]) + STYLE_RESET + rowStyle
+		case column == COLUMN_AMOUNT:
+			cell := fitRight(text, widths[column])
+			if exp.IsIncome && !isSelected {
+				cell = STYLE_GREEN + cell + STYLE_RESET
+			}
+			cells[column] = a.cellStyle(isSelected, column, cell, rowStyle)
+		default:
+			cells[column] = a.cellStyle(isSelected, column, fit(text, widths[column]), rowStyle)
+		}
+	}
+
+	// The widths add up to the width of the screen
+	return rowStyle + strings.Join(cells, COLUMN_GAP) + STYLE_RESET
+}
+
+func (a *App) cellStyle(isSelected bool, column int, cell, rowStyle string) string {
+	if !isSelected || column != a.column || a.mode != MODE_BROWSE {
+		return cell
+	}
+
+	return STYLE_CELL + cell + STYLE_RESET + rowStyle
+}
+
+/**
+* The value being typed, with the cursor, scrolled to fit the width.
+ */
+func (a *App) inputText(width int) string {
+	input := append([]rune{}, a.input...)
+	cursor := a.inputCursor
+	if cursor == len(input) {
+		input = append(input, ' ')
+	}
+
+	start := max(0, cursor-width+1)
+	end := min(len(input), start+width)
+
+	before := string(input[start:cursor])
+	at := string(input[cursor])
+	after := string(input[cursor+1 : end])
+
+	return before + STYLE_REVERSE + at + STYLE_RESET + STYLE_EDIT + after + strings.Repeat(" ", max(0, width-(end-start)))
+}
+
+/**
+* One gauge per budget of the month shown, or of the current month when
+* every month is shown.
+ */
+func (a *App) gaugeLines(width int) []string {
+	now := a.now()
+	year, month := now.Year(), int(now.Month())
+	if a.month != 0 {
+		year, month = a.year, a.month
+	}
+
+	budgetLines := report.BudgetVsActual(a.expenses, a.budgets, year, month, a.category, now)
+	if len(budgetLines) < 1 {
+		return nil
+	}
+
+	lines := []string{STYLE_BOLD + fit(fmt.Sprintf("Budgets for %s %d", time.Month(month).String(), year), width) + STYLE_RESET}
+	for _, line := range budgetLines {
+		filled := int(math.Round(math.Min(line.PercentUsed, 100) / 100 * GAUGE_WIDTH))
+		filled = max(0, min(GAUGE_WIDTH, filled))
+
+		color := STYLE_GREEN
+		switch {
+		case line.PercentUsed >= 100:
+			color = STYLE_RED
+		case line.PercentUsed >= report.BUDGET_THRESHOLDS[0]:
+			color = STYLE_YELLOW
+		}
+
+		remaining := fmt.Sprintf("%.2f left", line.Remaining)
+		if line.Remaining < 0 {
+			remaining = fmt.Sprintf("%.2f over", -line.Remaining)
+		}
+
+		text := fmt.Sprintf(" %6.1f%%  %.2f of %.2f, %s", line.PercentUsed, line.Spent, line.Limit, remaining)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", GAUGE_WIDTH-filled)
+
+		lines = append(lines, "  "+fit(line.Category, 18)+" "+color+bar+STYLE_RESET+fit(text, width-GAUGE_WIDTH-21))
+	}
+
+	return lines
+}
+
+func (a *App) statusLine(width int) string {
+	switch a.mode {
+	case MODE_FILTER:
+		return "Category: " + STYLE_EDIT + a.inputText(FILTER_INPUT_WIDTH) + STYLE_RESET +
+			fit("  Tab completes, Enter applies, empty shows all", width-10-FILTER_INPUT_WIDTH)
+	case MODE_EDIT:
+		if a.statusError {
+			return STYLE_RED + fit(a.status, width) + STYLE_RESET
+		}
+
+		hint := "Editing " + strings.ToLower(COLUMN_TITLES[a.column]) + ": Enter saves, Esc cancels"
+		if a.column == COLUMN_CATEGORY {
+			hint += ", Tab completes"
+		}
+		return fit(hint, width)
+	}
+
+	if a.statusError {
+		return STYLE_RED + fit(a.status, width) + STYLE_RESET
+	}
+
+	return fit(a.status, width)
+}
+
+/**
+* Shares the width out between the columns: ID, date and amount are
+* fixed, and what is left goes mostly to the description.
+ */
+func columnWidths(width int) []int {
+	widths := []int{5, 10, 10, 0, 0, 0}
+	available := width - 25 - len(COLUMN_GAP)*(len(widths)-1)
+
+	widths[COLUMN_CATEGORY] = min(24, available/4)
+	widths[COLUMN_TAGS] = min(20, available/5)
+	widths[COLUMN_DESCRIPTION] = available - widths[COLUMN_CATEGORY] - widths[COLUMN_TAGS]
+
+	return widths
+}
+
+/**
+* Pads or cuts text to exactly width cells, marking cut text with "…".
+ */
+func fit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	length := utf8.RuneCountInString(text)
+	if length <= width {
+		return text + strings.Repeat(" ", width-length)
+	}
+
+	runes := []rune(text)
+
+	return string(runes[:width-1]) + "…"
+}
+
+func fitRight(text string, width int) string {
+	length := utf8.RuneCountInString(text)
+	if length >= width {
+		return fit(text, width)
+	}
+
+	return strings.Repeat(" ", width-length) + text
+}
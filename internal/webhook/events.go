@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* The budget lines of some months before a change, so that the budgets the
+* change pushes over a threshold can be found afterwards.
+ */
+type Snapshot struct {
+	periods [][2]int
+	lines   []report.BudgetLine
+}
+
+/**
+* Takes a snapshot of the budgets of the months of the given dates, e.g.
+* the old and the new date of an expense.
+ */
+func TakeSnapshot(dates ...time.Time) (Snapshot, error) {
+	snapshot := Snapshot{}
+	for _, date := range dates {
+		period := [2]int{date.Year(), int(date.Month())}
+		if !containsPeriod(snapshot.periods, period) {
+			snapshot.periods = append(snapshot.periods, period)
+		}
+	}
+
+	lines, err := budgetLines(snapshot.periods)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot.lines = lines
+
+	return snapshot, nil
+}
+
+/**
+* Queues an event, then a budget.threshold event for every budget of the
+* snapshot months that has crossed a threshold since the snapshot.
+*
+* @return The crossings queued, e.g. to be sent as alerts by email too.
+ */
+func (s Snapshot) Notify(event string, data any) ([]report.Crossing, error) {
+	if err := Enqueue(event, data); err != nil {
+		return nil, err
+	}
+
+	return s.NotifyCrossings()
+}
+
+/**
+* Queues a budget.threshold event for every budget of the snapshot months
+* that has crossed a threshold since the snapshot, e.g. after a budget was
+* lowered.
+ */
+func (s Snapshot) NotifyCrossings() ([]report.Crossing, error) {
+	crossings, err := s.Crossings()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, crossing := range crossings {
+		if err := Enqueue(EVENT_BUDGET_THRESHOLD, crossing); err != nil {
+			return nil, err
+		}
+	}
+
+	return crossings, nil
+}
+
+/**
+* Lists the budgets of the snapshot months that have crossed a threshold
+* since the snapshot.
+ */
+func (s Snapshot) Crossings() ([]report.Crossing, error) {
+	after, err := budgetLines(s.periods)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.Crossings(s.lines, after, report.BUDGET_THRESHOLDS), nil
+}
+
+func budgetLines(periods [][2]int) ([]report.BudgetLine, error) {
+	if len(periods) < 1 {
+		return []report.BudgetLine{}, nil
+	}
+
+	expenses, err := expense.GetExpenses()
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lines := []report.BudgetLine{}
+	for _, period := range periods {
+		lines = append(lines, report.BudgetVsActual(expenses, budgets, period[0], period[1], "", now)...)
+	}
+
+	return lines, nil
+}
+
+func containsPeriod(periods [][2]int, period [2]int) bool {
+	for _, p := range periods {
+		if p == period {
+			return true
+		}
+	}
+
+	return false
+}
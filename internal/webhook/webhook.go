@@ -0,0 +1,461 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/storage"
+)
+
+/**
+* A URL notified of events. Events lists the events it receives; an empty
+* list or EVENT_ALL receives every event. Deliveries are signed with Secret.
+ */
+type Hook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+/**
+* An event waiting to be delivered to a hook. The payload is built when the
+* event happens, so a retry sends exactly the same body.
+ */
+type Delivery struct {
+	ID            string          `json:"id"`
+	HookID        int             `json:"hook_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	// Set once MAX_ATTEMPTS have failed; only `webhook deliver` retries it then
+	Failed    bool      `json:"failed,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+/**
+* The JSON body of a delivery.
+ */
+type Payload struct {
+	ID    string    `json:"id"`
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Data  any       `json:"data"`
+}
+
+/**
+* The outcome of a delivery pass.
+ */
+type Result struct {
+	Delivered int
+	Retrying  int
+	Failed    int
+	Errors    []error
+}
+
+const (
+	DEFAULT_WEBHOOKS_FILE_PATH = "./data/webhooks.json"
+	DEFAULT_QUEUE_FILE_PATH    = "./data/webhook_queue.json"
+	SIGNATURE_HEADER           = "X-Webhook-Signature"
+	EVENT_HEADER               = "X-Webhook-Event"
+	DELIVERY_HEADER            = "X-Webhook-Delivery"
+	USER_AGENT                 = "expense-tracker-webhook"
+	SECRET_BYTES               = 24
+	MAX_ATTEMPTS               = 8
+	RETRY_BASE_DELAY           = 30 * time.Second
+	RETRY_MAX_DELAY            = 6 * time.Hour
+	DELIVERY_TIMEOUT           = 5 * time.Second
+)
+
+const (
+	EVENT_ALL              = "*"
+	EVENT_EXPENSE_CREATED  = "expense.created"
+	EVENT_EXPENSE_UPDATED  = "expense.updated"
+	EVENT_EXPENSE_DELETED  = "expense.deleted"
+	EVENT_BUDGET_THRESHOLD = "budget.threshold"
+)
+
+var EVENTS = []string{
+	EVENT_EXPENSE_CREATED,
+	EVENT_EXPENSE_UPDATED,
+	EVENT_EXPENSE_DELETED,
+	EVENT_BUDGET_THRESHOLD,
+}
+
+// Guards the queue file, which both request handlers and the delivery loop of the server rewrite
+var queueMu sync.Mutex
+
+func GetHooks() ([]Hook, error) {
+	data, err := storage.GetFileData(DEFAULT_WEBHOOKS_FILE_PATH)
+	if err != nil {
+		return []Hook{}, err
+	}
+
+	if len(data) < 1 {
+		return []Hook{}, nil
+	}
+
+	hooks := []Hook{}
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return []Hook{}, err
+	}
+
+	return hooks, nil
+}
+
+func SaveHooks(hooks []Hook) error {
+	data, err := json.Marshal(hooks)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_WEBHOOKS_FILE_PATH, data)
+}
+
+/**
+* Adds a hook with the next free ID and a new signing secret.
+*
+* @param rawURL The http or https URL to notify.
+* @param events The events to notify it of; empty for every event.
+ */
+func AddHook(rawURL string, events []string) (Hook, error) {
+	if err := ValidateURL(rawURL); err != nil {
+		return Hook{}, err
+	}
+
+	for _, event := range events {
+		if event != EVENT_ALL && !slices.Contains(EVENTS, event) {
+			return Hook{}, errors.New("unknown event '" + event + "', expected one of " + strings.Join(EVENTS, ", ") + " or " + EVENT_ALL)
+		}
+	}
+
+	hooks, err := GetHooks()
+	if err != nil {
+		return Hook{}, err
+	}
+
+	secret, err := randomHex(SECRET_BYTES)
+	if err != nil {
+		return Hook{}, err
+	}
+
+	hook := Hook{
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, h := range hooks {
+		hook.ID = max(hook.ID, h.ID+1)
+	}
+
+	if err := SaveHooks(append(hooks, hook)); err != nil {
+		return Hook{}, err
+	}
+
+	return hook, nil
+}
+
+/**
+* Removes a hook together with its queued deliveries.
+ */
+func RemoveHook(id int) error {
+	hooks, err := GetHooks()
+	if err != nil {
+		return err
+	}
+
+	remaining := slices.DeleteFunc(slices.Clone(hooks), func(h Hook) bool {
+		return h.ID == id
+	})
+	if len(remaining) == len(hooks) {
+		return errors.New("cannot find webhook with provided id")
+	}
+
+	if err := SaveHooks(remaining); err != nil {
+		return err
+	}
+
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	queue, err := GetQueue()
+	if err != nil {
+		return err
+	}
+
+	return SaveQueue(slices.DeleteFunc(queue, func(d Delivery) bool {
+		return d.HookID == id
+	}))
+}
+
+func ValidateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("webhook url must be an absolute http or https url")
+	}
+
+	return nil
+}
+
+/**
+* Reports whether a hook receives an event.
+ */
+func Wants(hook Hook, event string) bool {
+	return len(hook.Events) < 1 || slices.Contains(hook.Events, EVENT_ALL) || slices.Contains(hook.Events, event)
+}
+
+func GetQueue() ([]Delivery, error) {
+	data, err := storage.GetFileData(DEFAULT_QUEUE_FILE_PATH)
+	if err != nil {
+		return []Delivery{}, err
+	}
+
+	if len(data) < 1 {
+		return []Delivery{}, nil
+	}
+
+	queue := []Delivery{}
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return []Delivery{}, err
+	}
+
+	return queue, nil
+}
+
+func SaveQueue(queue []Delivery) error {
+	data, err := json.Marshal(queue)
+	if err != nil {
+		return err
+	}
+
+	return storage.WriteFileData(DEFAULT_QUEUE_FILE_PATH, data)
+}
+
+/**
+* Queues an event for every hook that wants it. Nothing is sent yet; see
+* Deliver.
+*
+* @param event One of EVENTS.
+* @param data The subject of the event, e.g. the expense, sent as "data".
+ */
+func Enqueue(event string, data any) error {
+	hooks, err := GetHooks()
+	if err != nil {
+		return err
+	}
+
+	hooks = slices.DeleteFunc(hooks, func(h Hook) bool { return !Wants(h, event) })
+	if len(hooks) < 1 {
+		return nil
+	}
+
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	queue, err := GetQueue()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, hook := range hooks {
+		id, err := randomHex(16)
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(Payload{ID: id, Event: event, Time: now, Data: data})
+		if err != nil {
+			return err
+		}
+
+		queue = append(queue, Delivery{
+			ID:            id,
+			HookID:        hook.ID,
+			Event:         event,
+			Payload:       payload,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	return SaveQueue(queue)
+}
+
+/**
+* Sends the deliveries that are due. A delivery succeeds when the hook
+* answers with a 2xx status and is then removed from the queue; otherwise
+* it is retried with exponential backoff, until MAX_ATTEMPTS have failed.
+*
+* @param client The HTTP client to send with; its timeout bounds each attempt.
+* @param now The current time, which decides which deliveries are due.
+* @param all Whether to send every queued delivery now, including failed ones.
+* @return The outcome of the pass and the errors of failed attempts.
+ */
+func Deliver(ctx context.Context, client *http.Client, now time.Time, all bool) (Result, error) {
+	hooks, err := GetHooks()
+	if err != nil {
+		return Result{}, err
+	}
+
+	queueMu.Lock()
+	queue, err := GetQueue()
+	queueMu.Unlock()
+	if err != nil {
+		return Result{}, err
+	}
+
+	due := []Delivery{}
+	for _, delivery := range queue {
+		if all || (!delivery.Failed && !delivery.NextAttemptAt.After(now)) {
+			due = append(due, delivery)
+		}
+	}
+
+	if len(due) < 1 {
+		return Result{}, nil
+	}
+
+	result := Result{}
+	done := map[string]bool{}
+	retried := map[string]Delivery{}
+	for _, delivery := range due {
+		hookIdx := slices.IndexFunc(hooks, func(h Hook) bool { return h.ID == delivery.HookID })
+		if hookIdx == -1 {
+			// The hook has been removed since
+			done[delivery.ID] = true
+			continue
+		}
+
+		err := send(ctx, client, hooks[hookIdx], delivery)
+		if err == nil {
+			result.Delivered++
+			done[delivery.ID] = true
+			continue
+		}
+
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts))
+		delivery.Failed = delivery.Attempts >= MAX_ATTEMPTS
+		if delivery.Failed {
+			result.Failed++
+		} else {
+			result.Retrying++
+		}
+		result.Errors = append(result.Errors, errors.New("webhook "+strconv.Itoa(delivery.HookID)+": "+err.Error()))
+
+		retried[delivery.ID] = delivery
+	}
+
+	queueMu.Lock()
+	defer queueMu.Unlock()
+
+	// Re-read the queue: events may have been queued while sending
+	queue, err = GetQueue()
+	if err != nil {
+		return result, err
+	}
+
+	updated := []Delivery{}
+	for _, delivery := range queue {
+		if done[delivery.ID] {
+			continue
+		}
+
+		if retry, ok := retried[delivery.ID]; ok {
+			delivery = retry
+		}
+		updated = append(updated, delivery)
+	}
+
+	return result, SaveQueue(updated)
+}
+
+/**
+* The delay before the next attempt: RETRY_BASE_DELAY, doubled after every
+* failed attempt, up to RETRY_MAX_DELAY.
+ */
+func Backoff(attempts int) time.Duration {
+	delay := RETRY_BASE_DELAY
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= RETRY_MAX_DELAY {
+			return RETRY_MAX_DELAY
+		}
+	}
+
+	return delay
+}
+
+/**
+* Signs a payload as "sha256=" followed by the hex HMAC-SHA256 of the body
+* keyed with the secret of the hook, as sent in the X-Webhook-Signature header.
+ */
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+/**
+* Reports whether a signature header is valid for a body, for receivers
+* written in Go.
+ */
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func send(ctx context.Context, client *http.Client, hook Hook, delivery Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", USER_AGENT)
+	req.Header.Set(EVENT_HEADER, delivery.Event)
+	req.Header.Set(DELIVERY_HEADER, delivery.ID)
+	req.Header.Set(SIGNATURE_HEADER, Sign(hook.Secret, delivery.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("answered " + resp.Status)
+	}
+
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	random := make([]byte, n)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(random), nil
+}
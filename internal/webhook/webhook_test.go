@@ -0,0 +1,384 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/budget"
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+)
+
+/**
+* A local webhook receiver that records what it is sent and answers with
+* the given status.
+ */
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+	server   *httptest.Server
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+
+	rec := &receiver{status: http.StatusOK}
+	rec.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		w.WriteHeader(rec.status)
+	}))
+	t.Cleanup(rec.server.Close)
+
+	return rec
+}
+
+func (rec *receiver) answer(status int) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.status = status
+}
+
+func (rec *receiver) count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.requests)
+}
+
+func TestAddHook(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	tests := []struct {
+		name    string
+		url     string
+		events  []string
+		wantErr bool
+	}{
+		{"Every event", "https://example.com/hook", nil, false},
+		{"Filtered", "http://127.0.0.1:9000/hook", []string{EVENT_EXPENSE_CREATED, EVENT_BUDGET_THRESHOLD}, false},
+		{"Wildcard", "https://example.com/all", []string{EVENT_ALL}, false},
+		{"Unknown event", "https://example.com/hook", []string{"expense.exploded"}, true},
+		{"Relative url", "/hook", nil, true},
+		{"Other scheme", "ftp://example.com/hook", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AddHook(tt.url, tt.events)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	hooks, err := GetHooks()
+	if err != nil {
+		t.Fatalf("GetHooks() error = %v", err)
+	}
+	if len(hooks) != 3 || hooks[2].ID != 2 || hooks[0].Secret == "" || hooks[0].Secret == hooks[1].Secret {
+		t.Errorf("GetHooks() = %+v, want 3 hooks with own IDs and secrets", hooks)
+	}
+
+	if err := RemoveHook(1); err != nil {
+		t.Fatalf("RemoveHook() error = %v", err)
+	}
+	if err := RemoveHook(1); err == nil {
+		t.Errorf("RemoveHook() of a removed hook error = nil, want error")
+	}
+
+	hook, err := AddHook("https://example.com/new", nil)
+	if err != nil {
+		t.Fatalf("AddHook() error = %v", err)
+	}
+	if hook.ID != 3 {
+		t.Errorf("AddHook() after a removal ID = %d, want 3", hook.ID)
+	}
+}
+
+func TestWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{nil, EVENT_EXPENSE_DELETED, true},
+		{[]string{EVENT_ALL}, EVENT_BUDGET_THRESHOLD, true},
+		{[]string{EVENT_EXPENSE_CREATED}, EVENT_EXPENSE_CREATED, true},
+		{[]string{EVENT_EXPENSE_CREATED}, EVENT_EXPENSE_UPDATED, false},
+	}
+
+	for _, tt := range tests {
+		if got := Wants(Hook{Events: tt.events}, tt.event); got != tt.want {
+			t.Errorf("Wants(%v, %s) = %v, want %v", tt.events, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	rec := newReceiver(t)
+	hook, err := AddHook(rec.server.URL+"/hook", []string{EVENT_EXPENSE_CREATED})
+	if err != nil {
+		t.Fatalf("AddHook() error = %v", err)
+	}
+
+	exp := expense.Expense{ID: 3, Amount: 12.5, Description: "Lunch", Category: "Food"}
+	if err := Enqueue(EVENT_EXPENSE_CREATED, exp); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	// Not wanted by the hook
+	if err := Enqueue(EVENT_EXPENSE_DELETED, exp); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	result, err := Deliver(context.Background(), rec.server.Client(), time.Now(), false)
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if result.Delivered != 1 || rec.count() != 1 {
+		t.Fatalf("Deliver() = %+v with %d requests, want 1 delivered", result, rec.count())
+	}
+
+	req, body := rec.requests[0], rec.bodies[0]
+	if req.Method != http.MethodPost || req.URL.Path != "/hook" {
+		t.Errorf("request = %s %s, want POST /hook", req.Method, req.URL.Path)
+	}
+	if req.Header.Get(EVENT_HEADER) != EVENT_EXPENSE_CREATED {
+		t.Errorf("%s = %q, want %q", EVENT_HEADER, req.Header.Get(EVENT_HEADER), EVENT_EXPENSE_CREATED)
+	}
+	if !Verify(hook.Secret, body, req.Header.Get(SIGNATURE_HEADER)) {
+		t.Errorf("%s = %q does not verify", SIGNATURE_HEADER, req.Header.Get(SIGNATURE_HEADER))
+	}
+	if Verify("other secret", body, req.Header.Get(SIGNATURE_HEADER)) {
+		t.Errorf("signature verifies with another secret")
+	}
+
+	var payload struct {
+		ID    string          `json:"id"`
+		Event string          `json:"event"`
+		Data  expense.Expense `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("payload %s is not JSON: %v", body, err)
+	}
+	if payload.ID != req.Header.Get(DELIVERY_HEADER) || payload.Event != EVENT_EXPENSE_CREATED || payload.Data.Description != "Lunch" {
+		t.Errorf("payload = %+v, want the created expense", payload)
+	}
+
+	queue, err := GetQueue()
+	if err != nil {
+		t.Fatalf("GetQueue() error = %v", err)
+	}
+	if len(queue) != 0 {
+		t.Errorf("queue after delivery = %+v, want empty", queue)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	rec := newReceiver(t)
+	rec.answer(http.StatusServiceUnavailable)
+	if _, err := AddHook(rec.server.URL, nil); err != nil {
+		t.Fatalf("AddHook() error = %v", err)
+	}
+
+	if err := Enqueue(EVENT_EXPENSE_UPDATED, map[string]int{"id": 1}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	result, err := Deliver(ctx, rec.server.Client(), now, false)
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if result.Retrying != 1 || len(result.Errors) != 1 {
+		t.Fatalf("Deliver() = %+v, want 1 retrying", result)
+	}
+
+	queue, _ := GetQueue()
+	if len(queue) != 1 || queue[0].Attempts != 1 || !queue[0].NextAttemptAt.Equal(now.Add(RETRY_BASE_DELAY).UTC()) {
+		t.Fatalf("queue = %+v, want one delivery retried after %v", queue, RETRY_BASE_DELAY)
+	}
+	firstBody := rec.bodies[0]
+
+	// Not due yet
+	if result, _ := Deliver(ctx, rec.server.Client(), now.Add(RETRY_BASE_DELAY/2), false); result.Retrying != 0 || rec.count() != 1 {
+		t.Errorf("Deliver() before the retry is due sent %d requests", rec.count()-1)
+	}
+
+	rec.answer(http.StatusNoContent)
+	result, err = Deliver(ctx, rec.server.Client(), now.Add(RETRY_BASE_DELAY), false)
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if result.Delivered != 1 {
+		t.Fatalf("Deliver() when due = %+v, want 1 delivered", result)
+	}
+	if string(rec.bodies[1]) != string(firstBody) {
+		t.Errorf("retry body = %s, want the same body as the first attempt %s", rec.bodies[1], firstBody)
+	}
+
+	if queue, _ := GetQueue(); len(queue) != 0 {
+		t.Errorf("queue after delivery = %+v, want empty", queue)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	rec := newReceiver(t)
+	rec.answer(http.StatusInternalServerError)
+	if _, err := AddHook(rec.server.URL, nil); err != nil {
+		t.Fatalf("AddHook() error = %v", err)
+	}
+	if err := Enqueue(EVENT_EXPENSE_CREATED, nil); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	for range MAX_ATTEMPTS {
+		now = now.Add(RETRY_MAX_DELAY)
+		if _, err := Deliver(ctx, rec.server.Client(), now, false); err != nil {
+			t.Fatalf("Deliver() error = %v", err)
+		}
+	}
+
+	queue, _ := GetQueue()
+	if len(queue) != 1 || !queue[0].Failed || queue[0].Attempts != MAX_ATTEMPTS || queue[0].LastError == "" {
+		t.Fatalf("queue = %+v, want one failed delivery", queue)
+	}
+
+	// Failed deliveries are only sent again on request
+	if _, err := Deliver(ctx, rec.server.Client(), now.Add(RETRY_MAX_DELAY), false); err != nil || rec.count() != MAX_ATTEMPTS {
+		t.Errorf("Deliver() sent a failed delivery again: %d requests, error %v", rec.count(), err)
+	}
+
+	rec.answer(http.StatusOK)
+	result, err := Deliver(ctx, rec.server.Client(), now, true)
+	if err != nil || result.Delivered != 1 {
+		t.Errorf("Deliver() of all = %+v, %v, want 1 delivered", result, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, RETRY_BASE_DELAY},
+		{2, 2 * RETRY_BASE_DELAY},
+		{4, 8 * RETRY_BASE_DELAY},
+		{20, RETRY_MAX_DELAY},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotNotifiesCrossings(t *testing.T) {
+	setupTestData(t)
+	defer cleanupTestData(t)
+
+	rec := newReceiver(t)
+	if _, err := AddHook(rec.server.URL, []string{EVENT_BUDGET_THRESHOLD}); err != nil {
+		t.Fatalf("AddHook() error = %v", err)
+	}
+
+	now := time.Now().UTC()
+	data, _ := json.Marshal([]budget.Budget{{Month: int(now.Month()), Year: now.Year(), Category: "Food", Limit: 100}})
+	if err := os.WriteFile(budget.DEFAULT_BUDGET_FILE_PATH, data, 0644); err != nil {
+		t.Fatalf("Failed to write budgets: %v", err)
+	}
+
+	add := func(amount float64) {
+		snapshot, err := TakeSnapshot(now)
+		if err != nil {
+			t.Fatalf("TakeSnapshot() error = %v", err)
+		}
+
+		exp, err := expense.CreateExpenseObj(amount, "Groceries", "Food")
+		if err != nil {
+			t.Fatalf("CreateExpenseObj() error = %v", err)
+		}
+		exp.Date = now
+		if err := expense.AddExpense(exp); err != nil {
+			t.Fatalf("AddExpense() error = %v", err)
+		}
+
+		if _, err := snapshot.Notify(EVENT_EXPENSE_CREATED, exp); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+
+	add(50)
+	add(35)
+	add(10)
+
+	if _, err := Deliver(context.Background(), rec.server.Client(), time.Now(), false); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	if rec.count() != 1 {
+		t.Fatalf("receiver got %d events, want 1 budget.threshold", rec.count())
+	}
+
+	var payload struct {
+		Event string `json:"event"`
+		Data  struct {
+			Category  string  `json:"category"`
+			Spent     float64 `json:"spent"`
+			Threshold float64 `json:"threshold"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.bodies[0], &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.Event != EVENT_BUDGET_THRESHOLD || payload.Data.Category != "Food" || payload.Data.Spent != 85 || payload.Data.Threshold != 80 {
+		t.Errorf("payload = %+v, want Food crossing 80%% at 85", payload)
+	}
+}
+
+func setupTestData(t *testing.T) {
+	// Create test data directory
+	err := os.MkdirAll("./data", 0755)
+	if err != nil {
+		t.Fatalf("Failed to create test data directory: %v", err)
+	}
+	// Create empty expenses.json and budgets.json files
+	for _, fileName := range []string{"./data/expenses.json", "./data/budgets.json"} {
+		if err := os.WriteFile(fileName, []byte("[]"), 0755); err != nil {
+			t.Fatalf("Failed to create test data file: %v", err)
+		}
+	}
+}
+
+func cleanupTestData(t *testing.T) {
+	err := os.RemoveAll("./data")
+	if err != nil {
+		t.Logf("Failed to cleanup test data: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package xlsx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+const (
+	SHEET_EXPENSES    = "Expenses"
+	SHEET_BY_MONTH    = "By month"
+	SHEET_BY_CATEGORY = "By category"
+	SHEET_BUDGET      = "Budget vs actual"
+	TAGS_SEPARATOR    = ", "
+)
+
+/**
+* The pivots written next to the expenses, computed by the caller so that
+* they follow the same filters as the rest of the tracker.
+ */
+type Summary struct {
+	Months     []report.MonthTotal
+	Categories []report.CategoryTotal
+	Budget     []report.BudgetLine
+	Year       int
+	Month      int
+}
+
+/**
+* Writes an expenses workbook: an Expenses sheet with one row per expense,
+* the per-month and per-category pivots and the budget-vs-actual report of
+* one month, as printed by `summary`.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param expenses The expenses to write, already filtered by the caller.
+* @param summary The pivots and the budget report.
+* @return An error if writing fails; otherwise, nil.
+ */
+func WriteExpenses(w io.Writer, expenses []expense.Expense, summary Summary) error {
+	return Write(w, []Sheet{
+		expensesSheet(expenses),
+		monthsSheet(summary.Months),
+		categoriesSheet(summary.Categories),
+		budgetSheet(summary),
+	})
+}
+
+func expensesSheet(expenses []expense.Expense) Sheet {
+	sheet := Sheet{
+		Name: SHEET_EXPENSES,
+		Columns: []Column{
+			{Title: "ID", Width: 8},
+			{Title: "Date", Width: 12},
+			{Title: "Description", Width: 32},
+			{Title: "Category", Width: 20},
+			{Title: "Amount", Width: 12},
+			{Title: "Type", Width: 10},
+			{Title: "Tags", Width: 20},
+			{Title: "Notes", Width: 32},
+			{Title: "Counterparty", Width: 24},
+			{Title: "Deleted", Width: 10},
+		},
+	}
+
+	for _, exp := range expenses {
+		kind := "Expense"
+		if exp.IsIncome {
+			kind = "Income"
+		}
+
+		deleted := ""
+		if exp.IsDeleted {
+			deleted = "yes"
+		}
+
+		sheet.Rows = append(sheet.Rows, []Cell{
+			Number(float64(exp.ID)),
+			Date(exp.Date),
+			Text(exp.Description),
+			Text(exp.Category),
+			Money(exp.Amount),
+			Text(kind),
+			Text(strings.Join(exp.Tags, TAGS_SEPARATOR)),
+			Text(exp.Notes),
+			Text(exp.Counterparty),
+			Text(deleted),
+		})
+	}
+
+	return sheet
+}
+
+func monthsSheet(months []report.MonthTotal) Sheet {
+	sheet := Sheet{
+		Name: SHEET_BY_MONTH,
+		Columns: []Column{
+			{Title: "Month", Width: 10},
+			{Title: "Spent", Width: 12},
+			{Title: "Income", Width: 12},
+			{Title: "Net", Width: 12},
+			{Title: "Transactions", Width: 14},
+		},
+	}
+
+	for _, month := range months {
+		sheet.Rows = append(sheet.Rows, []Cell{
+			Month(month.Year, time.Month(month.Month)),
+			Money(month.Spent),
+			Money(month.Income),
+			Money(month.Income - month.Spent),
+			Number(float64(month.Count)),
+		})
+	}
+
+	return sheet
+}
+
+/**
+* Lists every category with its own spending and the total including its
+* subcategories. The share is that of the category's own spending, so the
+* shares add up to 100%.
+ */
+func categoriesSheet(categories []report.CategoryTotal) Sheet {
+	sheet := Sheet{
+		Name: SHEET_BY_CATEGORY,
+		Columns: []Column{
+			{Title: "Category", Width: 28},
+			{Title: "Own", Width: 12},
+			{Title: "Total", Width: 12},
+			{Title: "Share", Width: 10},
+		},
+	}
+
+	spent := 0.0
+	for _, total := range categories {
+		spent += total.Own
+	}
+
+	for _, total := range categories {
+		name := total.Category
+		if name == "" {
+			name = "(uncategorized)"
+		}
+
+		share := 0.0
+		if spent > 0 {
+			share = total.Own / spent
+		}
+
+		sheet.Rows = append(sheet.Rows, []Cell{
+			Text(name),
+			Money(total.Own),
+			Money(total.Total),
+			Percent(share),
+		})
+	}
+
+	return sheet
+}
+
+func budgetSheet(summary Summary) Sheet {
+	sheet := Sheet{
+		Name: SHEET_BUDGET,
+		Columns: []Column{
+			{Title: "Category", Width: 24},
+			{Title: "Limit", Width: 12},
+			{Title: "Spent", Width: 12},
+			{Title: "Remaining", Width: 12},
+			{Title: "Used", Width: 10},
+			{Title: "Per day", Width: 12},
+		},
+	}
+
+	period := fmt.Sprintf("%v %d", time.Month(summary.Month), summary.Year)
+	if len(summary.Budget) < 1 {
+		sheet.Rows = append(sheet.Rows, []Cell{Text("No budgets set for " + period)})
+		return sheet
+	}
+
+	for _, line := range summary.Budget {
+		dailyAllowance := Text("-")
+		if line.DailyAllowance > 0 {
+			dailyAllowance = Money(line.DailyAllowance)
+		}
+
+		sheet.Rows = append(sheet.Rows, []Cell{
+			Text(line.Category),
+			Money(line.Limit),
+			Money(line.Spent),
+			Money(line.Remaining),
+			Percent(line.PercentUsed / 100),
+			dailyAllowance,
+		})
+	}
+
+	sheet.Rows = append(sheet.Rows,
+		[]Cell{},
+		[]Cell{Text("Period"), Text(period)},
+		[]Cell{Text("Days left in period"), Number(float64(summary.Budget[0].DaysLeft))},
+	)
+
+	return sheet
+}
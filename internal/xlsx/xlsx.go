@@ -0,0 +1,345 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MAX_SHEET_NAME_LENGTH = 31
+	INVALID_SHEET_RUNES   = `[]:*?/\`
+)
+
+// Cell styles, as indexes into the cellXfs of styles.xml
+const (
+	STYLE_DEFAULT = iota
+	STYLE_DATE
+	STYLE_MONEY
+	STYLE_HEADER
+	STYLE_PERCENT
+	STYLE_MONTH
+)
+
+type cellKind int
+
+const (
+	KIND_TEXT cellKind = iota
+	KIND_NUMBER
+)
+
+/**
+* One cell of a sheet. Dates and numbers are stored as numbers with a number
+* format, so spreadsheets can sort, filter and sum them.
+ */
+type Cell struct {
+	kind   cellKind
+	text   string
+	number float64
+	style  int
+}
+
+type Column struct {
+	Title string
+	Width float64
+}
+
+/**
+* A worksheet: a bold header row built from the columns, frozen when
+* scrolling, followed by the rows.
+ */
+type Sheet struct {
+	Name    string
+	Columns []Column
+	Rows    [][]Cell
+}
+
+func Text(value string) Cell {
+	return Cell{kind: KIND_TEXT, text: value}
+}
+
+func Heading(value string) Cell {
+	return Cell{kind: KIND_TEXT, text: value, style: STYLE_HEADER}
+}
+
+/**
+* A number shown as is, e.g. an ID or a count.
+ */
+func Number(value float64) Cell {
+	return Cell{kind: KIND_NUMBER, number: value}
+}
+
+/**
+* An amount shown with two decimals.
+ */
+func Money(value float64) Cell {
+	return Cell{kind: KIND_NUMBER, number: value, style: STYLE_MONEY}
+}
+
+/**
+* A ratio shown as a percentage, e.g. 0.25 as "25.0%".
+ */
+func Percent(value float64) Cell {
+	return Cell{kind: KIND_NUMBER, number: value, style: STYLE_PERCENT}
+}
+
+/**
+* The calendar day of a time, shown as yyyy-mm-dd.
+ */
+func Date(value time.Time) Cell {
+	return Cell{kind: KIND_NUMBER, number: serialDate(value), style: STYLE_DATE}
+}
+
+/**
+* A calendar month, shown as yyyy-mm.
+ */
+func Month(year int, month time.Month) Cell {
+	return Cell{kind: KIND_NUMBER, number: serialDate(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)), style: STYLE_MONTH}
+}
+
+/**
+* Writes a workbook with the given sheets as an Office Open XML (.xlsx)
+* package. Strings are written inline, so no shared string table is needed.
+*
+* @param w The destination, e.g. a file or os.Stdout.
+* @param sheets The sheets, in the order of their tabs; names must be unique.
+* @return An error if a sheet name is invalid or writing fails; otherwise, nil.
+ */
+func Write(w io.Writer, sheets []Sheet) error {
+	if len(sheets) < 1 {
+		return errors.New("workbook needs at least one sheet")
+	}
+
+	names := map[string]bool{}
+	for _, sheet := range sheets {
+		if err := validateSheetName(sheet.Name); err != nil {
+			return err
+		}
+		key := strings.ToLower(sheet.Name)
+		if names[key] {
+			return errors.New("duplicate sheet name '" + sheet.Name + "'")
+		}
+		names[key] = true
+	}
+
+	archive := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", contentTypes(len(sheets))},
+		{"_rels/.rels", []byte(xml.Header + rootRelationships)},
+		{"xl/workbook.xml", workbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", workbookRelationships(len(sheets))},
+		{"xl/styles.xml", []byte(xml.Header + styles)},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct {
+			name    string
+			content []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(sheet)})
+	}
+
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(part.content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+/**
+* Replaces the characters a sheet name cannot hold with dashes and cuts it
+* to the maximum length.
+ */
+func SheetName(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	for i, r := range runes {
+		if strings.ContainsRune(INVALID_SHEET_RUNES, r) {
+			runes[i] = '-'
+		}
+	}
+
+	if len(runes) > MAX_SHEET_NAME_LENGTH {
+		runes = runes[:MAX_SHEET_NAME_LENGTH]
+	}
+
+	return string(runes)
+}
+
+func validateSheetName(name string) error {
+	if name == "" {
+		return errors.New("sheet name not set")
+	}
+
+	if SheetName(name) != name {
+		return errors.New("invalid sheet name '" + name + "'")
+	}
+
+	return nil
+}
+
+/**
+* Converts a time to a spreadsheet date: the days since 1899-12-30.
+ */
+func serialDate(value time.Time) float64 {
+	day := time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+	return math.Round(day.Sub(epoch).Hours() / 24)
+}
+
+/**
+* Returns the reference of a cell, e.g. "A1" or "AB12", from 0-based indexes.
+ */
+func cellReference(column, row int) string {
+	name := ""
+	for column >= 0 {
+		name = string(rune('A'+column%26)) + name
+		column = column/26 - 1
+	}
+
+	return name + strconv.Itoa(row+1)
+}
+
+func worksheet(sheet Sheet) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	rows := sheet.Rows
+	if len(sheet.Columns) > 0 {
+		buf.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+		buf.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+		buf.WriteString(`</sheetView></sheetViews>`)
+
+		buf.WriteString(`<cols>`)
+		for i, column := range sheet.Columns {
+			width := column.Width
+			if width <= 0 {
+				width = max(10, float64(len(column.Title)+2))
+			}
+			fmt.Fprintf(&buf, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		buf.WriteString(`</cols>`)
+
+		header := []Cell{}
+		for _, column := range sheet.Columns {
+			header = append(header, Heading(column.Title))
+		}
+		rows = append([][]Cell{header}, rows...)
+	}
+
+	buf.WriteString(`<sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&buf, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			writeCell(&buf, cellReference(c, r), cell)
+		}
+		buf.WriteString(`</row>`)
+	}
+	buf.WriteString(`</sheetData></worksheet>`)
+
+	return buf.Bytes()
+}
+
+func writeCell(buf *bytes.Buffer, reference string, cell Cell) {
+	style := ""
+	if cell.style != STYLE_DEFAULT {
+		style = fmt.Sprintf(` s="%d"`, cell.style)
+	}
+
+	if cell.kind == KIND_NUMBER && !math.IsNaN(cell.number) && !math.IsInf(cell.number, 0) {
+		fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, reference, style, strconv.FormatFloat(cell.number, 'g', -1, 64))
+		return
+	}
+
+	if cell.text == "" {
+		return
+	}
+
+	fmt.Fprintf(buf, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, reference, style)
+	xml.EscapeText(buf, []byte(cell.text))
+	buf.WriteString(`</t></is></c>`)
+}
+
+func contentTypes(sheetCount int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	buf.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	buf.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	buf.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	buf.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&buf, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	buf.WriteString(`</Types>`)
+
+	return buf.Bytes()
+}
+
+func workbook(sheets []Sheet) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range sheets {
+		buf.WriteString(`<sheet name="`)
+		xml.EscapeText(&buf, []byte(sheet.Name))
+		fmt.Fprintf(&buf, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+	}
+	buf.WriteString(`</sheets></workbook>`)
+
+	return buf.Bytes()
+}
+
+/**
+* Relates the workbook to its sheets (rId1 to rIdN) and its styles (rIdN+1).
+ */
+func workbookRelationships(sheetCount int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&buf, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&buf, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1)
+	buf.WriteString(`</Relationships>`)
+
+	return buf.Bytes()
+}
+
+const rootRelationships = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// The cellXfs follow the order of the STYLE_ constants
+const styles = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="3"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="0.0%"/><numFmt numFmtId="166" formatCode="yyyy-mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,194 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitriy-zverev/expense-tracker/internal/expense"
+	"github.com/dmitriy-zverev/expense-tracker/internal/report"
+)
+
+/**
+* Reads every part of a workbook and checks that it is well-formed XML.
+ */
+func readParts(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("workbook is not a zip archive: %v", err)
+	}
+
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", file.Name, err)
+			}
+		}
+
+		parts[file.Name] = string(content)
+	}
+
+	return parts
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, []Sheet{
+		{
+			Name:    "Data",
+			Columns: []Column{{Title: "Date"}, {Title: "Amount"}, {Title: "Text"}},
+			Rows: [][]Cell{
+				{Date(time.Date(2025, 9, 12, 18, 30, 0, 0, time.UTC)), Money(12.5), Text("Fish & <chips>\n")},
+				{Month(2025, time.September), Percent(0.25), Text("")},
+			},
+		},
+		{Name: "Empty"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	parts := readParts(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("Write() is missing part %s", name)
+		}
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="3" t="inlineStr"><is><t xml:space="preserve">Date</t></is></c>`,
+		`<c r="A2" s="1"><v>45912</v></c>`,
+		`<c r="B2" s="2"><v>12.5</v></c>`,
+		`<t xml:space="preserve">Fish &amp; &lt;chips&gt;&#xA;</t>`,
+		`<c r="A3" s="5"><v>45901</v></c>`,
+		`<c r="B3" s="4"><v>0.25</v></c>`,
+		`state="frozen"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet1.xml is missing %s:\n%s", want, sheet)
+		}
+	}
+
+	if strings.Contains(sheet, `r="C3"`) {
+		t.Errorf("empty text cells should not be written")
+	}
+
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Empty" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook.xml = %s", parts["xl/workbook.xml"])
+	}
+}
+
+func TestWriteInvalidSheets(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheets []Sheet
+	}{
+		{"No sheets", nil},
+		{"No name", []Sheet{{}}},
+		{"Invalid name", []Sheet{{Name: "2025/09"}}},
+		{"Duplicate name", []Sheet{{Name: "Data"}, {Name: "data"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Write(io.Discard, tt.sheets); err == nil {
+				t.Errorf("Write() should fail")
+			}
+		})
+	}
+}
+
+func TestCellReference(t *testing.T) {
+	tests := []struct {
+		column int
+		row    int
+		want   string
+	}{
+		{0, 0, "A1"},
+		{25, 9, "Z10"},
+		{26, 0, "AA1"},
+		{27, 1, "AB2"},
+		{701, 0, "ZZ1"},
+		{702, 0, "AAA1"},
+	}
+
+	for _, tt := range tests {
+		if got := cellReference(tt.column, tt.row); got != tt.want {
+			t.Errorf("cellReference(%d, %d) = %v, want %v", tt.column, tt.row, got, tt.want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	if got := SheetName(" Food: fast/food "); got != "Food- fast-food" {
+		t.Errorf("SheetName() = %v", got)
+	}
+
+	if got := SheetName(strings.Repeat("x", 40)); len(got) != MAX_SHEET_NAME_LENGTH {
+		t.Errorf("SheetName() length = %v", len(got))
+	}
+}
+
+func TestWriteExpenses(t *testing.T) {
+	expenses := []expense.Expense{
+		{ID: 0, Amount: 12.5, Date: time.Date(2025, 9, 12, 0, 0, 0, 0, time.UTC), Description: "Uber", Category: "Transport", Tags: []string{"work", "late"}},
+		{ID: 1, Amount: 2000, Date: time.Date(2025, 9, 14, 0, 0, 0, 0, time.UTC), Description: "Salary", IsIncome: true},
+	}
+	summary := Summary{
+		Months:     []report.MonthTotal{{Year: 2025, Month: 9, Spent: 12.5, Income: 2000, Count: 2}},
+		Categories: []report.CategoryTotal{{Category: "Transport", Own: 12.5, Total: 12.5}},
+		Budget:     []report.BudgetLine{{Year: 2025, Month: 9, Category: "Transport", Limit: 50, Spent: 12.5, Remaining: 37.5, PercentUsed: 25, DaysLeft: 0}},
+		Year:       2025,
+		Month:      9,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExpenses(&buf, expenses, summary); err != nil {
+		t.Fatalf("WriteExpenses() error = %v", err)
+	}
+
+	parts := readParts(t, buf.Bytes())
+
+	workbook := parts["xl/workbook.xml"]
+	for _, name := range []string{SHEET_EXPENSES, SHEET_BY_MONTH, SHEET_BY_CATEGORY, SHEET_BUDGET} {
+		if !strings.Contains(workbook, `name="`+name+`"`) {
+			t.Errorf("WriteExpenses() is missing sheet %s", name)
+		}
+	}
+
+	for part, want := range map[string]string{
+		"xl/worksheets/sheet1.xml": `<t xml:space="preserve">work, late</t>`,
+		"xl/worksheets/sheet2.xml": `<c r="D2" s="2"><v>1987.5</v></c>`,
+		"xl/worksheets/sheet3.xml": `<c r="D2" s="4"><v>1</v></c>`,
+		"xl/worksheets/sheet4.xml": `<c r="E2" s="4"><v>0.25</v></c>`,
+	} {
+		if !strings.Contains(parts[part], want) {
+			t.Errorf("%s is missing %s:\n%s", part, want, parts[part])
+		}
+	}
+
+	if !strings.Contains(parts["xl/worksheets/sheet4.xml"], "September 2025") {
+		t.Errorf("budget sheet should name the period")
+	}
+}